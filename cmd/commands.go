@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/modules"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/services"
+	"os"
+	"strconv"
+	"time"
+)
+
+const commandUsage = `Usage: main [flags] [command]
+
+Without a command the HTTP server is started. Every setting can be given as environment variable, in the YAML file
+of -config or CONFIG_FILE, or as flag, see -h.
+
+Commands:
+  audit verify          Recompute the audit log hash chain and report the first broken entry
+  accounts anonymize    Anonymize the accounts whose deletion grace period is over and delete their export archives
+  migrate up            Apply every pending schema migration
+  migrate down          Roll back the latest applied schema migration
+  migrate status        List the schema migrations and when they were applied
+  migrate to <version>  Migrate up or down to the given version, 0 rolls back every migration
+`
+
+// runCommand runs a one-off command instead of the HTTP server and returns the process exit code.
+func runCommand(cfg *config.Config, args []string) int {
+
+	if len(args) == 2 && args[0] == "audit" && args[1] == "verify" {
+		return runAuditVerifyCommand(cfg)
+	}
+
+	if len(args) == 2 && args[0] == "accounts" && args[1] == "anonymize" {
+		return runAccountsAnonymizeCommand(cfg)
+	}
+
+	if len(args) >= 2 && args[0] == "migrate" {
+		return runMigrateCommand(cfg, args[1:])
+	}
+
+	fmt.Fprint(os.Stderr, commandUsage)
+
+	return 2
+}
+
+func runAuditVerifyCommand(cfg *config.Config) int {
+
+	driver := cfg.Repository.Driver
+
+	db, err := openDatabase(cfg)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot connect to database: %v\n", err)
+		return 1
+	}
+
+	defer db.Close()
+
+	repositories, closeRepositories := newDatabaseRepositories(db, nil, driver)
+
+	defer closeRepositories()
+
+	auditService := services.NewAuditService(repositories.AuditLog)
+
+	result, err := auditService.VerifyChain(context.Background())
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot verify audit log: %v\n", err)
+		return 1
+	}
+
+	if result.IsValid == false {
+		fmt.Printf("audit log chain is BROKEN at entry %d (%d entries verified before it)\n", *result.BrokenAtId, result.CheckedCount)
+		return 1
+	}
+
+	fmt.Printf("audit log chain is valid (%d entries verified)\n", result.CheckedCount)
+
+	return 0
+}
+
+func runAccountsAnonymizeCommand(cfg *config.Config) int {
+
+	driver := cfg.Repository.Driver
+
+	db, err := openDatabase(cfg)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot connect to database: %v\n", err)
+		return 1
+	}
+
+	defer db.Close()
+
+	repositories, closeRepositories := newDatabaseRepositories(db, nil, driver)
+
+	defer closeRepositories()
+
+	auditService := services.NewAuditService(repositories.AuditLog)
+	userService := services.NewUserService(repositories.User, nil, nil, auditService,
+		cfg.Account.DeletionGracePeriod, cfg.Account.EmailVerificationUrl)
+
+	dataExportService := services.NewDataExportService(userService, nil, auditService, repositories.DataExport,
+		modules.NewLocalFileStorage(cfg.DataExport.Directory), cfg.DataExport.DownloadUrl)
+
+	anonymizedCount, err := userService.AnonymizeDueAccounts(context.Background())
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot anonymize accounts (%d anonymized before the error): %v\n", anonymizedCount, err)
+		return 1
+	}
+
+	fmt.Printf("%d accounts anonymized\n", anonymizedCount)
+
+	// The anonymization expired the exports of the accounts.
+	deletedCount, err := dataExportService.DeleteExpiredArchives(context.Background())
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot delete export archives (%d deleted before the error): %v\n", deletedCount, err)
+		return 1
+	}
+
+	fmt.Printf("%d export archives deleted\n", deletedCount)
+
+	return 0
+}
+
+func runMigrateCommand(cfg *config.Config, args []string) int {
+
+	var targetVersion int64
+
+	if args[0] == "to" {
+
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, commandUsage)
+			return 2
+		}
+
+		parsedVersion, err := strconv.ParseInt(args[1], 10, 64)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "version must be a number: %v\n", err)
+			return 2
+		}
+
+		targetVersion = parsedVersion
+	} else if len(args) != 1 {
+		fmt.Fprint(os.Stderr, commandUsage)
+		return 2
+	}
+
+	driver := cfg.Repository.Driver
+
+	db, err := openDatabase(cfg)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot connect to database: %v\n", err)
+		return 1
+	}
+
+	defer db.Close()
+
+	migrator, err := newMigrator(db, driver)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot load migrations: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+
+	var migrated []repository.Migration
+
+	switch args[0] {
+	case "up":
+		migrated, err = migrator.Up(ctx)
+	case "down":
+		migrated, err = migrator.Down(ctx)
+	case "to":
+		migrated, err = migrator.To(ctx, targetVersion)
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		fmt.Fprint(os.Stderr, commandUsage)
+		return 2
+	}
+
+	for _, migration := range migrated {
+		fmt.Printf("migrated %04d_%s\n", migration.Version, migration.Name)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot migrate: %v\n", err)
+		return 1
+	}
+
+	if len(migrated) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+
+	return 0
+}
+
+func printMigrationStatus(ctx context.Context, migrator *repository.Migrator) int {
+
+	statuses, err := migrator.Status(ctx)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read migration status: %v\n", err)
+		return 1
+	}
+
+	for _, status := range statuses {
+
+		appliedAt := "pending"
+
+		if status.AppliedAt != nil {
+			appliedAt = "applied at " + status.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Printf("%04d_%s  %s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return 0
+}
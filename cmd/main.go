@@ -2,95 +2,631 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/metrics"
 	"github.com/SawitProRecruitment/UserService/middlewares"
 	"github.com/SawitProRecruitment/UserService/modules"
 	"github.com/SawitProRecruitment/UserService/services"
+	"github.com/SawitProRecruitment/UserService/utils"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/SawitProRecruitment/UserService/generated"
 	"github.com/SawitProRecruitment/UserService/handler"
 	"github.com/SawitProRecruitment/UserService/repository"
 
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 func main() {
+
+	cfg, args, err := config.Load(os.Args[1:], os.LookupEnv)
+
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, commandUsage)
+		os.Exit(0)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	// The level is a variable so a reload can change it.
+	logLevel := &slog.LevelVar{}
+	logLevel.Set(cfg.Log.Level)
+
+	initLogger(logLevel)
+
+	if len(args) > 0 {
+		os.Exit(runCommand(cfg, args))
+	}
+
 	e := echo.New()
+	// The startup is logged as JSON like everything else.
+	e.HideBanner = true
+	e.HidePort = true
+
+	shutdownTracing := initTracing(cfg.Tracing)
+	defer shutdownTracing()
+
+	repositories, closeRepositories := initRepositories(cfg)
+	defer closeRepositories()
+
+	settings := newReloadableSettings(cfg, logLevel)
+
+	svc := initServices(cfg, repositories, settings)
+	initMiddlewares(e, cfg, svc)
+	initMetrics(e, cfg.Metrics, repositories)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A second signal kills the service without waiting for the shutdown.
+	context.AfterFunc(ctx, stop)
+
+	accountDeletionJobDone := make(chan struct{})
+
+	go func() {
+		defer close(accountDeletionJobDone)
+		runAccountDeletionJob(ctx, cfg.Account.DeletionJobInterval, svc.User, svc.DataExport)
+	}()
+
+	dataExportCleanupJobDone := make(chan struct{})
+
+	go func() {
+		defer close(dataExportCleanupJobDone)
+		runDataExportCleanupJob(ctx, cfg.DataExport.CleanupInterval, svc.DataExport)
+	}()
+
+	go watchSettings(ctx, os.Args[1:], cfg, settings)
+
+	var server generated.ServerInterface = newServer(svc)
+
+	generated.RegisterHandlers(e, server)
+
+	initHttpServer(e, cfg.Server)
+
+	// Returning instead of exiting lets the deferred cleanup close the statements and the pool.
+	serve(ctx, e, cfg.Server, svc.Health)
+
+	// The server may also have stopped because it failed, stop cancels ctx for the jobs then.
+	stop()
+	<-accountDeletionJobDone
+	<-dataExportCleanupJobDone
+}
+
+// initRepositories builds the repositories selected by REPOSITORY_DRIVER, "memory" runs the service without a database
+// and "sqlite" stores everything in a single file. The returned func closes the statements and the pool.
+func initRepositories(cfg *config.Config) (repository.Repositories, func()) {
+
+	driver := cfg.Repository.Driver
 
-	dbDsn := os.Getenv("DATABASE_URL")
-	db, err := sql.Open("postgres", dbDsn)
+	if driver == consts.RepositoryDriverMemory {
+
+		slog.Warn("using the in-memory repository, the data is lost when the service stops")
+
+		repo := repository.NewMemoryRepository()
+
+		return repository.Repositories{
+			User:       repo,
+			LoginEvent: repo,
+			AuditLog:   repo,
+			DataExport: repo,
+			Database:   repo,
+		}, func() {}
+	}
+
+	db, err := openDatabase(cfg)
 
 	if err != nil {
-		panic(err)
+		slog.Error("cannot connect to the database", slog.Any("error", err))
+		os.Exit(1)
 	}
 
-	dbConn, err := db.Conn(context.Background())
+	if cfg.Database.AutoMigrate {
+
+		err = migrateDatabase(db, driver)
+
+		if err != nil {
+			db.Close()
+			slog.Error("cannot migrate the database", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	replicas, err := openReplicaDatabases(cfg.Database)
 
 	if err != nil {
-		panic(err)
+		db.Close()
+		slog.Error("cannot connect to the read replicas", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	repositories, closeRepositories := newDatabaseRepositories(db, replicas, driver)
+	repositories, closeUserCache := initUserCache(cfg, newResilientRepositories(cfg.Database, repositories))
+
+	return repositories, func() {
+		closeUserCache()
+		closeRepositories()
+		db.Close()
+
+		for _, replica := range replicas {
+			replica.Close()
+		}
+	}
+}
+
+// openDatabase opens the connection pool of the repository driver, configured by the DATABASE_* or SQLITE_* settings.
+func openDatabase(cfg *config.Config) (*sql.DB, error) {
+
+	if cfg.Repository.Driver == consts.RepositoryDriverMemory {
+		return nil, errors.New("The memory repository has no database")
 	}
 
-	defer func(conn *sql.Conn) {
-		err := conn.Close()
+	if cfg.Repository.Driver == consts.RepositoryDriverSqlite {
+
+		return repository.OpenSqliteDatabase(context.Background(), repository.OpenSqliteDatabaseOptions{
+			Path:         cfg.Sqlite.Path,
+			BusyTimeout:  cfg.Sqlite.BusyTimeout,
+			MaxOpenConns: cfg.Database.MaxOpenConns,
+		})
+	}
+
+	return repository.OpenDatabase(context.Background(), getDatabaseOptions(cfg.Database, cfg.Database.Url))
+}
+
+// openReplicaDatabases opens a pool per DSN of DATABASE_REPLICA_URLS, with the settings of the primary pool.
+func openReplicaDatabases(databaseConfig config.DatabaseConfig) ([]*sql.DB, error) {
+
+	var replicas []*sql.DB
+
+	for _, dsn := range databaseConfig.ReplicaUrls {
+
+		replica, err := repository.OpenDatabase(context.Background(), getDatabaseOptions(databaseConfig, dsn))
+
 		if err != nil {
-			panic(err)
+
+			for _, openedReplica := range replicas {
+				openedReplica.Close()
+			}
+
+			return nil, err
+		}
+
+		replicas = append(replicas, replica)
+	}
+
+	return replicas, nil
+}
+
+func getDatabaseOptions(databaseConfig config.DatabaseConfig, dsn string) repository.OpenDatabaseOptions {
+
+	return repository.OpenDatabaseOptions{
+		Dsn:             dsn,
+		MaxOpenConns:    databaseConfig.MaxOpenConns,
+		MaxIdleConns:    databaseConfig.MaxIdleConns,
+		ConnMaxLifetime: databaseConfig.ConnMaxLifetime,
+		ConnMaxIdleTime: databaseConfig.ConnMaxIdleTime,
+		ConnectTimeout:  databaseConfig.ConnectTimeout,
+	}
+}
+
+// newDatabaseRepositories builds the repositories of the driver on top of db and the read replicas,
+// the returned func closes their statements.
+func newDatabaseRepositories(db *sql.DB, replicas []*sql.DB, driver string) (repository.Repositories, func()) {
+
+	if driver == consts.RepositoryDriverSqlite {
+
+		repo := repository.NewSqliteRepository(repository.NewSqliteRepositoryOptions{
+			Db: db,
+		})
+
+		return repository.Repositories{
+			User:       repo,
+			LoginEvent: repo,
+			AuditLog:   repo,
+			DataExport: repo,
+			Database:   repo,
+		}, func() {
+			repo.Close()
 		}
-	}(dbConn)
+	}
 
-	var repo repository.UserRepositoryInterface = repository.NewRepository(repository.NewRepositoryOptions{
-		Conn: dbConn,
+	repo := repository.NewRepository(repository.NewRepositoryOptions{
+		Db:       db,
+		Replicas: replicas,
 	})
 
-	svc := initServices(repo)
-	initMiddlewares(e, svc)
+	return repository.Repositories{
+		User:       repo,
+		LoginEvent: repo,
+		AuditLog:   repo,
+		DataExport: repo,
+		Database:   repo,
+	}, func() {
+		repo.Close()
+	}
+}
 
-	var server generated.ServerInterface = newServer(svc)
+// newResilientRepositories retries the transient errors of the repositories and fails fast while the database is unreachable,
+// configured by the DATABASE_RETRY_* and DATABASE_CIRCUIT_BREAKER_* settings.
+func newResilientRepositories(databaseConfig config.DatabaseConfig, repositories repository.Repositories) repository.Repositories {
 
-	generated.RegisterHandlers(e, server)
-	e.Logger.Fatal(e.Start(":1323"))
+	repo := repository.NewResilientRepository(repository.NewResilientRepositoryOptions{
+		Repositories: repositories,
+		RetryPolicy: repository.RetryPolicy{
+			MaxAttempts:    databaseConfig.RetryMaxAttempts,
+			InitialBackoff: databaseConfig.RetryInitialBackoff,
+			MaxBackoff:     databaseConfig.RetryMaxBackoff,
+		},
+		CircuitBreaker: repository.NewCircuitBreaker(repository.NewCircuitBreakerOptions{
+			FailureThreshold: databaseConfig.CircuitBreakerFailureThreshold,
+			OpenDuration:     databaseConfig.CircuitBreakerOpenDuration,
+		}),
+	})
+
+	return repository.Repositories{
+		User:       repo,
+		LoginEvent: repo,
+		AuditLog:   repo,
+		DataExport: repo,
+		Database:   repo,
+	}
 }
 
-func initServices(repo repository.UserRepositoryInterface) services.Services {
+// initUserCache caches the users read by id when USER_CACHE_SIZE is set. With Postgres the changes other instances
+// make arrive via LISTEN/NOTIFY, the returned func stops listening.
+func initUserCache(cfg *config.Config, repositories repository.Repositories) (repository.Repositories, func()) {
 
-	passwordAuth := modules.BcryptPasswordAuth{}
+	capacity := cfg.UserCache.Size
+
+	if capacity == 0 {
+		return repositories, func() {}
+	}
+
+	cachedUserRepository := repository.NewCachedUserRepository(repository.NewCachedUserRepositoryOptions{
+		Repository: repositories.User,
+		Capacity:   capacity,
+		TTL:        cfg.UserCache.TTL,
+	})
+
+	repositories.User = cachedUserRepository
+	repositories.UserCache = cachedUserRepository
+
+	if cfg.Repository.Driver != consts.RepositoryDriverPostgres {
+		return repositories, func() {}
+	}
+
+	listener, err := repository.NewUserChangeListener(repository.NewUserChangeListenerOptions{
+		Dsn:   cfg.Database.Url,
+		Cache: cachedUserRepository,
+		OnError: func(err error) {
+			slog.Error("user change listener failed", slog.Any("error", err))
+		},
+	})
+
+	if err != nil {
+		slog.Error("cannot listen for the user changes", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	return repositories, func() {
+		listener.Close()
+	}
+}
 
-	userService := services.NewUserService(repo, passwordAuth)
+// migrateDatabase applies the pending schema migrations, replicas starting together wait for each other.
+func migrateDatabase(db *sql.DB, driver string) error {
 
-	privateKey, err := os.ReadFile("cert/id_rsa")
+	migrator, err := newMigrator(db, driver)
 
 	if err != nil {
-		panic("cannot read private key")
+		return err
 	}
 
-	publicKey, err := os.ReadFile("cert/id_rsa.pub")
+	migrated, err := migrator.Up(context.Background())
+
+	for _, migration := range migrated {
+		slog.Info("migrated", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+	}
+
+	return err
+}
+
+func newMigrator(db *sql.DB, driver string) (*repository.Migrator, error) {
+
+	migrationDriver := repository.DriverPostgres
+
+	if driver == consts.RepositoryDriverSqlite {
+		migrationDriver = repository.DriverSqlite
+	}
+
+	return repository.NewMigrator(repository.NewMigratorOptions{
+		Db:     db,
+		Driver: migrationDriver,
+	})
+}
+
+// initLogger makes JSON records of LOG_LEVEL and above the default slog logger, which the log package writes to as well.
+func initLogger(logLevel slog.Leveler) {
+	slog.SetDefault(logging.NewLogger(os.Stdout, logLevel))
+}
+
+// initTracing exports the spans to the exporter TRACING_EXPORTER selects, "otlp" sends them over OTLP/HTTP to
+// TRACING_OTLP_ENDPOINT, or to the endpoint of the standard OTEL_EXPORTER_OTLP_* variables, and "stdout" prints them.
+// The returned func flushes the spans that are not exported yet.
+func initTracing(tracingConfig config.TracingConfig) func() {
+
+	// The trace context of the callers is propagated even when the spans are not exported.
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch tracingConfig.Exporter {
+	case consts.TracingExporterNone:
+		return func() {}
+	case consts.TracingExporterOtlp:
+
+		var opts []otlptracehttp.Option
+
+		if utils.StringIsEmpty(tracingConfig.OtlpEndpoint) == false {
+			opts = append(opts, otlptracehttp.WithEndpointURL(tracingConfig.OtlpEndpoint))
+		}
+
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	case consts.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		err = fmt.Errorf("TRACING_EXPORTER must be %s, %s or %s", consts.TracingExporterNone,
+			consts.TracingExporterOtlp, consts.TracingExporterStdout)
+	}
+
+	if err != nil {
+		slog.Error("cannot create the span exporter", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default service name.
+	serviceResource, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName(consts.DefaultTracingServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
 
 	if err != nil {
-		panic("cannot read public key")
+		slog.Error("cannot create the tracing resource", slog.Any("error", err))
+		os.Exit(1)
 	}
 
-	jwtAuth := modules.NewRS256Jwt(privateKey, publicKey)
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+
+	return func() {
+
+		ctx, cancel := context.WithTimeout(context.Background(), consts.DefaultTracingShutdownTimeout)
+		defer cancel()
+
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			slog.Error("flushing the spans failed", slog.Any("error", err))
+		}
+	}
+}
+
+func initServices(cfg *config.Config, repositories repository.Repositories, settings reloadableSettings) services.Services {
+
+	passwordAuth := modules.BcryptPasswordAuth{}
+
+	mailSender := initMailSender(cfg.Mail)
+
+	auditService := services.NewAuditService(repositories.AuditLog)
 
-	authenticationService := services.NewAuthenticationService(repo, passwordAuth, jwtAuth)
+	userService := services.NewUserService(repositories.User, passwordAuth, mailSender, auditService,
+		cfg.Account.DeletionGracePeriod, cfg.Account.EmailVerificationUrl)
+
+	jwtAuth := settings.jwtAuth
+
+	authenticationService := services.NewAuthenticationService(repositories.User, repositories.LoginEvent, passwordAuth, jwtAuth,
+		auditService, cfg.Application.Name, settings.getLoginExpirationDuration)
+
+	dataExportService := services.NewDataExportService(userService, authenticationService, auditService,
+		repositories.DataExport, modules.NewLocalFileStorage(cfg.DataExport.Directory), cfg.DataExport.DownloadUrl)
 
 	return services.Services{
 		Authentication: authenticationService,
 		User:           userService,
+		Audit:          auditService,
+		DataExport:     dataExportService,
+		System:         services.NewSystemService(repositories.Database, repositories.UserCache),
+		Health:         initHealthService(cfg, repositories, jwtAuth),
+	}
+}
+
+// initHealthService checks the database and its migrations, unless the data is in memory, and the key material.
+// Every check is cancelled after HEALTH_CHECK_TIMEOUT.
+func initHealthService(cfg *config.Config, repositories repository.Repositories, jwtAuth modules.JsonWebTokenUtilInterface) services.HealthServiceInterface {
+
+	var checks []services.HealthCheck
+
+	if driver := cfg.Repository.Driver; driver != consts.RepositoryDriverMemory {
+
+		// The migrations are embedded, reading their versions needs no database.
+		migrator, err := newMigrator(nil, driver)
+
+		if err != nil {
+			slog.Error("cannot read the migrations", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		checks = append(checks,
+			services.NewDatabaseHealthCheck(repositories.Database),
+			services.NewMigrationHealthCheck(repositories.Database, migrator.LatestVersion()),
+		)
 	}
+
+	checks = append(checks, services.NewKeyMaterialHealthCheck(jwtAuth))
+
+	return services.NewHealthService(checks, cfg.Health.CheckTimeout)
 }
 
-func initMiddlewares(e *echo.Echo, svc services.Services) {
+func initMailSender(mailConfig config.MailConfig) modules.MailSenderInterface {
+
+	if mailConfig.Driver == consts.MailDriverFile {
+		return modules.NewFileMailSender(mailConfig.FileDirectory, mailConfig.From)
+	}
+
+	return modules.NewSmtpMailSender(mailConfig.SmtpHost, mailConfig.SmtpPort,
+		mailConfig.SmtpUsername, mailConfig.SmtpPassword, mailConfig.From)
+}
+
+func initMiddlewares(e *echo.Echo, cfg *config.Config, svc services.Services) {
 
 	verifyJwtMiddleware := middlewares.NewVerifyJwtMiddleware(svc)
+	requestTimeoutMiddleware := middlewares.NewRequestTimeoutMiddleware(cfg.Request.Timeout, cfg.Request.RouteTimeouts)
+	readYourWritesMiddleware := middlewares.NewReadYourWritesMiddleware()
+	metricsMiddleware := middlewares.NewMetricsMiddleware()
+	requestIdMiddleware := middlewares.NewRequestIdMiddleware()
+	accessLogMiddleware := middlewares.NewAccessLogMiddleware(slog.Default())
+	tracingMiddleware := middlewares.NewTracingMiddleware(otel.GetTracerProvider(), otel.GetTextMapPropagator())
 
+	e.Use(metricsMiddleware.Process)
+	e.Use(tracingMiddleware.Process)
+	e.Use(requestIdMiddleware.Process)
+	e.Use(accessLogMiddleware.Process)
+	e.Use(middleware.BodyLimit(cfg.Server.MaxBodySize))
+	e.Use(requestTimeoutMiddleware.Process)
+	e.Use(readYourWritesMiddleware.Process)
 	e.Use(verifyJwtMiddleware.Process)
 }
 
+// initMetrics serves the Prometheus metrics on GET /metrics to scrapers sending METRICS_TOKEN as bearer token.
+// Without METRICS_TOKEN the route does not exist, the metrics are never public.
+func initMetrics(e *echo.Echo, metricsConfig config.MetricsConfig, repositories repository.Repositories) {
+
+	metrics.Registry.MustRegister(metrics.NewDatabaseCollector(repositories.Database, repositories.UserCache))
+
+	token := metricsConfig.Token
+
+	if utils.StringIsEmpty(token) {
+		slog.Info("METRICS_TOKEN is not set, GET /metrics is disabled")
+		return
+	}
+
+	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})),
+		middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+			return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
+		}))
+}
+
 func newServer(svc services.Services) *handler.Server {
 
 	opts := handler.NewServerOptions{
 		UserService:           svc.User,
 		AuthenticationService: svc.Authentication,
+		AuditService:          svc.Audit,
+		DataExportService:     svc.DataExport,
+		SystemService:         svc.System,
+		HealthService:         svc.Health,
 	}
 	return handler.NewServer(opts)
 }
+
+// runAccountDeletionJob periodically anonymizes the accounts whose deletion grace period is over and deletes their
+// export archives, until ctx is done.
+func runAccountDeletionJob(ctx context.Context, interval time.Duration, userService services.UserServiceInterface,
+	dataExportService services.DataExportServiceInterface) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// A run is not cancelled by the shutdown, it would leave anonymized accounts without their audit log entry.
+		anonymizedCount, err := userService.AnonymizeDueAccounts(context.Background())
+
+		if err != nil {
+			slog.Error("account deletion job failed", slog.Int("anonymized_count", anonymizedCount), slog.Any("error", err))
+			continue
+		}
+
+		if anonymizedCount == 0 {
+			continue
+		}
+
+		slog.Info("account deletion job anonymized accounts", slog.Int("anonymized_count", anonymizedCount))
+
+		// The anonymization expired the exports of the accounts, so their archives are deleted now.
+		deletedCount, err := dataExportService.DeleteExpiredArchives(context.Background())
+
+		if err != nil {
+			slog.Error("account deletion job failed to delete the export archives", slog.Int("deleted_count", deletedCount),
+				slog.Any("error", err))
+		}
+	}
+}
+
+// runDataExportCleanupJob marks the exports failed whose build was interrupted, at once and then every interval
+// together with deleting the archives of the expired exports, until ctx is done.
+func runDataExportCleanupJob(ctx context.Context, interval time.Duration, dataExportService services.DataExportServiceInterface) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+
+		failedCount, err := dataExportService.FailStaleExports(ctx)
+
+		if err != nil {
+			slog.Error("data export cleanup job failed to mark the interrupted exports failed", slog.Any("error", err))
+		} else if failedCount > 0 {
+			slog.Warn("data export cleanup job marked interrupted exports failed", slog.Int("failed_count", failedCount))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deletedCount, err := dataExportService.DeleteExpiredArchives(ctx)
+
+		if err != nil {
+			slog.Error("data export cleanup job failed", slog.Int("deleted_count", deletedCount), slog.Any("error", err))
+			continue
+		}
+
+		if deletedCount > 0 {
+			slog.Info("data export cleanup job deleted expired archives", slog.Int("deleted_count", deletedCount))
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/SawitProRecruitment/UserService/modules"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// reloadableSettingNames are the settings a reload applies, the others take effect after a restart.
+var reloadableSettingNames = []string{
+	"LOG_LEVEL",
+	"LOGIN_EXPIRATION_DURATION",
+	"JWT_PRIVATE_KEY_PATH",
+	"JWT_PUBLIC_KEY_PATH",
+	"JWT_KEY_DIRECTORY",
+}
+
+// reloadableSettings are the settings and the keys the services read on every use, so a reload swaps them while
+// the requests are served.
+type reloadableSettings struct {
+	jwtAuth                 modules.ReloadableRS256Jwt
+	logLevel                *slog.LevelVar
+	loginExpirationDuration *atomic.Int64
+}
+
+func (s reloadableSettings) getLoginExpirationDuration() time.Duration {
+	return time.Duration(s.loginExpirationDuration.Load())
+}
+
+// apply swaps the settings of cfg in, or none of them when the keys cannot be loaded or do not work.
+func (s reloadableSettings) apply(cfg *config.Config) error {
+
+	keySet, err := loadKeySet(cfg.Authentication)
+
+	if err != nil {
+		return err
+	}
+
+	s.jwtAuth.SetKeySet(keySet)
+	s.logLevel.Set(cfg.Log.Level)
+	s.loginExpirationDuration.Store(int64(cfg.Authentication.LoginExpirationDuration))
+
+	return nil
+}
+
+// newReloadableSettings exits when the keys cannot be loaded, the service cannot issue tokens without them.
+func newReloadableSettings(cfg *config.Config, logLevel *slog.LevelVar) reloadableSettings {
+
+	keySet, err := loadKeySet(cfg.Authentication)
+
+	if err != nil {
+		slog.Error("cannot load the keys", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	s := reloadableSettings{
+		jwtAuth:                 modules.NewReloadableRS256Jwt(keySet),
+		logLevel:                logLevel,
+		loginExpirationDuration: &atomic.Int64{},
+	}
+
+	s.loginExpirationDuration.Store(int64(cfg.Authentication.LoginExpirationDuration))
+
+	return s
+}
+
+// loadKeySet reads the keys of JWT_KEY_DIRECTORY or, when it is not set, of JWT_PRIVATE_KEY_PATH and JWT_PUBLIC_KEY_PATH.
+func loadKeySet(authenticationConfig config.AuthenticationConfig) (*modules.RS256KeySet, error) {
+
+	if directory := authenticationConfig.KeyDirectory; directory != "" {
+
+		keySet, err := modules.LoadRS256KeySetFromDirectory(directory, consts.JwtKeyDirectorySigningKeyFile,
+			consts.JwtKeyDirectoryPublicKeyPattern)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the keys of JWT_KEY_DIRECTORY %s: %w", directory, err)
+		}
+
+		return keySet, nil
+	}
+
+	keySet, err := modules.LoadRS256KeySet(authenticationConfig.PrivateKeyPath, authenticationConfig.PublicKeyPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the keys of JWT_PRIVATE_KEY_PATH %s and JWT_PUBLIC_KEY_PATH %s: %w",
+			authenticationConfig.PrivateKeyPath, authenticationConfig.PublicKeyPath, err)
+	}
+
+	return keySet, nil
+}
+
+// getWatchedFiles are the config file and the key files whose changes trigger a reload.
+func getWatchedFiles(cfg *config.Config) []string {
+
+	var files []string
+
+	if cfg.File() != "" {
+		files = append(files, cfg.File())
+	}
+
+	directory := cfg.Authentication.KeyDirectory
+
+	if directory == "" {
+		return append(files, cfg.Authentication.PrivateKeyPath, cfg.Authentication.PublicKeyPath)
+	}
+
+	// Adding or removing a public key changes the directory.
+	files = append(files, directory)
+
+	entries, _ := os.ReadDir(directory)
+
+	for _, entry := range entries {
+		files = append(files, filepath.Join(directory, entry.Name()))
+	}
+
+	return files
+}
+
+// getFilesVersion changes whenever one of the files changes. The files are followed through symbolic links, like the
+// keys of a Kubernetes secret, which are replaced by switching a link.
+func getFilesVersion(files []string) string {
+
+	var version strings.Builder
+
+	for _, file := range files {
+
+		info, err := os.Stat(file)
+
+		if err != nil {
+			fmt.Fprintf(&version, "%s:missing;", file)
+			continue
+		}
+
+		fmt.Fprintf(&version, "%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return version.String()
+}
+
+// reload loads the settings again from args, the environment and the config file, and applies the reloadable ones.
+// On failure the previous settings stay in use. It returns the settings now in use.
+func reload(ctx context.Context, args []string, startup *config.Config, current *config.Config,
+	settings reloadableSettings, trigger string) *config.Config {
+
+	start := time.Now()
+
+	cfg, _, err := config.Load(args, os.LookupEnv)
+
+	if err == nil {
+		err = settings.apply(cfg)
+	}
+
+	if err != nil {
+		metrics.ConfigReloads.WithLabelValues(metrics.ResultFailure).Inc()
+		slog.ErrorContext(ctx, "reload failed, the previous settings and keys stay in use", slog.String("trigger", trigger),
+			slog.Any("error", err))
+		return current
+	}
+
+	metrics.ConfigReloads.WithLabelValues(metrics.ResultSuccess).Inc()
+
+	keySet := settings.jwtAuth.KeySet()
+
+	slog.InfoContext(ctx, "reloaded the settings and keys", slog.String("trigger", trigger),
+		slog.Any("changed", config.ChangedSettings(current, cfg)),
+		slog.String("signing_key_id", keySet.SigningKeyId()),
+		slog.Any("verification_key_ids", keySet.VerificationKeyIds()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000))
+
+	var restartRequired []string
+
+	for _, name := range config.ChangedSettings(startup, cfg) {
+		if slices.Contains(reloadableSettingNames, name) == false {
+			restartRequired = append(restartRequired, name)
+		}
+	}
+
+	if len(restartRequired) > 0 {
+		slog.WarnContext(ctx, "changed settings take effect after a restart", slog.Any("settings", restartRequired))
+	}
+
+	return cfg
+}
+
+// watchSettings reloads the settings on SIGHUP and, every RELOAD_WATCH_INTERVAL, when the config file or the keys
+// changed, until ctx is done. A reload does not interrupt the requests, they use either the previous or the new keys.
+func watchSettings(ctx context.Context, args []string, startup *config.Config, settings reloadableSettings) {
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+
+	if interval := startup.Reload.WatchInterval; interval > 0 {
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	current := startup
+	filesVersion := getFilesVersion(getWatchedFiles(current))
+
+	for {
+
+		var trigger string
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			trigger = "signal"
+		case <-tick:
+
+			version := getFilesVersion(getWatchedFiles(current))
+
+			if version == filesVersion {
+				continue
+			}
+
+			trigger = "file_change"
+		}
+
+		current = reload(ctx, args, startup, current, settings, trigger)
+
+		// A failed reload is not retried until the files change again.
+		filesVersion = getFilesVersion(getWatchedFiles(current))
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/services"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// initHttpServer limits how long a client may take to send a request and to read the response, and how long an idle
+// keep-alive connection stays open, so slow clients cannot hold connections forever.
+func initHttpServer(e *echo.Echo, serverConfig config.ServerConfig) {
+
+	e.Server.ReadHeaderTimeout = serverConfig.ReadHeaderTimeout
+	e.Server.ReadTimeout = serverConfig.ReadTimeout
+	e.Server.WriteTimeout = serverConfig.WriteTimeout
+	e.Server.IdleTimeout = serverConfig.IdleTimeout
+	e.Server.MaxHeaderBytes = serverConfig.MaxHeaderBytes
+}
+
+// serve runs the HTTP server until ctx is done. Then GET /readyz fails right away, SERVER_SHUTDOWN_DELAY later the
+// server stops accepting connections, and the running requests and data exports get SERVER_SHUTDOWN_TIMEOUT to finish.
+// It returns once they did, so the database is only closed afterwards.
+func serve(ctx context.Context, e *echo.Echo, serverConfig config.ServerConfig, healthService services.HealthServiceInterface) {
+
+	address := serverConfig.Address
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		serverErr <- e.Start(address)
+	}()
+
+	slog.Info("http server started", slog.String("address", address))
+
+	select {
+	case err := <-serverErr:
+		if errors.Is(err, http.ErrServerClosed) == false {
+			slog.Error("http server failed", slog.Any("error", err))
+		}
+		return
+	case <-ctx.Done():
+	}
+
+	shutdownDelay := serverConfig.ShutdownDelay
+
+	slog.Info("shutting down", slog.Duration("delay", shutdownDelay))
+
+	healthService.StartShutdown()
+
+	// The load balancers keep sending requests until they noticed the failing readiness.
+	time.Sleep(shutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		slog.Error("http server did not finish the running requests", slog.Any("error", err))
+		e.Close()
+	}
+
+	if err := services.WaitForBackgroundTasks(shutdownCtx); err != nil {
+		slog.Error("data exports and mails did not finish", slog.Any("error", err))
+	}
+
+	slog.Info("http server stopped")
+}
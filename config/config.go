@@ -0,0 +1,221 @@
+// Package config loads the settings of the service at startup and again when they are reloaded. Each setting has a
+// default, which the YAML file, the environment variable and the flag of the setting override in this order.
+package config
+
+import (
+	"github.com/SawitProRecruitment/UserService/consts"
+	"log/slog"
+	"time"
+)
+
+// Config is every setting of the service. The env tag names the environment variable of a setting, its flag is the
+// lower case name with dashes, e.g. -database-max-open-conns, and the yaml tags give its path in the file.
+type Config struct {
+	Application    ApplicationConfig    `yaml:"application"`
+	Authentication AuthenticationConfig `yaml:"authentication"`
+	Server         ServerConfig         `yaml:"server"`
+	Request        RequestConfig        `yaml:"request"`
+	Repository     RepositoryConfig     `yaml:"repository"`
+	Database       DatabaseConfig       `yaml:"database"`
+	Sqlite         SqliteConfig         `yaml:"sqlite"`
+	UserCache      UserCacheConfig      `yaml:"user_cache"`
+	Mail           MailConfig           `yaml:"mail"`
+	Account        AccountConfig        `yaml:"account"`
+	DataExport     DataExportConfig     `yaml:"data_export"`
+	Log            LogConfig            `yaml:"log"`
+	Tracing        TracingConfig        `yaml:"tracing"`
+	Metrics        MetricsConfig        `yaml:"metrics"`
+	Health         HealthConfig         `yaml:"health"`
+	Reload         ReloadConfig         `yaml:"reload"`
+
+	// file is the YAML file the settings were loaded from, empty when there is none.
+	file string
+}
+
+// File is the YAML file the settings were loaded from, empty when there is none.
+func (c Config) File() string {
+	return c.file
+}
+
+type ApplicationConfig struct {
+	// Name is the issuer of the tokens.
+	Name string `yaml:"name" env:"APPLICATION_NAME"`
+}
+
+type AuthenticationConfig struct {
+	LoginExpirationDuration time.Duration `yaml:"login_expiration_duration" env:"LOGIN_EXPIRATION_DURATION"`
+	PrivateKeyPath          string        `yaml:"private_key_path" env:"JWT_PRIVATE_KEY_PATH"`
+	PublicKeyPath           string        `yaml:"public_key_path" env:"JWT_PUBLIC_KEY_PATH"`
+	// KeyDirectory replaces the key paths when set, see consts.JwtKeyDirectorySigningKeyFile.
+	KeyDirectory string `yaml:"key_directory" env:"JWT_KEY_DIRECTORY"`
+}
+
+type ServerConfig struct {
+	Address           string        `yaml:"address" env:"SERVER_ADDRESS"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+	// MaxBodySize is a size like 512K or 1M.
+	MaxBodySize     string        `yaml:"max_body_size" env:"SERVER_MAX_BODY_SIZE"`
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+}
+
+type RequestConfig struct {
+	Timeout       time.Duration `yaml:"timeout" env:"REQUEST_TIMEOUT"`
+	RouteTimeouts RouteTimeouts `yaml:"route_timeouts" env:"REQUEST_ROUTE_TIMEOUTS"`
+}
+
+type RepositoryConfig struct {
+	Driver string `yaml:"driver" env:"REPOSITORY_DRIVER"`
+}
+
+type DatabaseConfig struct {
+	Url                            string        `yaml:"url" env:"DATABASE_URL"`
+	ReplicaUrls                    []string      `yaml:"replica_urls" env:"DATABASE_REPLICA_URLS"`
+	AutoMigrate                    bool          `yaml:"auto_migrate" env:"DATABASE_AUTO_MIGRATE"`
+	MaxOpenConns                   int           `yaml:"max_open_conns" env:"DATABASE_MAX_OPEN_CONNS"`
+	MaxIdleConns                   int           `yaml:"max_idle_conns" env:"DATABASE_MAX_IDLE_CONNS"`
+	ConnMaxLifetime                time.Duration `yaml:"conn_max_lifetime" env:"DATABASE_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime                time.Duration `yaml:"conn_max_idle_time" env:"DATABASE_CONN_MAX_IDLE_TIME"`
+	ConnectTimeout                 time.Duration `yaml:"connect_timeout" env:"DATABASE_CONNECT_TIMEOUT"`
+	RetryMaxAttempts               int           `yaml:"retry_max_attempts" env:"DATABASE_RETRY_MAX_ATTEMPTS"`
+	RetryInitialBackoff            time.Duration `yaml:"retry_initial_backoff" env:"DATABASE_RETRY_INITIAL_BACKOFF"`
+	RetryMaxBackoff                time.Duration `yaml:"retry_max_backoff" env:"DATABASE_RETRY_MAX_BACKOFF"`
+	CircuitBreakerFailureThreshold int           `yaml:"circuit_breaker_failure_threshold" env:"DATABASE_CIRCUIT_BREAKER_FAILURE_THRESHOLD"`
+	CircuitBreakerOpenDuration     time.Duration `yaml:"circuit_breaker_open_duration" env:"DATABASE_CIRCUIT_BREAKER_OPEN_DURATION"`
+}
+
+type SqliteConfig struct {
+	Path        string        `yaml:"path" env:"SQLITE_PATH"`
+	BusyTimeout time.Duration `yaml:"busy_timeout" env:"SQLITE_BUSY_TIMEOUT"`
+}
+
+type UserCacheConfig struct {
+	// Size is 0 when the users are not cached.
+	Size int           `yaml:"size" env:"USER_CACHE_SIZE"`
+	TTL  time.Duration `yaml:"ttl" env:"USER_CACHE_TTL"`
+}
+
+type MailConfig struct {
+	Driver        string `yaml:"driver" env:"MAIL_DRIVER"`
+	From          string `yaml:"from" env:"MAIL_FROM"`
+	FileDirectory string `yaml:"file_directory" env:"MAIL_FILE_DIRECTORY"`
+	SmtpHost      string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SmtpPort      string `yaml:"smtp_port" env:"SMTP_PORT"`
+	SmtpUsername  string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SmtpPassword  string `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+}
+
+type AccountConfig struct {
+	DeletionGracePeriod  time.Duration `yaml:"deletion_grace_period" env:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	DeletionJobInterval  time.Duration `yaml:"deletion_job_interval" env:"ACCOUNT_DELETION_JOB_INTERVAL"`
+	EmailVerificationUrl string        `yaml:"email_verification_url" env:"EMAIL_VERIFICATION_URL"`
+}
+
+type DataExportConfig struct {
+	Directory       string        `yaml:"directory" env:"DATA_EXPORT_DIRECTORY"`
+	DownloadUrl     string        `yaml:"download_url" env:"DATA_EXPORT_DOWNLOAD_URL"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"DATA_EXPORT_CLEANUP_INTERVAL"`
+}
+
+type LogConfig struct {
+	Level slog.Level `yaml:"level" env:"LOG_LEVEL"`
+}
+
+type TracingConfig struct {
+	Exporter     string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	OtlpEndpoint string `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+}
+
+type MetricsConfig struct {
+	// Token is empty when GET /metrics is disabled.
+	Token string `yaml:"token" env:"METRICS_TOKEN"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+type ReloadConfig struct {
+	// WatchInterval is how often the config file and the keys are checked for changes, 0 when only SIGHUP reloads.
+	WatchInterval time.Duration `yaml:"watch_interval" env:"RELOAD_WATCH_INTERVAL"`
+}
+
+// Default returns the settings used when neither the file, the environment nor the flags set them.
+func Default() Config {
+
+	return Config{
+		Application: ApplicationConfig{
+			Name: consts.DefaultApplicationName,
+		},
+		Authentication: AuthenticationConfig{
+			LoginExpirationDuration: consts.DefaultLoginExpirationDuration,
+			PrivateKeyPath:          consts.DefaultJwtPrivateKeyPath,
+			PublicKeyPath:           consts.DefaultJwtPublicKeyPath,
+		},
+		Server: ServerConfig{
+			Address:           consts.DefaultServerAddress,
+			ReadHeaderTimeout: consts.DefaultServerReadHeaderTimeout,
+			ReadTimeout:       consts.DefaultServerReadTimeout,
+			WriteTimeout:      consts.DefaultServerWriteTimeout,
+			IdleTimeout:       consts.DefaultServerIdleTimeout,
+			MaxHeaderBytes:    consts.DefaultServerMaxHeaderBytes,
+			MaxBodySize:       consts.DefaultServerMaxBodySize,
+			ShutdownTimeout:   consts.DefaultServerShutdownTimeout,
+		},
+		Request: RequestConfig{
+			Timeout:       consts.DefaultRequestTimeout,
+			RouteTimeouts: RouteTimeouts{},
+		},
+		Repository: RepositoryConfig{
+			Driver: consts.RepositoryDriverPostgres,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:                   consts.DefaultDatabaseMaxOpenConns,
+			MaxIdleConns:                   consts.DefaultDatabaseMaxIdleConns,
+			ConnMaxLifetime:                consts.DefaultDatabaseConnMaxLifetime,
+			ConnMaxIdleTime:                consts.DefaultDatabaseConnMaxIdleTime,
+			ConnectTimeout:                 consts.DefaultDatabaseConnectTimeout,
+			RetryMaxAttempts:               consts.DefaultDatabaseRetryMaxAttempts,
+			RetryInitialBackoff:            consts.DefaultDatabaseRetryInitialBackoff,
+			RetryMaxBackoff:                consts.DefaultDatabaseRetryMaxBackoff,
+			CircuitBreakerFailureThreshold: consts.DefaultDatabaseCircuitBreakerFailureThreshold,
+			CircuitBreakerOpenDuration:     consts.DefaultDatabaseCircuitBreakerOpenDuration,
+		},
+		Sqlite: SqliteConfig{
+			Path:        consts.DefaultSqlitePath,
+			BusyTimeout: consts.DefaultSqliteBusyTimeout,
+		},
+		UserCache: UserCacheConfig{
+			TTL: consts.DefaultUserCacheTTL,
+		},
+		Mail: MailConfig{
+			Driver: consts.MailDriverSmtp,
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod:  consts.DefaultAccountDeletionGracePeriod,
+			DeletionJobInterval:  consts.DefaultAccountDeletionJobInterval,
+			EmailVerificationUrl: consts.DefaultEmailVerificationUrl,
+		},
+		DataExport: DataExportConfig{
+			Directory:       consts.DefaultDataExportDirectory,
+			DownloadUrl:     consts.DefaultDataExportDownloadUrl,
+			CleanupInterval: consts.DefaultDataExportCleanupInterval,
+		},
+		Log: LogConfig{
+			Level: slog.LevelInfo,
+		},
+		Tracing: TracingConfig{
+			Exporter: consts.TracingExporterNone,
+		},
+		Health: HealthConfig{
+			CheckTimeout: consts.DefaultHealthCheckTimeout,
+		},
+		Reload: ReloadConfig{
+			WatchInterval: consts.DefaultReloadWatchInterval,
+		},
+	}
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting is a leaf field of Config, named by its env tag.
+type setting struct {
+	env   string
+	value reflect.Value
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(s.env), "_", "-")
+}
+
+// set parses value, the text of an environment variable or a flag.
+func (s setting) set(value string) error {
+
+	target := s.value
+
+	if unmarshaler, ok := target.Addr().Interface().(encoding.TextUnmarshaler); ok {
+
+		err := unmarshaler.UnmarshalText([]byte(strings.TrimSpace(value)))
+
+		if err != nil {
+			return fmt.Errorf("is invalid: %w", err)
+		}
+
+		return nil
+	}
+
+	switch {
+	case target.Type() == reflect.TypeOf(time.Duration(0)):
+
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+
+		if err != nil {
+			return fmt.Errorf("must be a duration like 30s or 5m, got %q", value)
+		}
+
+		target.SetInt(int64(duration))
+	case target.Kind() == reflect.String:
+		target.SetString(value)
+	case target.Kind() == reflect.Int:
+
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", value)
+		}
+
+		target.SetInt(int64(number))
+	case target.Kind() == reflect.Bool:
+
+		boolean, err := strconv.ParseBool(strings.TrimSpace(value))
+
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+
+		target.SetBool(boolean)
+	case target.Type() == reflect.TypeOf([]string{}):
+
+		// A comma separated list, e.g. DATABASE_REPLICA_URLS.
+		var items []string
+
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+
+		target.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("has the unsupported type %s", target.Type())
+	}
+
+	return nil
+}
+
+// getSettings returns the fields of cfg that have an env tag, they can be set through the returned values.
+func getSettings(cfg *Config) []setting {
+
+	var settings []setting
+
+	var walk func(value reflect.Value)
+
+	walk = func(value reflect.Value) {
+
+		for i := 0; i < value.NumField(); i++ {
+
+			field := value.Type().Field(i)
+
+			if env, ok := field.Tag.Lookup("env"); ok {
+				settings = append(settings, setting{env: env, value: value.Field(i)})
+				continue
+			}
+
+			if field.Type.Kind() == reflect.Struct {
+				walk(value.Field(i))
+			}
+		}
+	}
+
+	walk(reflect.ValueOf(cfg).Elem())
+
+	return settings
+}
+
+// Load builds the settings from the defaults, the YAML file of the -config flag or CONFIG_FILE, the environment
+// variables lookupEnv finds and the flags of args, and validates them. Every invalid setting is reported, not only
+// the first. It also returns the arguments after the flags, the command to run.
+func Load(args []string, lookupEnv func(name string) (string, bool)) (*Config, []string, error) {
+
+	cfg := Default()
+	settings := getSettings(&cfg)
+
+	flagSet := flag.NewFlagSet("main", flag.ContinueOnError)
+	configFile := flagSet.String("config", "", "YAML file with the settings, CONFIG_FILE when not given")
+
+	// The flags override the file and the environment, so they are applied last.
+	flagValues := map[string]string{}
+
+	for _, s := range settings {
+
+		s := s
+
+		flagSet.Func(s.flagName(), "overrides "+s.env, func(value string) error {
+			flagValues[s.env] = value
+			return nil
+		})
+	}
+
+	err := flagSet.Parse(args)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path := *configFile
+
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+
+	if path != "" {
+
+		err = loadFile(&cfg, path)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cfg.file = path
+	}
+
+	var errs []error
+
+	for _, s := range settings {
+
+		// An empty variable counts as not set, like in docker-compose files that list every variable.
+		if value, ok := lookupEnv(s.env); ok && strings.TrimSpace(value) != "" {
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s %w", s.env, err))
+			}
+		}
+
+		if value, ok := flagValues[s.env]; ok {
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("-%s %w", s.flagName(), err))
+			}
+		}
+	}
+
+	// A setting that cannot be parsed keeps its default, so validating the others reports no false errors.
+	err = errors.Join(append(errs, cfg.Validate())...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &cfg, flagSet.Args(), nil
+}
+
+// ChangedSettings returns the environment variables of the settings whose values differ between previous and current.
+func ChangedSettings(previous *Config, current *Config) []string {
+
+	var changed []string
+
+	currentSettings := getSettings(current)
+
+	for i, s := range getSettings(previous) {
+		if reflect.DeepEqual(s.value.Interface(), currentSettings[i].value.Interface()) == false {
+			changed = append(changed, s.env)
+		}
+	}
+
+	return changed
+}
+
+// loadFile sets what the YAML file at path sets, a key that is not a setting is an error so typos do not go unnoticed.
+func loadFile(cfg *Config, path string) error {
+
+	content, err := os.ReadFile(path)
+
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(cfg)
+
+	// An empty file sets nothing.
+	if err != nil && errors.Is(err, io.EOF) == false {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return nil
+}
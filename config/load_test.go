@@ -0,0 +1,200 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		file        string
+		want        func(cfg *Config)
+		wantArgs    []string
+		wantErrors  []string
+		wantNoError bool
+	}{
+		{
+			name:        "When nothing is set, it will return the defaults and the command",
+			args:        []string{"migrate", "up"},
+			want:        func(cfg *Config) {},
+			wantArgs:    []string{"migrate", "up"},
+			wantNoError: true,
+		},
+		{
+			name: "When the environment sets settings, it will parse them by their type",
+			env: map[string]string{
+				"LOGIN_EXPIRATION_DURATION": "1h",
+				"DATABASE_MAX_OPEN_CONNS":   "10",
+				"DATABASE_AUTO_MIGRATE":     "true",
+				"DATABASE_REPLICA_URLS":     "postgres://a, postgres://b,",
+				"REQUEST_ROUTE_TIMEOUTS":    "POST /users/me/export=30s",
+				"LOG_LEVEL":                 "DEBUG",
+				"MAIL_FROM":                 "",
+			},
+			want: func(cfg *Config) {
+				cfg.Authentication.LoginExpirationDuration = time.Hour
+				cfg.Database.MaxOpenConns = 10
+				cfg.Database.AutoMigrate = true
+				cfg.Database.ReplicaUrls = []string{"postgres://a", "postgres://b"}
+				cfg.Request.RouteTimeouts = RouteTimeouts{"POST /users/me/export": 30 * time.Second}
+				cfg.Log.Level = slog.LevelDebug
+			},
+			wantNoError: true,
+		},
+		{
+			name: "When the file, the environment and a flag set a setting, it will use the flag",
+			args: []string{"-login-expiration-duration", "3h", "audit", "verify"},
+			env: map[string]string{
+				"LOGIN_EXPIRATION_DURATION": "2h",
+				"APPLICATION_NAME":          "from-env",
+			},
+			file: `
+application:
+  name: from-file
+authentication:
+  login_expiration_duration: 1h
+request:
+  route_timeouts:
+    GET /admin/audit-logs: 30s
+sqlite:
+  path: /var/lib/user-service/user_service.db
+`,
+			want: func(cfg *Config) {
+				cfg.Application.Name = "from-env"
+				cfg.Authentication.LoginExpirationDuration = 3 * time.Hour
+				cfg.Request.RouteTimeouts = RouteTimeouts{"GET /admin/audit-logs": 30 * time.Second}
+				cfg.Sqlite.Path = "/var/lib/user-service/user_service.db"
+			},
+			wantArgs:    []string{"audit", "verify"},
+			wantNoError: true,
+		},
+		{
+			name: "When settings cannot be parsed, it will return every one of them",
+			args: []string{"-database-max-idle-conns", "many"},
+			env: map[string]string{
+				"LOGIN_EXPIRATION_DURATION": "1 day",
+				"DATABASE_MAX_OPEN_CONNS":   "ten",
+			},
+			wantErrors: []string{
+				`LOGIN_EXPIRATION_DURATION must be a duration like 30s or 5m, got "1 day"`,
+				`DATABASE_MAX_OPEN_CONNS must be a number, got "ten"`,
+				`-database-max-idle-conns must be a number, got "many"`,
+			},
+		},
+		{
+			name: "When settings are invalid, it will return every one of them",
+			env: map[string]string{
+				"REPOSITORY_DRIVER":     "mysql",
+				"MAIL_DRIVER":           "file",
+				"USER_CACHE_SIZE":       "-1",
+				"DATABASE_REPLICA_URLS": "postgres://a",
+			},
+			wantErrors: []string{
+				`REPOSITORY_DRIVER must be postgres, sqlite or memory, got "mysql"`,
+				"DATABASE_REPLICA_URLS are only supported by the postgres repository",
+				"USER_CACHE_SIZE must not be negative",
+				"MAIL_FILE_DIRECTORY must be set when MAIL_DRIVER is file",
+			},
+		},
+		{
+			name: "When the file has a key that is not a setting, it will return error",
+			file: `
+database:
+  max_open_connections: 10
+`,
+			wantErrors: []string{"field max_open_connections not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			env := map[string]string{}
+
+			for name, value := range tt.env {
+				env[name] = value
+			}
+
+			if tt.file != "" {
+
+				path := filepath.Join(t.TempDir(), "config.yml")
+
+				if err := os.WriteFile(path, []byte(tt.file), 0600); err != nil {
+					t.Fatal(err)
+				}
+
+				env["CONFIG_FILE"] = path
+			}
+
+			got, gotArgs, err := Load(tt.args, func(name string) (string, bool) {
+				value, ok := env[name]
+				return value, ok
+			})
+
+			if tt.wantNoError {
+
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+
+				want := Default()
+				tt.want(&want)
+				want.file = env["CONFIG_FILE"]
+
+				if !reflect.DeepEqual(*got, want) {
+					t.Errorf("Load() got = %+v, want %+v", *got, want)
+				}
+
+				if !reflect.DeepEqual(gotArgs, tt.wantArgs) && (len(gotArgs) > 0 || len(tt.wantArgs) > 0) {
+					t.Errorf("Load() args = %v, want %v", gotArgs, tt.wantArgs)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Load() error = nil, want %v", tt.wantErrors)
+			}
+
+			for _, wantError := range tt.wantErrors {
+				if strings.Contains(err.Error(), wantError) == false {
+					t.Errorf("Load() error = %v, want it to contain %q", err, wantError)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+
+	if err := Default().Validate(); err != nil {
+		t.Errorf("Validate() of the defaults error = %v", err)
+	}
+}
+
+func TestChangedSettings(t *testing.T) {
+
+	previous := Default()
+
+	current := Default()
+	current.Log.Level = slog.LevelDebug
+	current.Database.ReplicaUrls = []string{"postgres://a"}
+	current.Request.RouteTimeouts = RouteTimeouts{"POST /users/me/export": 30 * time.Second}
+
+	want := []string{"REQUEST_ROUTE_TIMEOUTS", "DATABASE_REPLICA_URLS", "LOG_LEVEL"}
+
+	if got := ChangedSettings(&previous, &current); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedSettings() = %v, want %v", got, want)
+	}
+
+	if got := ChangedSettings(&previous, &previous); len(got) != 0 {
+		t.Errorf("ChangedSettings() of the same settings = %v, want none", got)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RouteTimeouts are the request timeouts of single routes, keyed by method and echo route path,
+// e.g. "PUT /admin/users/:id/role".
+type RouteTimeouts map[string]time.Duration
+
+// UnmarshalText parses the environment variable and flag format, see ParseRouteTimeouts.
+func (r *RouteTimeouts) UnmarshalText(text []byte) error {
+
+	routeTimeouts, err := ParseRouteTimeouts(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	*r = routeTimeouts
+
+	return nil
+}
+
+// ParseRouteTimeouts parses a comma separated list of route timeouts,
+// e.g. "POST /users/me/export=30s,GET /admin/audit-logs=5s".
+func ParseRouteTimeouts(value string) (RouteTimeouts, error) {
+
+	routeTimeouts := RouteTimeouts{}
+
+	for _, entry := range strings.Split(value, ",") {
+
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		route, configuredTimeout, found := strings.Cut(entry, "=")
+
+		if found == false || len(strings.Fields(route)) != 2 {
+			return nil, fmt.Errorf("invalid route timeout %q, expected \"METHOD /path=duration\"", entry)
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(configuredTimeout))
+
+		if err != nil {
+			return nil, err
+		}
+
+		routeTimeouts[strings.Join(strings.Fields(route), " ")] = timeout
+	}
+
+	return routeTimeouts, nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRouteTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    RouteTimeouts
+		wantErr bool
+	}{
+		{
+			name:  "When given a list of route timeouts, it will return the timeout per method and path",
+			value: "POST /users/me/export=30s, GET  /admin/audit-logs=5s,",
+			want: RouteTimeouts{
+				"POST /users/me/export": 30 * time.Second,
+				"GET /admin/audit-logs": 5 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "When given an empty value, it will return no route timeouts",
+			value:   "",
+			want:    RouteTimeouts{},
+			wantErr: false,
+		},
+		{
+			name:    "When a route has no method, it will return error",
+			value:   "/users/me/export=30s",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "When a timeout is not a duration, it will return error",
+			value:   "POST /users/me/export=soon",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRouteTimeouts(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRouteTimeouts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRouteTimeouts() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/labstack/gommon/bytes"
+	"strings"
+)
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(isValid bool, format string, args ...any) {
+	if isValid == false {
+		v.errs = append(v.errs, fmt.Errorf(format, args...))
+	}
+}
+
+func isOneOf(value string, allowed ...string) bool {
+
+	for _, allowedValue := range allowed {
+		if value == allowedValue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate returns every invalid setting, named by its environment variable, nil when all are valid.
+func (c Config) Validate() error {
+
+	v := validator{}
+
+	v.check(strings.TrimSpace(c.Application.Name) != "", "APPLICATION_NAME must not be empty")
+
+	v.check(c.Authentication.LoginExpirationDuration > 0, "LOGIN_EXPIRATION_DURATION must be positive")
+	v.check(c.Authentication.KeyDirectory != "" || c.Authentication.PrivateKeyPath != "",
+		"JWT_PRIVATE_KEY_PATH must not be empty unless JWT_KEY_DIRECTORY is set")
+	v.check(c.Authentication.KeyDirectory != "" || c.Authentication.PublicKeyPath != "",
+		"JWT_PUBLIC_KEY_PATH must not be empty unless JWT_KEY_DIRECTORY is set")
+
+	v.check(c.Server.Address != "", "SERVER_ADDRESS must not be empty")
+	v.check(c.Server.ReadHeaderTimeout > 0, "SERVER_READ_HEADER_TIMEOUT must be positive")
+	v.check(c.Server.ReadTimeout > 0, "SERVER_READ_TIMEOUT must be positive")
+	v.check(c.Server.WriteTimeout > 0, "SERVER_WRITE_TIMEOUT must be positive")
+	v.check(c.Server.IdleTimeout > 0, "SERVER_IDLE_TIMEOUT must be positive")
+	v.check(c.Server.MaxHeaderBytes > 0, "SERVER_MAX_HEADER_BYTES must be positive")
+	v.check(c.Server.ShutdownDelay >= 0, "SERVER_SHUTDOWN_DELAY must not be negative")
+	v.check(c.Server.ShutdownTimeout > 0, "SERVER_SHUTDOWN_TIMEOUT must be positive")
+
+	maxBodySize, err := bytes.Parse(c.Server.MaxBodySize)
+	v.check(err == nil && maxBodySize > 0, "SERVER_MAX_BODY_SIZE must be a size like 512K or 1M, got %q", c.Server.MaxBodySize)
+
+	v.check(c.Request.Timeout > 0, "REQUEST_TIMEOUT must be positive")
+
+	for route, timeout := range c.Request.RouteTimeouts {
+		v.check(len(strings.Fields(route)) == 2, "REQUEST_ROUTE_TIMEOUTS route %q must be \"METHOD /path\"", route)
+		v.check(timeout > 0, "REQUEST_ROUTE_TIMEOUTS timeout of %q must be positive", route)
+	}
+
+	v.check(isOneOf(c.Repository.Driver, consts.RepositoryDriverPostgres, consts.RepositoryDriverSqlite, consts.RepositoryDriverMemory),
+		"REPOSITORY_DRIVER must be %s, %s or %s, got %q", consts.RepositoryDriverPostgres, consts.RepositoryDriverSqlite,
+		consts.RepositoryDriverMemory, c.Repository.Driver)
+	v.check(len(c.Database.ReplicaUrls) == 0 || c.Repository.Driver == consts.RepositoryDriverPostgres,
+		"DATABASE_REPLICA_URLS are only supported by the %s repository", consts.RepositoryDriverPostgres)
+
+	// 0 connections means no limit, like for database/sql.
+	v.check(c.Database.MaxOpenConns >= 0, "DATABASE_MAX_OPEN_CONNS must not be negative")
+	v.check(c.Database.MaxIdleConns >= 0, "DATABASE_MAX_IDLE_CONNS must not be negative")
+	v.check(c.Database.ConnMaxLifetime >= 0, "DATABASE_CONN_MAX_LIFETIME must not be negative")
+	v.check(c.Database.ConnMaxIdleTime >= 0, "DATABASE_CONN_MAX_IDLE_TIME must not be negative")
+	v.check(c.Database.ConnectTimeout > 0, "DATABASE_CONNECT_TIMEOUT must be positive")
+	v.check(c.Database.RetryMaxAttempts >= 1, "DATABASE_RETRY_MAX_ATTEMPTS must be at least 1")
+	v.check(c.Database.RetryInitialBackoff > 0, "DATABASE_RETRY_INITIAL_BACKOFF must be positive")
+	v.check(c.Database.RetryMaxBackoff >= c.Database.RetryInitialBackoff,
+		"DATABASE_RETRY_MAX_BACKOFF must not be below DATABASE_RETRY_INITIAL_BACKOFF")
+	v.check(c.Database.CircuitBreakerFailureThreshold >= 1, "DATABASE_CIRCUIT_BREAKER_FAILURE_THRESHOLD must be at least 1")
+	v.check(c.Database.CircuitBreakerOpenDuration > 0, "DATABASE_CIRCUIT_BREAKER_OPEN_DURATION must be positive")
+
+	v.check(c.Repository.Driver != consts.RepositoryDriverSqlite || c.Sqlite.Path != "", "SQLITE_PATH must not be empty")
+	v.check(c.Sqlite.BusyTimeout >= 0, "SQLITE_BUSY_TIMEOUT must not be negative")
+
+	v.check(c.UserCache.Size >= 0, "USER_CACHE_SIZE must not be negative")
+	v.check(c.UserCache.TTL > 0, "USER_CACHE_TTL must be positive")
+
+	v.check(isOneOf(c.Mail.Driver, consts.MailDriverSmtp, consts.MailDriverFile),
+		"MAIL_DRIVER must be %s or %s, got %q", consts.MailDriverSmtp, consts.MailDriverFile, c.Mail.Driver)
+	v.check(c.Mail.Driver != consts.MailDriverFile || c.Mail.FileDirectory != "",
+		"MAIL_FILE_DIRECTORY must be set when MAIL_DRIVER is %s", consts.MailDriverFile)
+
+	v.check(c.Account.DeletionGracePeriod > 0, "ACCOUNT_DELETION_GRACE_PERIOD must be positive")
+	v.check(c.Account.DeletionJobInterval > 0, "ACCOUNT_DELETION_JOB_INTERVAL must be positive")
+	v.check(c.Account.EmailVerificationUrl != "", "EMAIL_VERIFICATION_URL must not be empty")
+
+	v.check(c.DataExport.Directory != "", "DATA_EXPORT_DIRECTORY must not be empty")
+	v.check(c.DataExport.DownloadUrl != "", "DATA_EXPORT_DOWNLOAD_URL must not be empty")
+	v.check(c.DataExport.CleanupInterval > 0, "DATA_EXPORT_CLEANUP_INTERVAL must be positive")
+
+	v.check(isOneOf(c.Tracing.Exporter, consts.TracingExporterNone, consts.TracingExporterOtlp, consts.TracingExporterStdout),
+		"TRACING_EXPORTER must be %s, %s or %s, got %q", consts.TracingExporterNone, consts.TracingExporterOtlp,
+		consts.TracingExporterStdout, c.Tracing.Exporter)
+
+	v.check(c.Health.CheckTimeout > 0, "HEALTH_CHECK_TIMEOUT must be positive")
+
+	v.check(c.Reload.WatchInterval >= 0, "RELOAD_WATCH_INTERVAL must not be negative")
+
+	return errors.Join(v.errs...)
+}
@@ -0,0 +1,15 @@
+package consts
+
+import "time"
+
+const (
+	DefaultAccountDeletionGracePeriod = 30 * 24 * time.Hour
+	DefaultAccountDeletionJobInterval = time.Hour
+	AccountDeletionBatchSize          = 100
+)
+
+const (
+	AccountDeletionRequestReason = "Deletion requested by the user"
+	AccountDeletionDoneReason    = "Deleted on request of the user"
+	AnonymizedUserFullName       = "Deleted User"
+)
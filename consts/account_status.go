@@ -0,0 +1,21 @@
+package consts
+
+const (
+	AccountStatusActive    = "active"
+	AccountStatusSuspended = "suspended"
+	AccountStatusLocked    = "locked"
+	// AccountStatusPendingDeletion is set by the user's own deletion request, see UserService.RequestDeletion.
+	AccountStatusPendingDeletion = "pending_deletion"
+	AccountStatusDeleted         = "deleted"
+)
+
+// AccountStatusTransitions lists, per status, the statuses an admin can move an account to. Deleted is final.
+// Pending deletion is left to the user's own request: a login cancels it and the deletion job deletes the account once
+// the grace period is over.
+var AccountStatusTransitions = map[string][]string{
+	AccountStatusActive:          {AccountStatusSuspended, AccountStatusLocked, AccountStatusDeleted},
+	AccountStatusSuspended:       {AccountStatusActive, AccountStatusDeleted},
+	AccountStatusLocked:          {AccountStatusActive, AccountStatusSuspended, AccountStatusDeleted},
+	AccountStatusPendingDeletion: {},
+	AccountStatusDeleted:         {},
+}
@@ -0,0 +1,24 @@
+package consts
+
+const (
+	AuditActionRegister        = "user.register"
+	AuditActionLoginSuccess    = "user.login.success"
+	AuditActionLoginFailure    = "user.login.failure"
+	AuditActionProfileUpdate   = "user.profile.update"
+	AuditActionEmailChange     = "user.email.change"
+	AuditActionEmailVerify     = "user.email.verify"
+	AuditActionRoleChange      = "user.role.change"
+	AuditActionStatusChange    = "user.status.change"
+	AuditActionDeletionRequest = "user.deletion.request"
+	AuditActionDeletionCancel  = "user.deletion.cancel"
+	AuditActionDelete          = "user.delete"
+	AuditActionDataExport      = "user.data.export"
+)
+
+// AuditRedactedValue stands for the values of the personal fields in the hash chained changes of an audit log entry.
+const AuditRedactedValue = "[REDACTED]"
+
+const (
+	DefaultAuditLogPageSize = 20
+	AuditLogVerifyBatchSize = 500
+)
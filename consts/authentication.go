@@ -1,5 +1,22 @@
 package consts
 
+import "time"
+
 const (
 	ContextAuthorizedUsedId = "authorized-user-id"
 )
+
+const (
+	DefaultApplicationName         = "user-service"
+	DefaultLoginExpirationDuration = 24 * time.Hour
+	DefaultJwtPrivateKeyPath       = "cert/id_rsa"
+	DefaultJwtPublicKeyPath        = "cert/id_rsa.pub"
+)
+
+// In JWT_KEY_DIRECTORY the tokens are signed with the private key in the file JwtKeyDirectorySigningKeyFile and
+// verified with the public keys in the files matching JwtKeyDirectoryPublicKeyPattern, the current one and those of
+// the previous signing keys.
+const (
+	JwtKeyDirectorySigningKeyFile   = "id_rsa"
+	JwtKeyDirectoryPublicKeyPattern = "*.pub"
+)
@@ -0,0 +1,29 @@
+package consts
+
+import "time"
+
+const (
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusCompleted  = "completed"
+	DataExportStatusFailed     = "failed"
+)
+
+const (
+	DataExportTokenByteLength      = 32
+	DataExportExpiration           = 72 * time.Hour
+	DataExportPageSize             = 100
+	DefaultDataExportDownloadUrl   = "http://localhost:8080/users/export/download"
+	DefaultDataExportDirectory     = "exports"
+	DataExportContentType          = "application/zip"
+	DataExportEncryptedContentType = "application/octet-stream"
+)
+
+const (
+	DefaultDataExportCleanupInterval = time.Hour
+	DataExportCleanupBatchSize       = 100
+	// DataExportBuildTimeout is how long after its request an export that is not built yet is given up.
+	DataExportBuildTimeout = 15 * time.Minute
+	// DataExportMaxConcurrentBuilds is how many archives an instance builds at once, the other exports wait pending.
+	DataExportMaxConcurrentBuilds = 4
+)
@@ -0,0 +1,32 @@
+package consts
+
+import "time"
+
+const (
+	DefaultDatabaseMaxOpenConns    = 25
+	DefaultDatabaseMaxIdleConns    = 25
+	DefaultDatabaseConnMaxLifetime = 30 * time.Minute
+	DefaultDatabaseConnMaxIdleTime = 5 * time.Minute
+	DefaultDatabaseConnectTimeout  = time.Minute
+)
+
+const (
+	DefaultDatabaseRetryMaxAttempts               = 3
+	DefaultDatabaseRetryInitialBackoff            = 50 * time.Millisecond
+	DefaultDatabaseRetryMaxBackoff                = time.Second
+	DefaultDatabaseCircuitBreakerFailureThreshold = 5
+	DefaultDatabaseCircuitBreakerOpenDuration     = 10 * time.Second
+)
+
+const (
+	RepositoryDriverPostgres = "postgres"
+	RepositoryDriverMemory   = "memory"
+	RepositoryDriverSqlite   = "sqlite"
+)
+
+const DefaultUserCacheTTL = time.Minute
+
+const (
+	DefaultSqlitePath        = "user_service.db"
+	DefaultSqliteBusyTimeout = 5 * time.Second
+)
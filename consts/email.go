@@ -0,0 +1,14 @@
+package consts
+
+import "time"
+
+const (
+	EmailVerificationTokenByteLength = 32
+	EmailVerificationExpiration      = 24 * time.Hour
+	DefaultEmailVerificationUrl      = "http://localhost:8080/users/email/verify"
+)
+
+const (
+	MailDriverSmtp = "smtp"
+	MailDriverFile = "file"
+)
@@ -0,0 +1,17 @@
+package consts
+
+import "time"
+
+const (
+	HealthStatusOk   = "ok"
+	HealthStatusFail = "fail"
+)
+
+const (
+	HealthCheckDatabase    = "database"
+	HealthCheckMigrations  = "migrations"
+	HealthCheckKeyMaterial = "key_material"
+	HealthCheckShutdown    = "shutdown"
+)
+
+const DefaultHealthCheckTimeout = 2 * time.Second
@@ -0,0 +1,24 @@
+package consts
+
+import "time"
+
+const (
+	// StatusClientClosedRequest is the non-standard status (from nginx) for requests the client gave up on.
+	StatusClientClosedRequest = 499
+)
+
+const (
+	DefaultRequestTimeout = 10 * time.Second
+)
+
+const (
+	DefaultServerAddress           = ":1323"
+	DefaultServerReadHeaderTimeout = 5 * time.Second
+	DefaultServerReadTimeout       = 30 * time.Second
+	// DefaultServerWriteTimeout is longer than the request timeouts, a timed out request still gets its 503.
+	DefaultServerWriteTimeout    = time.Minute
+	DefaultServerIdleTimeout     = 2 * time.Minute
+	DefaultServerMaxHeaderBytes  = 64 << 10
+	DefaultServerMaxBodySize     = "1M"
+	DefaultServerShutdownTimeout = 30 * time.Second
+)
@@ -0,0 +1,17 @@
+package consts
+
+const (
+	LoginMethodPhoneNumber = "phone_number"
+	LoginMethodEmail       = "email"
+)
+
+const (
+	LoginFailureReasonUserNotFound    = "user_not_found"
+	LoginFailureReasonInvalidPassword = "invalid_password"
+	LoginFailureReasonAccountInactive = "account_inactive"
+)
+
+const (
+	DefaultLoginHistoryPageSize = 20
+	MaxLoginHistoryPageSize     = 100
+)
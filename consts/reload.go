@@ -0,0 +1,7 @@
+package consts
+
+import "time"
+
+const (
+	DefaultReloadWatchInterval = 30 * time.Second
+)
@@ -0,0 +1,6 @@
+package consts
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
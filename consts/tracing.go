@@ -0,0 +1,14 @@
+package consts
+
+import "time"
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOtlp   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+const (
+	DefaultTracingServiceName     = "user-service"
+	DefaultTracingShutdownTimeout = 5 * time.Second
+)
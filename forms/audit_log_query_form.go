@@ -0,0 +1,54 @@
+package forms
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+type AuditLogQueryForm struct {
+	ActorUserId   *int64 `form:"actor_user_id" json:"actor_user_id" query:"actor_user_id"`
+	SubjectUserId *int64 `form:"subject_user_id" json:"subject_user_id" query:"subject_user_id"`
+	Action        string `form:"action" json:"action" query:"action"`
+	Page          int    `form:"page" json:"page" query:"page" validate:"min=1"`
+	PageSize      int    `form:"page_size" json:"page_size" query:"page_size" validate:"min=1,max=100"`
+}
+
+func (a AuditLogQueryForm) GetFormField(fieldError validator.FieldError) string {
+
+	switch fieldError.Field() {
+
+	case "Page":
+		return "page"
+	case "PageSize":
+		return "page_size"
+	}
+
+	return "unknown"
+}
+
+func (a AuditLogQueryForm) TranslateField(field string) string {
+
+	switch field {
+
+	case "Page":
+		return "Page"
+	case "PageSize":
+		return "Page size"
+	}
+
+	return "unknown"
+}
+
+func (a AuditLogQueryForm) GetErrorMessage(fieldError validator.FieldError) string {
+
+	translatedField := a.TranslateField(fieldError.Field())
+
+	switch fieldError.Tag() {
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", translatedField, fieldError.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", translatedField, fieldError.Param())
+	}
+
+	return "unknown error"
+}
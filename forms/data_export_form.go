@@ -0,0 +1,47 @@
+package forms
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+// DataExportForm optionally carries a password the export archive gets encrypted with.
+type DataExportForm struct {
+	Password string `form:"password" json:"password" validate:"omitempty,min=8,max=72"`
+}
+
+func (d DataExportForm) GetFormField(fieldError validator.FieldError) string {
+
+	switch fieldError.Field() {
+
+	case "Password":
+		return "password"
+	}
+
+	return "unknown"
+}
+
+func (d DataExportForm) TranslateField(field string) string {
+
+	switch field {
+
+	case "Password":
+		return "Password"
+	}
+
+	return "unknown"
+}
+
+func (d DataExportForm) GetErrorMessage(fieldError validator.FieldError) string {
+
+	translatedField := d.TranslateField(fieldError.Field())
+
+	switch fieldError.Tag() {
+	case "min":
+		return fmt.Sprintf("%s must have minimum %s characters long", translatedField, fieldError.Param())
+	case "max":
+		return fmt.Sprintf("%s must have maximum %s characters long", translatedField, fieldError.Param())
+	}
+
+	return "unknown error"
+}
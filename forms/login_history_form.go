@@ -0,0 +1,51 @@
+package forms
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+type LoginHistoryForm struct {
+	Page     int `form:"page" json:"page" query:"page" validate:"min=1"`
+	PageSize int `form:"page_size" json:"page_size" query:"page_size" validate:"min=1,max=100"`
+}
+
+func (l LoginHistoryForm) GetFormField(fieldError validator.FieldError) string {
+
+	switch fieldError.Field() {
+
+	case "Page":
+		return "page"
+	case "PageSize":
+		return "page_size"
+	}
+
+	return "unknown"
+}
+
+func (l LoginHistoryForm) TranslateField(field string) string {
+
+	switch field {
+
+	case "Page":
+		return "Page"
+	case "PageSize":
+		return "Page size"
+	}
+
+	return "unknown"
+}
+
+func (l LoginHistoryForm) GetErrorMessage(fieldError validator.FieldError) string {
+
+	translatedField := l.TranslateField(fieldError.Field())
+
+	switch fieldError.Tag() {
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", translatedField, fieldError.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", translatedField, fieldError.Param())
+	}
+
+	return "unknown error"
+}
@@ -0,0 +1,48 @@
+package forms
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+type UserChangeEmailForm struct {
+	Email string `form:"email" json:"email" validate:"required,email,max=255"`
+}
+
+func (u UserChangeEmailForm) GetFormField(fieldError validator.FieldError) string {
+
+	switch fieldError.Field() {
+
+	case "Email":
+		return "email"
+	}
+
+	return "unknown"
+}
+
+func (u UserChangeEmailForm) TranslateField(field string) string {
+
+	switch field {
+
+	case "Email":
+		return "Email"
+	}
+
+	return "unknown"
+}
+
+func (u UserChangeEmailForm) GetErrorMessage(fieldError validator.FieldError) string {
+
+	translatedField := u.TranslateField(fieldError.Field())
+
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", translatedField)
+	case "max":
+		return fmt.Sprintf("%s must have maximum %s characters long", translatedField, fieldError.Param())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", translatedField)
+	}
+
+	return "unknown error"
+}
@@ -0,0 +1,46 @@
+package forms
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+type UserChangeRoleForm struct {
+	Role string `form:"role" json:"role" validate:"required,oneof=user admin"`
+}
+
+func (u UserChangeRoleForm) GetFormField(fieldError validator.FieldError) string {
+
+	switch fieldError.Field() {
+
+	case "Role":
+		return "role"
+	}
+
+	return "unknown"
+}
+
+func (u UserChangeRoleForm) TranslateField(field string) string {
+
+	switch field {
+
+	case "Role":
+		return "Role"
+	}
+
+	return "unknown"
+}
+
+func (u UserChangeRoleForm) GetErrorMessage(fieldError validator.FieldError) string {
+
+	translatedField := u.TranslateField(fieldError.Field())
+
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", translatedField)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", translatedField, fieldError.Param())
+	}
+
+	return "unknown error"
+}
@@ -0,0 +1,53 @@
+package forms
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+type UserChangeStatusForm struct {
+	Status string `form:"status" json:"status" validate:"required,oneof=active suspended locked deleted"`
+	Reason string `form:"reason" json:"reason" validate:"required,max=255"`
+}
+
+func (u UserChangeStatusForm) GetFormField(fieldError validator.FieldError) string {
+
+	switch fieldError.Field() {
+
+	case "Status":
+		return "status"
+	case "Reason":
+		return "reason"
+	}
+
+	return "unknown"
+}
+
+func (u UserChangeStatusForm) TranslateField(field string) string {
+
+	switch field {
+
+	case "Status":
+		return "Status"
+	case "Reason":
+		return "Reason"
+	}
+
+	return "unknown"
+}
+
+func (u UserChangeStatusForm) GetErrorMessage(fieldError validator.FieldError) string {
+
+	translatedField := u.TranslateField(fieldError.Field())
+
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", translatedField)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", translatedField, fieldError.Param())
+	case "max":
+		return fmt.Sprintf("%s must have maximum %s characters long", translatedField, fieldError.Param())
+	}
+
+	return "unknown error"
+}
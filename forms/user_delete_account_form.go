@@ -0,0 +1,44 @@
+package forms
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+type UserDeleteAccountForm struct {
+	Password string `form:"password" json:"password" validate:"required"`
+}
+
+func (u UserDeleteAccountForm) GetFormField(fieldError validator.FieldError) string {
+
+	switch fieldError.Field() {
+
+	case "Password":
+		return "password"
+	}
+
+	return "unknown"
+}
+
+func (u UserDeleteAccountForm) TranslateField(field string) string {
+
+	switch field {
+
+	case "Password":
+		return "Password"
+	}
+
+	return "unknown"
+}
+
+func (u UserDeleteAccountForm) GetErrorMessage(fieldError validator.FieldError) string {
+
+	translatedField := u.TranslateField(fieldError.Field())
+
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", translatedField)
+	}
+
+	return "unknown error"
+}
@@ -3,10 +3,13 @@ package forms
 import (
 	"fmt"
 	"github.com/go-playground/validator/v10"
+	"strings"
 )
 
+// UserLoginForm identifies the user by phone number or, when given, by a verified email.
 type UserLoginForm struct {
-	PhoneNumber string `form:"phone_number" json:"phone_number" validate:"required"`
+	PhoneNumber string `form:"phone_number" json:"phone_number" validate:"required_without=Email"`
+	Email       string `form:"email" json:"email" validate:"omitempty,email"`
 	Password    string `form:"password" json:"password" validate:"required"`
 }
 
@@ -16,6 +19,8 @@ func (u UserLoginForm) GetFormField(fieldError validator.FieldError) string {
 
 	case "PhoneNumber":
 		return "phone_number"
+	case "Email":
+		return "email"
 	case "Password":
 		return "password"
 	}
@@ -29,6 +34,8 @@ func (u UserLoginForm) TranslateField(field string) string {
 
 	case "PhoneNumber":
 		return "Phone number"
+	case "Email":
+		return "Email"
 	case "Password":
 		return "Password"
 	}
@@ -43,6 +50,10 @@ func (u UserLoginForm) GetErrorMessage(fieldError validator.FieldError) string {
 	switch fieldError.Tag() {
 	case "required":
 		return fmt.Sprintf("%s is required", translatedField)
+	case "required_without":
+		return fmt.Sprintf("%s is required when %s is empty", translatedField, strings.ToLower(u.TranslateField(fieldError.Param())))
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", translatedField)
 	}
 
 	return "unknown error"
@@ -9,6 +9,7 @@ import (
 type UserRegisterForm struct {
 	PhoneNumber string `form:"phone_number" json:"phone_number" validate:"required,min=10,max=13,startswith=+62"`
 	FullName    string `form:"full_name" json:"full_name" validate:"required,min=3,max=60"`
+	Email       string `form:"email" json:"email" validate:"omitempty,email,max=255"`
 	Password    string `form:"password" json:"password" validate:"required,min=6,max=64,atl_x_capital_char=1,atl_x_special_char=1"`
 }
 
@@ -20,6 +21,8 @@ func (c UserRegisterForm) GetFormField(fieldError validator.FieldError) string {
 		return "phone_number"
 	case "FullName":
 		return "full_name"
+	case "Email":
+		return "email"
 	case "Password":
 		return "password"
 	}
@@ -35,6 +38,8 @@ func (c UserRegisterForm) TranslateField(field string) string {
 		return "Phone number"
 	case "FullName":
 		return "Full name"
+	case "Email":
+		return "Email"
 	case "Password":
 		return "Password"
 	}
@@ -55,6 +60,8 @@ func (c UserRegisterForm) GetErrorMessage(fieldError validator.FieldError) strin
 		return fmt.Sprintf("%s must have maximum %s characters long", translatedField, fieldError.Param())
 	case "startswith":
 		return fmt.Sprintf("%s must starts with %s", translatedField, fieldError.Param())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", translatedField)
 	case validators.AtLeastXCapitalCharValidationTag:
 		return fmt.Sprintf("%s must contains at least %s captial characters", translatedField, fieldError.Param())
 	case validators.AtLeastXSpecialCharValidationTag:
@@ -0,0 +1,44 @@
+package forms
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+type VerifyEmailForm struct {
+	Token string `form:"token" json:"token" query:"token" validate:"required"`
+}
+
+func (v VerifyEmailForm) GetFormField(fieldError validator.FieldError) string {
+
+	switch fieldError.Field() {
+
+	case "Token":
+		return "token"
+	}
+
+	return "unknown"
+}
+
+func (v VerifyEmailForm) TranslateField(field string) string {
+
+	switch field {
+
+	case "Token":
+		return "Verification token"
+	}
+
+	return "unknown"
+}
+
+func (v VerifyEmailForm) GetErrorMessage(fieldError validator.FieldError) string {
+
+	translatedField := v.TranslateField(fieldError.Field())
+
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", translatedField)
+	}
+
+	return "unknown error"
+}
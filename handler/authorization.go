@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/labstack/echo/v4"
+)
+
+// isAuthorizedAdmin reports whether the user of the verified token currently has the admin role.
+// The role is read from the database on every call so revoking it takes effect immediately.
+func (s *Server) isAuthorizedAdmin(ctx echo.Context) (bool, error) {
+
+	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
+
+	user, err := s.userService.GetById(ctx.Request().Context(), authorizedUserId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return user != nil && user.Role == consts.RoleAdmin, nil
+}
@@ -1,10 +1,11 @@
 package handler
 
 import (
+	"fmt"
 	"github.com/SawitProRecruitment/UserService/consts"
 	"github.com/SawitProRecruitment/UserService/forms"
+	"github.com/SawitProRecruitment/UserService/generated"
 	"github.com/SawitProRecruitment/UserService/responses"
-	"github.com/SawitProRecruitment/UserService/utils"
 	"github.com/labstack/echo/v4"
 	"net/http"
 )
@@ -20,19 +21,54 @@ func (s *Server) Register(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, "Bad Request")
 	}
 
-	registerResult, err := s.userService.Register(userRegisterForm)
+	registerResult, err := s.userService.Register(ctx.Request().Context(), userRegisterForm, buildRequestMetadata(ctx))
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, "Internal Server Error")
+		return responses.RespondError(ctx, err)
 	}
 
 	if registerResult.HasValidationErrors {
 		return ctx.JSON(http.StatusBadRequest, registerResult.ValidationErrors)
 	}
 
+	if registerResult.IsPhoneNumberConflict {
+		return respondPhoneNumberConflict(ctx, userRegisterForm.PhoneNumber)
+	}
+
 	return ctx.JSON(http.StatusOK, registerResult.User)
 }
 
+// Delete my account
+// (DELETE /users/me)
+func (s *Server) DeleteMyAccount(ctx echo.Context) error {
+
+	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
+
+	var userDeleteAccountForm forms.UserDeleteAccountForm
+
+	if err := ctx.Bind(&userDeleteAccountForm); err != nil {
+		return ctx.JSON(http.StatusBadRequest, "Bad Request")
+	}
+
+	deleteAccountResult, err := s.userService.RequestDeletion(ctx.Request().Context(), authorizedUserId, userDeleteAccountForm, buildRequestMetadata(ctx))
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if deleteAccountResult.HasValidationErrors {
+		return ctx.JSON(http.StatusBadRequest, deleteAccountResult.ValidationErrors)
+	}
+
+	if deleteAccountResult.IsInvalidPassword {
+		return ctx.JSON(http.StatusForbidden, responses.BadRequestResponse{
+			ErrorMessage: "Your password is incorrect",
+		})
+	}
+
+	return ctx.JSON(http.StatusAccepted, deleteAccountResult.User)
+}
+
 // Update user profile
 // (PUT /users)
 func (s *Server) UpdateUser(ctx echo.Context) error {
@@ -45,30 +81,20 @@ func (s *Server) UpdateUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, "Bad Request")
 	}
 
-	if utils.StringIsEmpty(updateUserForm.PhoneNumber) == false {
-		user, err := s.userService.GetByPhoneNumber(updateUserForm.PhoneNumber)
-
-		if err != nil {
-			return ctx.JSON(http.StatusInternalServerError, "Internal Server Error")
-		}
-
-		if user != nil {
-			return ctx.JSON(http.StatusConflict, responses.BadRequestResponse{
-				ErrorMessage: "Conflicted",
-			})
-		}
-	}
-
-	updateResult, err := s.userService.Update(authorizedUserId, updateUserForm)
+	updateResult, err := s.userService.Update(ctx.Request().Context(), authorizedUserId, updateUserForm, buildRequestMetadata(ctx))
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, "Internal Server Error")
+		return responses.RespondError(ctx, err)
 	}
 
 	if updateResult.HasValidationErrors {
 		return ctx.JSON(http.StatusBadRequest, updateResult.ValidationErrors)
 	}
 
+	if updateResult.IsPhoneNumberConflict {
+		return respondPhoneNumberConflict(ctx, updateUserForm.PhoneNumber)
+	}
+
 	return ctx.JSON(http.StatusOK, updateResult.User)
 }
 
@@ -82,16 +108,22 @@ func (s *Server) Login(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, "Bad Request")
 	}
 
-	authenticationResult, err := s.authenticationService.Authenticate(userLoginForm)
+	authenticationResult, err := s.authenticationService.Authenticate(ctx.Request().Context(), userLoginForm, buildRequestMetadata(ctx))
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, "Internal Server Error")
+		return responses.RespondError(ctx, err)
 	}
 
 	if authenticationResult.HasValidationErrors {
 		return ctx.JSON(http.StatusBadRequest, authenticationResult.ValidationErrors)
 	}
 
+	if authenticationResult.IsAccountInactive {
+		return ctx.JSON(http.StatusForbidden, responses.BadRequestResponse{
+			ErrorMessage: fmt.Sprintf("Login failed. Your account is %s.", authenticationResult.AccountStatus),
+		})
+	}
+
 	if authenticationResult.IsUserNotFound || authenticationResult.IsSuccess == false {
 		badRequestResponse := responses.BadRequestResponse{
 			ErrorMessage: "Login failed. Please enter correct phone number and password.",
@@ -108,10 +140,10 @@ func (s *Server) GetMyProfile(ctx echo.Context) error {
 
 	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
 
-	user, err := s.userService.GetById(authorizedUserId)
+	user, err := s.userService.GetById(ctx.Request().Context(), authorizedUserId)
 
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, "Internal Server Error")
+		return responses.RespondError(ctx, err)
 	}
 
 	if user == nil {
@@ -120,3 +152,345 @@ func (s *Server) GetMyProfile(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, user)
 }
+
+// Change email
+// (PUT /users/me/email)
+func (s *Server) ChangeMyEmail(ctx echo.Context) error {
+
+	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
+
+	var userChangeEmailForm forms.UserChangeEmailForm
+
+	if err := ctx.Bind(&userChangeEmailForm); err != nil {
+		return ctx.JSON(http.StatusBadRequest, "Bad Request")
+	}
+
+	changeEmailResult, err := s.userService.ChangeEmail(ctx.Request().Context(), authorizedUserId, userChangeEmailForm, buildRequestMetadata(ctx))
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if changeEmailResult.HasValidationErrors {
+		return ctx.JSON(http.StatusBadRequest, changeEmailResult.ValidationErrors)
+	}
+
+	return ctx.JSON(http.StatusOK, changeEmailResult.User)
+}
+
+// Verify email
+// (POST /users/email/verify)
+func (s *Server) VerifyEmail(ctx echo.Context) error {
+
+	var verifyEmailForm forms.VerifyEmailForm
+
+	if err := ctx.Bind(&verifyEmailForm); err != nil {
+		return ctx.JSON(http.StatusBadRequest, "Bad Request")
+	}
+
+	verifyEmailResult, err := s.userService.VerifyEmail(ctx.Request().Context(), verifyEmailForm, buildRequestMetadata(ctx))
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if verifyEmailResult.HasValidationErrors {
+		return ctx.JSON(http.StatusBadRequest, verifyEmailResult.ValidationErrors)
+	}
+
+	return ctx.JSON(http.StatusOK, responses.VerifyEmailResponse{
+		IsVerified: verifyEmailResult.IsVerified,
+	})
+}
+
+// Get login history
+// (GET /users/me/logins)
+func (s *Server) GetMyLoginHistory(ctx echo.Context, params generated.GetMyLoginHistoryParams) error {
+
+	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
+
+	loginHistoryForm := forms.LoginHistoryForm{
+		Page:     1,
+		PageSize: consts.DefaultLoginHistoryPageSize,
+	}
+
+	if params.Page != nil {
+		loginHistoryForm.Page = *params.Page
+	}
+
+	if params.PageSize != nil {
+		loginHistoryForm.PageSize = *params.PageSize
+	}
+
+	loginHistoryResult, err := s.authenticationService.GetLoginHistory(ctx.Request().Context(), authorizedUserId, loginHistoryForm)
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if loginHistoryResult.HasValidationErrors {
+		return ctx.JSON(http.StatusBadRequest, loginHistoryResult.ValidationErrors)
+	}
+
+	return ctx.JSON(http.StatusOK, loginHistoryResult.LoginHistory)
+}
+
+// Export my personal data
+// (POST /users/me/export)
+func (s *Server) RequestMyDataExport(ctx echo.Context) error {
+
+	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
+
+	var dataExportForm forms.DataExportForm
+
+	if err := ctx.Bind(&dataExportForm); err != nil {
+		return ctx.JSON(http.StatusBadRequest, "Bad Request")
+	}
+
+	dataExportResult, err := s.dataExportService.RequestExport(ctx.Request().Context(), authorizedUserId, dataExportForm, buildRequestMetadata(ctx))
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if dataExportResult.HasValidationErrors {
+		return ctx.JSON(http.StatusBadRequest, dataExportResult.ValidationErrors)
+	}
+
+	if dataExportResult.IsExportInProgress {
+		return ctx.JSON(http.StatusConflict, responses.BadRequestResponse{
+			ErrorMessage: "Your previous data export is still being generated",
+		})
+	}
+
+	return ctx.JSON(http.StatusAccepted, dataExportResult.DataExport)
+}
+
+// Get the status of my data export
+// (GET /users/me/export/{id})
+func (s *Server) GetMyDataExport(ctx echo.Context, id int64) error {
+
+	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
+
+	dataExport, err := s.dataExportService.GetExport(ctx.Request().Context(), authorizedUserId, id)
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if dataExport == nil {
+		return ctx.JSON(http.StatusNotFound, "Data export not found")
+	}
+
+	return ctx.JSON(http.StatusOK, dataExport)
+}
+
+// Download a data export
+// (GET /users/export/download)
+func (s *Server) DownloadDataExport(ctx echo.Context, params generated.DownloadDataExportParams) error {
+
+	archiveResult, err := s.dataExportService.GetExportArchive(ctx.Request().Context(), params.Token)
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if archiveResult.IsNotFound {
+		return ctx.JSON(http.StatusNotFound, "Data export not found")
+	}
+
+	if archiveResult.IsExpired {
+		return ctx.JSON(http.StatusGone, responses.BadRequestResponse{
+			ErrorMessage: "The download url has expired, please request a new export",
+		})
+	}
+
+	if archiveResult.IsNotReady {
+		return ctx.JSON(http.StatusConflict, responses.BadRequestResponse{
+			ErrorMessage: "Data export is not ready yet",
+		})
+	}
+
+	contentType := consts.DataExportContentType
+
+	if archiveResult.IsEncrypted {
+		contentType = consts.DataExportEncryptedContentType
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", archiveResult.FileName))
+
+	return ctx.Blob(http.StatusOK, contentType, archiveResult.Content)
+}
+
+// Query the audit log
+// (GET /admin/audit-logs)
+func (s *Server) GetAuditLogs(ctx echo.Context, params generated.GetAuditLogsParams) error {
+
+	isAdmin, err := s.isAuthorizedAdmin(ctx)
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if isAdmin == false {
+		return ctx.JSON(http.StatusForbidden, responses.BadRequestResponse{
+			ErrorMessage: "Your request is made without admin privilege",
+		})
+	}
+
+	auditLogQueryForm := forms.AuditLogQueryForm{
+		ActorUserId:   params.ActorUserId,
+		SubjectUserId: params.SubjectUserId,
+		Page:          1,
+		PageSize:      consts.DefaultAuditLogPageSize,
+	}
+
+	if params.Action != nil {
+		auditLogQueryForm.Action = *params.Action
+	}
+
+	if params.Page != nil {
+		auditLogQueryForm.Page = *params.Page
+	}
+
+	if params.PageSize != nil {
+		auditLogQueryForm.PageSize = *params.PageSize
+	}
+
+	auditLogsResult, err := s.auditService.GetAuditLogs(ctx.Request().Context(), auditLogQueryForm)
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if auditLogsResult.HasValidationErrors {
+		return ctx.JSON(http.StatusBadRequest, auditLogsResult.ValidationErrors)
+	}
+
+	return ctx.JSON(http.StatusOK, auditLogsResult.AuditLogs)
+}
+
+// Change a user's role
+// (PUT /admin/users/{id}/role)
+func (s *Server) ChangeUserRole(ctx echo.Context, id int64) error {
+
+	isAdmin, err := s.isAuthorizedAdmin(ctx)
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if isAdmin == false {
+		return ctx.JSON(http.StatusForbidden, responses.BadRequestResponse{
+			ErrorMessage: "Your request is made without admin privilege",
+		})
+	}
+
+	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
+
+	var userChangeRoleForm forms.UserChangeRoleForm
+
+	if err := ctx.Bind(&userChangeRoleForm); err != nil {
+		return ctx.JSON(http.StatusBadRequest, "Bad Request")
+	}
+
+	changeRoleResult, err := s.userService.ChangeRole(ctx.Request().Context(), authorizedUserId, id, userChangeRoleForm, buildRequestMetadata(ctx))
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if changeRoleResult.HasValidationErrors {
+		return ctx.JSON(http.StatusBadRequest, changeRoleResult.ValidationErrors)
+	}
+
+	if changeRoleResult.IsUserNotFound {
+		return ctx.JSON(http.StatusNotFound, "User not found")
+	}
+
+	return ctx.JSON(http.StatusOK, changeRoleResult.User)
+}
+
+// Change a user's account status
+// (PUT /admin/users/{id}/status)
+func (s *Server) ChangeUserStatus(ctx echo.Context, id int64) error {
+
+	isAdmin, err := s.isAuthorizedAdmin(ctx)
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if isAdmin == false {
+		return ctx.JSON(http.StatusForbidden, responses.BadRequestResponse{
+			ErrorMessage: "Your request is made without admin privilege",
+		})
+	}
+
+	authorizedUserId := ctx.Get(consts.ContextAuthorizedUsedId).(int64)
+
+	var userChangeStatusForm forms.UserChangeStatusForm
+
+	if err := ctx.Bind(&userChangeStatusForm); err != nil {
+		return ctx.JSON(http.StatusBadRequest, "Bad Request")
+	}
+
+	changeStatusResult, err := s.userService.ChangeStatus(ctx.Request().Context(), authorizedUserId, id, userChangeStatusForm, buildRequestMetadata(ctx))
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if changeStatusResult.HasValidationErrors {
+		return ctx.JSON(http.StatusBadRequest, changeStatusResult.ValidationErrors)
+	}
+
+	if changeStatusResult.IsUserNotFound {
+		return ctx.JSON(http.StatusNotFound, "User not found")
+	}
+
+	if changeStatusResult.IsTransitionNotAllowed {
+		return ctx.JSON(http.StatusConflict, responses.BadRequestResponse{
+			ErrorMessage: fmt.Sprintf("Account status cannot be changed from %s to %s",
+				changeStatusResult.User.Status, userChangeStatusForm.Status),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, changeStatusResult.User)
+}
+
+// Get database connection pool statistics
+// (GET /admin/database/stats)
+func (s *Server) GetDatabasePoolStats(ctx echo.Context) error {
+
+	isAdmin, err := s.isAuthorizedAdmin(ctx)
+
+	if err != nil {
+		return responses.RespondError(ctx, err)
+	}
+
+	if isAdmin == false {
+		return ctx.JSON(http.StatusForbidden, responses.BadRequestResponse{
+			ErrorMessage: "Your request is made without admin privilege",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, s.systemService.GetDatabasePoolStats(ctx.Request().Context()))
+}
+
+// (GET /healthz)
+func (s *Server) GetLiveness(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, s.healthService.GetLiveness(ctx.Request().Context()))
+}
+
+// (GET /readyz)
+func (s *Server) GetReadiness(ctx echo.Context) error {
+
+	health := s.healthService.GetReadiness(ctx.Request().Context())
+
+	if health.Status != consts.HealthStatusOk {
+		return ctx.JSON(http.StatusServiceUnavailable, health)
+	}
+
+	return ctx.JSON(http.StatusOK, health)
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/SawitProRecruitment/UserService/responses"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// respondPhoneNumberConflict is the answer to registering or updating to a phone number another user has.
+func respondPhoneNumberConflict(ctx echo.Context, phoneNumber string) error {
+
+	return ctx.JSON(http.StatusConflict, responses.BadRequestResponse{
+		ErrorMessage: fmt.Sprintf("Phone number %s is already used by another user", phoneNumber),
+	})
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"github.com/SawitProRecruitment/UserService/services"
+	"github.com/labstack/echo/v4"
+)
+
+func buildRequestMetadata(ctx echo.Context) services.RequestMetadata {
+
+	return services.RequestMetadata{
+		RequestId: ctx.Response().Header().Get(echo.HeaderXRequestID),
+		IpAddress: ctx.RealIP(),
+		UserAgent: ctx.Request().UserAgent(),
+	}
+}
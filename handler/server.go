@@ -7,16 +7,28 @@ import (
 type Server struct {
 	userService services.UserServiceInterface
 	authenticationService services.AuthenticationServiceInterface
+	auditService services.AuditServiceInterface
+	dataExportService services.DataExportServiceInterface
+	systemService services.SystemServiceInterface
+	healthService services.HealthServiceInterface
 }
 
 type NewServerOptions struct {
 	UserService           services.UserServiceInterface
 	AuthenticationService services.AuthenticationServiceInterface
+	AuditService          services.AuditServiceInterface
+	DataExportService     services.DataExportServiceInterface
+	SystemService         services.SystemServiceInterface
+	HealthService         services.HealthServiceInterface
 }
 
 func NewServer(opts NewServerOptions) *Server {
 	return &Server{
 		userService:           opts.UserService,
 		authenticationService: opts.AuthenticationService,
+		auditService:          opts.AuditService,
+		dataExportService:     opts.DataExportService,
+		systemService:         opts.SystemService,
+		healthService:         opts.HealthService,
 	}
 }
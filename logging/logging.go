@@ -0,0 +1,77 @@
+// Package logging sets up the structured JSON logs of the service. Every record carries the request id, the
+// authenticated user id and the trace id of its context, and phone numbers, passwords and tokens are masked.
+package logging
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+)
+
+type contextKey int
+
+const (
+	requestIdContextKey contextKey = iota
+	userIdContextKey
+)
+
+// WithRequestId returns a copy of ctx whose log records carry requestId.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey, requestId)
+}
+
+// WithUserId returns a copy of ctx whose log records carry the id of the authenticated user.
+func WithUserId(ctx context.Context, userId int64) context.Context {
+	return context.WithValue(ctx, userIdContextKey, userId)
+}
+
+// GetRequestId returns the request id of ctx, empty when there is none.
+func GetRequestId(ctx context.Context) string {
+
+	requestId, _ := ctx.Value(requestIdContextKey).(string)
+
+	return requestId
+}
+
+// contextHandler adds the ids of the context to every record, so a call site only logs what it is about.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+
+	if requestId := GetRequestId(ctx); requestId != "" {
+		record.AddAttrs(slog.String("request_id", requestId))
+	}
+
+	if userId, ok := ctx.Value(userIdContextKey).(int64); ok {
+		record.AddAttrs(slog.Int64("user_id", userId))
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// NewLogger writes JSON records of level and above to w, a *slog.LevelVar changes the level while the logger is used.
+func NewLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+
+	return slog.New(contextHandler{
+		Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{
+			AddSource:   true,
+			Level:       level,
+			ReplaceAttr: redactAttr,
+		}),
+	})
+}
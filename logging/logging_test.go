@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		attrs []slog.Attr
+		want  map[string]interface{}
+	}{
+		{
+			name:  "When the context has a request id and a user id, it will add them to the record",
+			ctx:   WithUserId(WithRequestId(context.Background(), "request-1"), 42),
+			attrs: []slog.Attr{slog.String("route", "/users/me")},
+			want:  map[string]interface{}{"request_id": "request-1", "user_id": float64(42), "route": "/users/me"},
+		},
+		{
+			name: "When the record has secrets, it will redact them",
+			ctx:  context.Background(),
+			attrs: []slog.Attr{
+				slog.String("password", "Secret1!"),
+				slog.String("access_token", "abc"),
+				slog.String("phone_number", "08123456789"),
+				slog.Any("error", errors.New("user +628123456789 not found")),
+			},
+			want: map[string]interface{}{
+				"password":     redacted,
+				"access_token": redacted,
+				"phone_number": "081*****789",
+				"error":        "user +62*******789 not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+
+			NewLogger(&output, slog.LevelInfo).LogAttrs(tt.ctx, slog.LevelInfo, "message", tt.attrs...)
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+				t.Fatalf("NewLogger() wrote %q, not JSON: %v", output.String(), err)
+			}
+
+			got := map[string]interface{}{}
+			for key := range tt.want {
+				got[key] = record[key]
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewLogger() record = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretKeyParts mark the attributes whose whole value is a secret, e.g. password, new_password or access_token.
+var secretKeyParts = []string{"password", "token", "secret", "authorization", "private_key"}
+
+var (
+	// jwtPattern matches a JSON web token, its header always starts with {" which is eyJ in base64.
+	jwtPattern         = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bearerPattern      = regexp.MustCompile(`(?i)\bbearer\s+\S+`)
+	phoneNumberPattern = regexp.MustCompile(`\+\d{8,15}`)
+)
+
+// redactAttr is the ReplaceAttr of the JSON handler. It drops the values of secret attributes and masks the
+// phone numbers and tokens of every other string, which covers messages and errors that quote their input.
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+
+	key := strings.ToLower(attr.Key)
+
+	for _, part := range secretKeyParts {
+		if strings.Contains(key, part) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+
+	switch value := attr.Value.Any().(type) {
+	case string:
+
+		// A phone number attribute is masked even when it is not in the international format the pattern matches.
+		if strings.Contains(key, "phone") {
+			return slog.String(attr.Key, MaskPhoneNumber(value))
+		}
+
+		return slog.String(attr.Key, Redact(value))
+	case error:
+		return slog.String(attr.Key, Redact(value.Error()))
+	}
+
+	return attr
+}
+
+// Redact masks the phone numbers, keeping the country code and the last 3 digits, and removes the tokens of s.
+func Redact(s string) string {
+
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+
+	return phoneNumberPattern.ReplaceAllStringFunc(s, MaskPhoneNumber)
+}
+
+// MaskPhoneNumber keeps the + with the 2 digits after it and the last 3 digits, e.g. +62*******789.
+func MaskPhoneNumber(phoneNumber string) string {
+
+	if len(phoneNumber) <= 6 {
+		return strings.Repeat("*", len(phoneNumber))
+	}
+
+	return phoneNumber[:3] + strings.Repeat("*", len(phoneNumber)-6) + phoneNumber[len(phoneNumber)-3:]
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+const maxStackFrames = 32
+
+// StackError is an error together with the call stack where it was wrapped, so the record of an unexpected error
+// logged far from its origin still shows where it came from.
+type StackError struct {
+	err error
+	pcs []uintptr
+}
+
+func (e *StackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *StackError) Unwrap() error {
+	return e.err
+}
+
+// Stack is the call stack of its caller as a "stack" attribute, one "function file:line" entry per frame,
+// skip leaves out that many more frames. The frames of the runtime are left out.
+func Stack(skip int) slog.Attr {
+	return slog.Any("stack", formatStack(callers(skip)))
+}
+
+// WithStack wraps err with the call stack of its caller. A nil error, or one that already has a stack, is returned
+// as it is.
+func WithStack(err error) error {
+
+	var stackErr *StackError
+
+	if err == nil || errors.As(err, &stackErr) {
+		return err
+	}
+
+	return &StackError{err: err, pcs: callers(0)}
+}
+
+// ErrorStack is the stack err was wrapped with as a "stack" attribute, like Stack. It is an empty attribute, which
+// the record leaves out, when err has no stack.
+func ErrorStack(err error) slog.Attr {
+
+	var stackErr *StackError
+
+	if errors.As(err, &stackErr) == false {
+		return slog.Attr{}
+	}
+
+	return slog.Any("stack", formatStack(stackErr.pcs))
+}
+
+// callers returns the program counters of the stack starting at the caller of the function calling callers, skip
+// leaves out that many more frames.
+func callers(skip int) []uintptr {
+
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip+3, pcs)
+
+	return pcs[:n]
+}
+
+func formatStack(pcs []uintptr) []string {
+
+	frames := runtime.CallersFrames(pcs)
+
+	var stack []string
+
+	for {
+
+		frame, more := frames.Next()
+
+		if strings.HasPrefix(frame.Function, "runtime.") == false {
+			stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		}
+
+		if more == false {
+			break
+		}
+	}
+
+	return stack
+}
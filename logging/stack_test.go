@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func wrapUnexpectedError(err error) error {
+	return WithStack(err)
+}
+
+func TestWithStack(t *testing.T) {
+
+	unexpectedErr := errors.New("unexpected error")
+	wrapped := wrapUnexpectedError(unexpectedErr)
+
+	tests := []struct {
+		name          string
+		err           error
+		wantStackFrom string
+	}{
+		{
+			name:          "When the error was wrapped, it will log the stack where it was wrapped",
+			err:           wrapped,
+			wantStackFrom: "logging.wrapUnexpectedError",
+		},
+		{
+			name:          "When the wrapped error is wrapped again, it will keep the first stack",
+			err:           WithStack(wrapped),
+			wantStackFrom: "logging.wrapUnexpectedError",
+		},
+		{
+			name:          "When the error was not wrapped, it will log no stack",
+			err:           unexpectedErr,
+			wantStackFrom: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errors.Is(tt.err, unexpectedErr) == false || tt.err.Error() != unexpectedErr.Error() {
+				t.Errorf("WithStack() = %v, want it to wrap %v", tt.err, unexpectedErr)
+			}
+
+			var output bytes.Buffer
+
+			NewLogger(&output, slog.LevelInfo).Error("request failed", slog.Any("error", tt.err), ErrorStack(tt.err))
+
+			var record struct {
+				Stack []string `json:"stack"`
+			}
+			if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+				t.Fatalf("NewLogger() wrote %q, not JSON: %v", output.String(), err)
+			}
+
+			if tt.wantStackFrom == "" {
+				if record.Stack != nil {
+					t.Errorf("ErrorStack() stack = %v, want none", record.Stack)
+				}
+				return
+			}
+
+			if len(record.Stack) == 0 || strings.Contains(record.Stack[0], tt.wantStackFrom) == false {
+				t.Errorf("ErrorStack() stack = %v, want it to start at %s", record.Stack, tt.wantStackFrom)
+			}
+		})
+	}
+}
+
+func TestWithStack_Nil(t *testing.T) {
+
+	if err := WithStack(nil); err != nil {
+		t.Errorf("WithStack(nil) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var circuitBreakerStates = []string{
+	repository.CircuitBreakerClosed,
+	repository.CircuitBreakerOpen,
+	repository.CircuitBreakerHalfOpen,
+}
+
+var (
+	databaseMaxOpenConnectionsDesc = newDatabaseDesc("database_max_open_connections", "Maximum number of open connections of the pool.")
+	databaseOpenConnectionsDesc    = newDatabaseDesc("database_open_connections", "Open connections of the pool, in use and idle.")
+	databaseInUseConnectionsDesc   = newDatabaseDesc("database_in_use_connections", "Connections of the pool currently in use.")
+	databaseIdleConnectionsDesc    = newDatabaseDesc("database_idle_connections", "Idle connections of the pool.")
+	databaseWaitCountDesc          = newDatabaseDesc("database_wait_count_total", "Times a query waited for a free connection.")
+	databaseWaitDurationDesc       = newDatabaseDesc("database_wait_duration_seconds_total", "Time queries spent waiting for a free connection.")
+	databaseMaxIdleClosedDesc      = newDatabaseDesc("database_max_idle_closed_total", "Connections closed because the pool had too many idle connections.")
+	databaseMaxIdleTimeClosedDesc  = newDatabaseDesc("database_max_idle_time_closed_total", "Connections closed because they were idle for too long.")
+	databaseMaxLifetimeClosedDesc  = newDatabaseDesc("database_max_lifetime_closed_total", "Connections closed because they reached their maximum lifetime.")
+
+	databaseCircuitBreakerStateDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "database_circuit_breaker_state"),
+		"1 for the current state of the database circuit breaker, 0 for the others.", []string{"state"}, nil)
+
+	userCacheHitsDesc      = newDatabaseDesc("user_cache_hits_total", "Users read by id that were found in the user cache.")
+	userCacheMissesDesc    = newDatabaseDesc("user_cache_misses_total", "Users read by id that were read from the database.")
+	userCacheEvictionsDesc = newDatabaseDesc("user_cache_evictions_total", "Users dropped from the user cache to make room for others.")
+	userCacheSizeDesc      = newDatabaseDesc("user_cache_size", "Users currently in the user cache.")
+	userCacheCapacityDesc  = newDatabaseDesc("user_cache_capacity", "Maximum number of users in the user cache.")
+)
+
+func newDatabaseDesc(name string, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, nil, nil)
+}
+
+// DatabaseCollector reads the pool stats, the circuit breaker state and the user cache stats when Prometheus scrapes,
+// the same values GET /admin/database/stats returns.
+type DatabaseCollector struct {
+	databaseRepository repository.DatabaseRepositoryInterface
+	// userCache is nil when the users are not cached.
+	userCache repository.UserCacheInterface
+}
+
+func (d DatabaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(d, ch)
+}
+
+func (d DatabaseCollector) Collect(ch chan<- prometheus.Metric) {
+
+	stats := d.databaseRepository.GetPoolStats()
+
+	ch <- prometheus.MustNewConstMetric(databaseMaxOpenConnectionsDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(databaseOpenConnectionsDesc, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(databaseInUseConnectionsDesc, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(databaseIdleConnectionsDesc, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(databaseWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(databaseWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(databaseMaxIdleClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(databaseMaxIdleTimeClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(databaseMaxLifetimeClosedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+
+	for _, state := range circuitBreakerStates {
+
+		value := 0.0
+
+		if state == stats.CircuitBreakerState {
+			value = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(databaseCircuitBreakerStateDesc, prometheus.GaugeValue, value, state)
+	}
+
+	if d.userCache == nil {
+		return
+	}
+
+	cacheStats := d.userCache.GetUserCacheStats()
+
+	ch <- prometheus.MustNewConstMetric(userCacheHitsDesc, prometheus.CounterValue, float64(cacheStats.Hits))
+	ch <- prometheus.MustNewConstMetric(userCacheMissesDesc, prometheus.CounterValue, float64(cacheStats.Misses))
+	ch <- prometheus.MustNewConstMetric(userCacheEvictionsDesc, prometheus.CounterValue, float64(cacheStats.Evictions))
+	ch <- prometheus.MustNewConstMetric(userCacheSizeDesc, prometheus.GaugeValue, float64(cacheStats.Size))
+	ch <- prometheus.MustNewConstMetric(userCacheCapacityDesc, prometheus.GaugeValue, float64(cacheStats.Capacity))
+}
+
+func NewDatabaseCollector(databaseRepository repository.DatabaseRepositoryInterface, userCache repository.UserCacheInterface) DatabaseCollector {
+
+	return DatabaseCollector{
+		databaseRepository: databaseRepository,
+		userCache:          userCache,
+	}
+}
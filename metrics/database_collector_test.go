@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDatabaseCollector_Collect(t *testing.T) {
+	poolStats := repository.PoolStatsOutput{
+		MaxOpenConnections:  25,
+		OpenConnections:     4,
+		InUse:               3,
+		Idle:                1,
+		WaitCount:           2,
+		WaitDuration:        1500 * time.Millisecond,
+		CircuitBreakerState: repository.CircuitBreakerOpen,
+	}
+	tests := []struct {
+		name        string
+		isCached    bool
+		metricNames []string
+		want        string
+	}{
+		{
+			name:        "When the circuit breaker is open, it will set the gauge of the open state only",
+			metricNames: []string{"user_service_database_circuit_breaker_state", "user_service_database_wait_duration_seconds_total"},
+			want: `
+# HELP user_service_database_circuit_breaker_state 1 for the current state of the database circuit breaker, 0 for the others.
+# TYPE user_service_database_circuit_breaker_state gauge
+user_service_database_circuit_breaker_state{state="closed"} 0
+user_service_database_circuit_breaker_state{state="half_open"} 0
+user_service_database_circuit_breaker_state{state="open"} 1
+# HELP user_service_database_wait_duration_seconds_total Time queries spent waiting for a free connection.
+# TYPE user_service_database_wait_duration_seconds_total counter
+user_service_database_wait_duration_seconds_total 1.5
+`,
+		},
+		{
+			name:        "When the users are cached, it will collect the stats of the user cache",
+			isCached:    true,
+			metricNames: []string{"user_service_user_cache_hits_total", "user_service_user_cache_size"},
+			want: `
+# HELP user_service_user_cache_hits_total Users read by id that were found in the user cache.
+# TYPE user_service_user_cache_hits_total counter
+user_service_user_cache_hits_total 10
+# HELP user_service_user_cache_size Users currently in the user cache.
+# TYPE user_service_user_cache_size gauge
+user_service_user_cache_size 3
+`,
+		},
+		{
+			name:        "When the users are not cached, it will collect no user cache stats",
+			metricNames: []string{"user_service_user_cache_hits_total"},
+			want:        ``,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			databaseRepository := repository.NewMockDatabaseRepositoryInterface(mockCtrl)
+			databaseRepository.EXPECT().GetPoolStats().Return(poolStats).AnyTimes()
+
+			var userCache repository.UserCacheInterface
+			if tt.isCached {
+				mockUserCache := repository.NewMockUserCacheInterface(mockCtrl)
+				mockUserCache.EXPECT().GetUserCacheStats().Return(repository.UserCacheStatsOutput{
+					Hits: 10, Misses: 2, Size: 3, Capacity: 100,
+				}).AnyTimes()
+				userCache = mockUserCache
+			}
+
+			collector := NewDatabaseCollector(databaseRepository, userCache)
+
+			if err := testutil.CollectAndCompare(collector, strings.NewReader(tt.want), tt.metricNames...); err != nil {
+				t.Errorf("Collect() %v", err)
+			}
+		})
+	}
+}
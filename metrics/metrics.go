@@ -0,0 +1,97 @@
+// Package metrics holds the Prometheus metrics of the service, served by GET /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "user_service"
+
+// Registry holds every metric of the service. It is not the default registry, so a dependency
+// registering its own metrics does not end up on /metrics unnoticed.
+var Registry = prometheus.NewRegistry()
+
+// The results and reasons the counters are labeled with.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+
+	LoginFailureReasonValidationError = "validation_error"
+
+	RegistrationResultValidationError     = "validation_error"
+	RegistrationResultPhoneNumberConflict = "phone_number_conflict"
+	RegistrationResultEmailConflict       = "email_conflict"
+
+	TokenFailureReasonInvalidToken        = "invalid_token"
+	TokenFailureReasonUnauthorizedUser    = "unauthorized_user"
+	TokenFailureReasonDatabaseUnavailable = "database_unavailable"
+	// TokenFailureReasonError is any other failure to check the user, e.g. a query error or the request timeout.
+	TokenFailureReasonError = "error"
+
+	PasswordOperationGenerate = "generate"
+	PasswordOperationCompare  = "compare"
+)
+
+var (
+	HttpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, echo route path and status code.",
+	}, []string{"method", "route", "status"})
+
+	HttpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to answer HTTP requests by method, echo route path and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// LoginAttempts is labeled with the failure reasons of the login events, plus LoginFailureReasonValidationError.
+	LoginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_attempts_total",
+		Help:      "Login attempts by result and failure reason, the reason is empty for successful logins.",
+	}, []string{"result", "reason"})
+
+	Registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "User registrations by result.",
+	}, []string{"result"})
+
+	TokenVerificationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_verification_failures_total",
+		Help:      "Requests whose bearer token was not accepted, by reason.",
+	}, []string{"reason"})
+
+	// PasswordHashDuration has buckets around the 50-100ms bcrypt takes at cost 10.
+	PasswordHashDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "password_hash_duration_seconds",
+		Help:      "Time taken by bcrypt to generate or compare a password hash.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.075, 0.1, 0.15, 0.25, 0.5, 1},
+	}, []string{"operation"})
+
+	ConfigReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "Reloads of the settings and the keys by result, a failed reload keeps the previous ones.",
+	}, []string{"result"})
+)
+
+func init() {
+
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HttpRequests,
+		HttpRequestDuration,
+		LoginAttempts,
+		Registrations,
+		TokenVerificationFailures,
+		PasswordHashDuration,
+		ConfigReloads,
+	)
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"github.com/labstack/echo/v4"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AccessLogMiddleware logs a record per request once it is answered. The record is logged with the context the
+// inner middlewares left on the request, so it carries the request id and the authenticated user id.
+type AccessLogMiddleware struct {
+	logger *slog.Logger
+}
+
+func (a *AccessLogMiddleware) Process(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		start := time.Now()
+
+		err := next(c)
+
+		status := getResponseStatus(c, err)
+
+		level := slog.LevelInfo
+
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		a.logger.LogAttrs(c.Request().Context(), level, "request",
+			slog.String("method", c.Request().Method),
+			slog.String("route", c.Path()),
+			slog.String("path", c.Request().URL.Path),
+			slog.Int("status", status),
+			slog.Int64("bytes", c.Response().Size),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
+
+		return err
+	}
+}
+
+func NewAccessLogMiddleware(logger *slog.Logger) AccessLogMiddleware {
+
+	return AccessLogMiddleware{
+		logger: logger,
+	}
+}
@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/labstack/echo/v4"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels the requests no route matched, their paths would give every scanned URL its own series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware counts the requests and observes their duration by method, echo route path and status code.
+type MetricsMiddleware struct {
+}
+
+func (m *MetricsMiddleware) Process(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		start := time.Now()
+
+		err := next(c)
+
+		route := c.Path()
+
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		labels := []string{c.Request().Method, route, strconv.Itoa(getResponseStatus(c, err))}
+
+		metrics.HttpRequests.WithLabelValues(labels...).Inc()
+		metrics.HttpRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+func NewMetricsMiddleware() MetricsMiddleware {
+	return MetricsMiddleware{}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricsMiddleware_Process(t *testing.T) {
+	tests := []struct {
+		name       string
+		route      string
+		handler    echo.HandlerFunc
+		wantLabels []string
+	}{
+		{
+			name:  "When the handler answers, it will count the request by route and status",
+			route: "/users/:id",
+			handler: func(c echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			},
+			wantLabels: []string{http.MethodGet, "/users/:id", "204"},
+		},
+		{
+			name:  "When the handler returns an http error, it will count the request with its status",
+			route: "/users/:id",
+			handler: func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusNotFound)
+			},
+			wantLabels: []string{http.MethodGet, "/users/:id", "404"},
+		},
+		{
+			name: "When no route matched, it will count the request as unmatched instead of by its path",
+			handler: func(c echo.Context) error {
+				return echo.ErrNotFound
+			},
+			wantLabels: []string{http.MethodGet, unmatchedRoute, "404"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetricsMiddleware()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/users/7", nil), httptest.NewRecorder())
+			c.SetPath(tt.route)
+
+			before := testutil.ToFloat64(metrics.HttpRequests.WithLabelValues(tt.wantLabels...))
+
+			_ = m.Process(tt.handler)(c)
+
+			if got := testutil.ToFloat64(metrics.HttpRequests.WithLabelValues(tt.wantLabels...)) - before; got != 1 {
+				t.Errorf("Process() counted %v requests with labels %v, want 1", got, tt.wantLabels)
+			}
+		})
+	}
+}
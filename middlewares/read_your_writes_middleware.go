@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// ReadYourWritesMiddleware scopes the primary pin of the repository to the request, the reads of a request go to
+// the replicas until it writes something and to the primary after that.
+type ReadYourWritesMiddleware struct {
+}
+
+func (r *ReadYourWritesMiddleware) Process(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		c.SetRequest(c.Request().WithContext(repository.WithReadYourWrites(c.Request().Context())))
+
+		return next(c)
+	}
+}
+
+func NewReadYourWritesMiddleware() ReadYourWritesMiddleware {
+	return ReadYourWritesMiddleware{}
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadYourWritesMiddleware_Process(t *testing.T) {
+	tests := []struct {
+		name       string
+		isWritten  bool
+		wantPinned bool
+	}{
+		{
+			name:       "When the request wrote something, it will pin the later reads to the primary",
+			isWritten:  true,
+			wantPinned: true,
+		},
+		{
+			name:       "When the request wrote nothing, it will leave the reads on the replicas",
+			isWritten:  false,
+			wantPinned: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReadYourWritesMiddleware()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodPatch, "/users/me", nil), httptest.NewRecorder())
+			var gotPinned bool
+			err := r.Process(func(c echo.Context) error {
+				if tt.isWritten {
+					repository.PinToPrimary(c.Request().Context())
+				}
+				gotPinned = repository.IsPinnedToPrimary(c.Request().Context())
+				return nil
+			})(c)
+			if err != nil {
+				t.Errorf("Process() error = %v", err)
+				return
+			}
+			if gotPinned != tt.wantPinned {
+				t.Errorf("Process() pinned = %v, want %v", gotPinned, tt.wantPinned)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/labstack/echo/v4"
+	"regexp"
+)
+
+// requestIdPattern limits the X-Request-ID a caller can choose, the id ends up in every log record of the request.
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIdMiddleware keeps the X-Request-ID of the caller, e.g. a load balancer, or generates one. The id is sent
+// back in the response and put in the request context for the log records.
+type RequestIdMiddleware struct {
+	generate func() string
+}
+
+func (r *RequestIdMiddleware) Process(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		request := c.Request()
+
+		requestId := request.Header.Get(echo.HeaderXRequestID)
+
+		if requestIdPattern.MatchString(requestId) == false {
+			requestId = r.generate()
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, requestId)
+		c.SetRequest(request.WithContext(logging.WithRequestId(request.Context(), requestId)))
+
+		return next(c)
+	}
+}
+
+func generateRequestId() string {
+
+	id := make([]byte, 16)
+
+	// crypto/rand does not fail on the platforms Go supports.
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+func NewRequestIdMiddleware() RequestIdMiddleware {
+
+	return RequestIdMiddleware{
+		generate: generateRequestId,
+	}
+}
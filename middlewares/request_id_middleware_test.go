@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIdMiddleware_Process(t *testing.T) {
+	tests := []struct {
+		name            string
+		callerRequestId string
+		want            string
+	}{
+		{
+			name:            "When the caller sends a request id, it will keep it",
+			callerRequestId: "lb-7f3a.1",
+			want:            "lb-7f3a.1",
+		},
+		{
+			name: "When the caller sends no request id, it will generate one",
+			want: "generated",
+		},
+		{
+			name:            "When the caller sends a request id that could forge log lines, it will generate one",
+			callerRequestId: "1\n{\"level\":\"ERROR\"}",
+			want:            "generated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := RequestIdMiddleware{generate: func() string { return "generated" }}
+			request := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+			if tt.callerRequestId != "" {
+				request.Header.Set(echo.HeaderXRequestID, tt.callerRequestId)
+			}
+			recorder := httptest.NewRecorder()
+			c := echo.New().NewContext(request, recorder)
+
+			var gotContextRequestId string
+			err := r.Process(func(c echo.Context) error {
+				gotContextRequestId = logging.GetRequestId(c.Request().Context())
+				return nil
+			})(c)
+			if err != nil {
+				t.Errorf("Process() error = %v", err)
+				return
+			}
+			if got := recorder.Header().Get(echo.HeaderXRequestID); got != tt.want {
+				t.Errorf("Process() response request id = %v, want %v", got, tt.want)
+			}
+			if gotContextRequestId != tt.want {
+				t.Errorf("Process() context request id = %v, want %v", gotContextRequestId, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"time"
+)
+
+// RequestTimeoutMiddleware puts a deadline on the request context, so database queries of a slow
+// request are cancelled. Routes are keyed by method and echo route path, e.g. "PUT /admin/users/:id/role".
+type RequestTimeoutMiddleware struct {
+	defaultTimeout time.Duration
+	routeTimeouts  map[string]time.Duration
+}
+
+func (r *RequestTimeoutMiddleware) Process(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		timeout := r.getTimeout(c.Request().Method, c.Path())
+
+		ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+		defer cancel()
+
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
+
+func (r *RequestTimeoutMiddleware) getTimeout(method string, path string) time.Duration {
+
+	if timeout, ok := r.routeTimeouts[method+" "+path]; ok {
+		return timeout
+	}
+
+	return r.defaultTimeout
+}
+
+func NewRequestTimeoutMiddleware(defaultTimeout time.Duration, routeTimeouts map[string]time.Duration) RequestTimeoutMiddleware {
+
+	return RequestTimeoutMiddleware{
+		defaultTimeout: defaultTimeout,
+		routeTimeouts:  routeTimeouts,
+	}
+}
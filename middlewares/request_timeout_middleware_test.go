@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeoutMiddleware_Process(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantTimeout time.Duration
+	}{
+		{
+			name:        "When the route has its own timeout, it will put that deadline on the request context",
+			method:      http.MethodPost,
+			path:        "/users/me/export",
+			wantTimeout: 30 * time.Second,
+		},
+		{
+			name:        "When the route has no timeout of its own, it will use the default timeout",
+			method:      http.MethodGet,
+			path:        "/users/me",
+			wantTimeout: 10 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRequestTimeoutMiddleware(10*time.Second, map[string]time.Duration{
+				"POST /users/me/export": 30 * time.Second,
+			})
+			c := echo.New().NewContext(httptest.NewRequest(tt.method, tt.path, nil), httptest.NewRecorder())
+			c.SetPath(tt.path)
+			startedAt := time.Now()
+			var gotDeadline time.Time
+			err := r.Process(func(c echo.Context) error {
+				gotDeadline, _ = c.Request().Context().Deadline()
+				return nil
+			})(c)
+			if err != nil {
+				t.Errorf("Process() error = %v", err)
+				return
+			}
+			if gotTimeout := gotDeadline.Sub(startedAt); gotTimeout < tt.wantTimeout || gotTimeout > tt.wantTimeout+time.Second {
+				t.Errorf("Process() timeout = %v, want %v", gotTimeout, tt.wantTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// getResponseStatus is the status code the request is answered with once next returned err. The error handler
+// writes the response after the middlewares return, so for an error the status comes from the error.
+func getResponseStatus(c echo.Context, err error) int {
+
+	if err == nil {
+		return c.Response().Status
+	}
+
+	var httpError *echo.HTTPError
+
+	if errors.As(err, &httpError) {
+		return httpError.Code
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// TracingMiddleware starts a server span per request, continuing the trace of the caller when the request
+// carries its context, e.g. a W3C traceparent header. The span is named by method and echo route path.
+type TracingMiddleware struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func (t *TracingMiddleware) Process(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		request := c.Request()
+
+		route := c.Path()
+
+		if route == "" {
+			route = request.URL.Path
+		}
+
+		ctx := t.propagator.Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+
+		ctx, span := t.tracer.Start(ctx, request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		c.SetRequest(request.WithContext(ctx))
+
+		err := next(c)
+
+		status := getResponseStatus(c, err)
+
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		if requestId := c.Response().Header().Get(echo.HeaderXRequestID); requestId != "" {
+			span.SetAttributes(attribute.StringSlice("http.response.header.x-request-id", []string{requestId}))
+		}
+
+		return err
+	}
+}
+
+func NewTracingMiddleware(tracerProvider trace.TracerProvider, propagator propagation.TextMapPropagator) TracingMiddleware {
+
+	return TracingMiddleware{
+		tracer:     tracerProvider.Tracer("github.com/SawitProRecruitment/UserService/middlewares"),
+		propagator: propagator,
+	}
+}
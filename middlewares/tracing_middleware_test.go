@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracingMiddleware_Process(t *testing.T) {
+	tests := []struct {
+		name            string
+		traceparent     string
+		handlerErr      error
+		wantTraceId     string
+		wantParentId    string
+		wantStatus      int
+		wantStatusError bool
+	}{
+		{
+			name:        "When the request carries a traceparent header, it will continue the trace of the caller",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTraceId: "4bf92f3577b34da6a3ce929d0e0e4736", wantParentId: "00f067aa0ba902b7",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "When the request carries no trace context, it will start a new trace",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "When the handler returns an http error, it will record its status code",
+			handlerErr: echo.NewHTTPError(http.StatusNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:            "When the handler returns any other error, it will mark the span as failed",
+			handlerErr:      errors.New("connection refused"),
+			wantStatus:      http.StatusInternalServerError,
+			wantStatusError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			m := NewTracingMiddleware(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), propagation.TraceContext{})
+
+			e := echo.New()
+			request := httptest.NewRequest(http.MethodGet, "/admin/users/7", nil)
+			if tt.traceparent != "" {
+				request.Header.Set("traceparent", tt.traceparent)
+			}
+			c := e.NewContext(request, httptest.NewRecorder())
+			c.SetPath("/admin/users/:id")
+
+			var handlerSpanContext trace.SpanContext
+			err := m.Process(func(c echo.Context) error {
+				handlerSpanContext = trace.SpanContextFromContext(c.Request().Context())
+				if tt.handlerErr != nil {
+					return tt.handlerErr
+				}
+				return c.NoContent(http.StatusOK)
+			})(c)
+			if errors.Is(err, tt.handlerErr) == false {
+				t.Errorf("Process() error = %v, want %v", err, tt.handlerErr)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("Process() ended %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != "GET /admin/users/:id" {
+				t.Errorf("Process() span name = %s, want GET /admin/users/:id", span.Name())
+			}
+			if span.SpanContext().SpanID() != handlerSpanContext.SpanID() {
+				t.Errorf("Process() handler span = %s, want %s", handlerSpanContext.SpanID(), span.SpanContext().SpanID())
+			}
+			if tt.wantTraceId != "" && span.SpanContext().TraceID().String() != tt.wantTraceId {
+				t.Errorf("Process() trace id = %s, want %s", span.SpanContext().TraceID(), tt.wantTraceId)
+			}
+			if tt.wantParentId == "" && span.Parent().IsValid() {
+				t.Errorf("Process() parent = %s, want none", span.Parent().SpanID())
+			}
+			if tt.wantParentId != "" && span.Parent().SpanID().String() != tt.wantParentId {
+				t.Errorf("Process() parent = %s, want %s", span.Parent().SpanID(), tt.wantParentId)
+			}
+			var gotStatus int64
+			for _, attribute := range span.Attributes() {
+				if attribute.Key == semconv.HTTPResponseStatusCodeKey {
+					gotStatus = attribute.Value.AsInt64()
+				}
+			}
+			if gotStatus != int64(tt.wantStatus) {
+				t.Errorf("Process() status code = %d, want %d", gotStatus, tt.wantStatus)
+			}
+			if (span.Status().Code == codes.Error) != tt.wantStatusError {
+				t.Errorf("Process() span status = %v, want error %v", span.Status().Code, tt.wantStatusError)
+			}
+		})
+	}
+}
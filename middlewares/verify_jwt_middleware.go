@@ -1,7 +1,12 @@
 package middlewares
 
 import (
+	"context"
+	"errors"
 	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/SawitProRecruitment/UserService/repository"
 	"github.com/SawitProRecruitment/UserService/responses"
 	"github.com/SawitProRecruitment/UserService/services"
 	"github.com/labstack/echo/v4"
@@ -16,9 +21,14 @@ type VerifyJwtMiddleware struct {
 func (v *VerifyJwtMiddleware) getWhiteListRoute() map[string]string {
 
 	return map[string]string{
-		"/users/register": "POST",
-		"/users/login":    "POST",
-		"/":               "GET",
+		"/users/register":        "POST",
+		"/users/login":           "POST",
+		"/users/email/verify":    "POST",
+		"/users/export/download": "GET",
+		"/healthz":               "GET",
+		"/readyz":                "GET",
+		// GET /metrics checks its own token, the one of the scraper.
+		"/metrics": "GET",
 	}
 }
 
@@ -33,20 +43,48 @@ func (v *VerifyJwtMiddleware) Process(next echo.HandlerFunc) echo.HandlerFunc {
 			return next(c)
 		}
 
-		isTokenAllowed, userId := v.isTokenAllowed(request.Header.Get("Authorization"))
+		authorizeResult, err := v.authorize(request.Context(), request.Header.Get("Authorization"))
+
+		// The token may be fine, the user behind it cannot be checked right now. That is no reason to send the
+		// client away with 403, nor to count it as a bad token.
+		if err != nil {
+
+			reason := metrics.TokenFailureReasonError
+
+			var unavailableErr *repository.DatabaseUnavailableError
+
+			if errors.As(err, &unavailableErr) {
+				reason = metrics.TokenFailureReasonDatabaseUnavailable
+			}
+
+			metrics.TokenVerificationFailures.WithLabelValues(reason).Inc()
+
+			return responses.RespondError(c, err)
+		}
+
+		if authorizeResult.IsAuthorized == false {
+
+			reason := metrics.TokenFailureReasonUnauthorizedUser
+
+			if authorizeResult.IsInvalidToken {
+				reason = metrics.TokenFailureReasonInvalidToken
+			}
+
+			metrics.TokenVerificationFailures.WithLabelValues(reason).Inc()
 
-		if isTokenAllowed == false {
 			return c.JSON(http.StatusForbidden, responses.BadRequestResponse{
 				ErrorMessage: "Your request is made with invalid credential",
 			})
 		}
 
-		c.Set(consts.ContextAuthorizedUsedId, *userId)
+		userId := authorizeResult.UserId
+
+		c.Set(consts.ContextAuthorizedUsedId, userId)
+		c.SetRequest(request.WithContext(logging.WithUserId(request.Context(), userId)))
 
 		return next(c)
 	}
 }
-
 func (v *VerifyJwtMiddleware) isUrlAllowed(requestMethod string, url string) bool {
 
 	whiteListRoute := v.getWhiteListRoute()
@@ -58,17 +96,12 @@ func (v *VerifyJwtMiddleware) isUrlAllowed(requestMethod string, url string) boo
 	return false
 }
 
-func (v *VerifyJwtMiddleware) isTokenAllowed(jwtToken string) (bool, *int64) {
+// authorize returns the error of the authorization only when the user behind the token could not be checked.
+func (v *VerifyJwtMiddleware) authorize(ctx context.Context, jwtToken string) (*services.AuthorizationResult, error) {
 
 	tokenString := strings.Replace(jwtToken, "Bearer ", "", -1)
 
-	authorizeResult, err := v.authenticationService.Authorize(tokenString)
-
-	if err != nil || authorizeResult.IsAuthorized == false {
-		return false, nil
-	}
-
-	return true, &authorizeResult.UserId
+	return v.authenticationService.Authorize(ctx, tokenString)
 }
 
 func NewVerifyJwtMiddleware(svc services.Services) VerifyJwtMiddleware {
@@ -1,11 +1,19 @@
 package middlewares
 
 import (
+	"context"
+	"errors"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/repository"
 	"github.com/SawitProRecruitment/UserService/services"
 	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type VerifyJWTMiddlewareTestSuite struct {
@@ -45,7 +53,11 @@ func (ts *VerifyJWTMiddlewareTestSuite) TestNewVerifyJwtMiddleware() {
 				svc: struct {
 					Authentication services.AuthenticationServiceInterface
 					User           services.UserServiceInterface
-				}{Authentication: ts.authenticationService, User: nil},
+					Audit          services.AuditServiceInterface
+					DataExport     services.DataExportServiceInterface
+					System         services.SystemServiceInterface
+					Health         services.HealthServiceInterface
+				}{Authentication: ts.authenticationService, User: nil, Audit: nil, DataExport: nil, System: nil, Health: nil},
 			},
 			want: VerifyJwtMiddleware{
 				authenticationService: ts.authenticationService,
@@ -110,79 +122,94 @@ func (ts *VerifyJWTMiddlewareTestSuite) TestVerifyJwtMiddleware_isUrlAllowed() {
 	}
 }
 
-func (ts *VerifyJWTMiddlewareTestSuite) TestVerifyJwtMiddleware_isTokenAllowed() {
-
-	validUsedId := int64(123)
-	type fields struct {
-		authenticationService services.AuthenticationServiceInterface
-	}
-	type args struct {
-		jwtToken string
-	}
+func (ts *VerifyJWTMiddlewareTestSuite) TestVerifyJwtMiddleware_Process() {
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		mock   func() error
-		want   bool
-		want1  *int64
+		name       string
+		mock       func()
+		wantStatus int
 	}{
 		{
-			name: "When the authorization service return not authorized given the token, then itu return false and nil",
-			fields: fields{
-				authenticationService: ts.authenticationService,
-			},
-			args: args{
-				jwtToken: "dummy json web token",
+			name: "When the token is invalid, then it will answer 403",
+			mock: func() {
+				ts.authenticationService.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(&services.AuthorizationResult{
+					IsAuthorized:   false,
+					IsInvalidToken: true,
+				}, nil)
 			},
-			want:  false,
-			want1: nil,
-			mock: func() error {
-
-				ts.authenticationService.EXPECT().Authorize(gomock.Any()).Return(&services.AuthorizationResult{
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "When the user is not active, then it will answer 403",
+			mock: func() {
+				ts.authenticationService.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(&services.AuthorizationResult{
 					IsAuthorized: false,
-					UserId:       0,
+					UserId:       123,
 				}, nil)
-
-				return nil
 			},
+			wantStatus: http.StatusForbidden,
 		},
-
 		{
-			name: "When the authorization service return authorized given the token, then itu return true and userId",
-			fields: fields{
-				authenticationService: ts.authenticationService,
-			},
-			args: args{
-				jwtToken: "dummy json web token",
-			},
-			want:  true,
-			want1: &validUsedId,
-			mock: func() error {
-
-				ts.authenticationService.EXPECT().Authorize(gomock.Any()).Return(&services.AuthorizationResult{
+			name: "When the user is authorized, then it will call the next handler",
+			mock: func() {
+				ts.authenticationService.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(&services.AuthorizationResult{
 					IsAuthorized: true,
 					UserId:       123,
 				}, nil)
-
-				return nil
 			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "When the database is unavailable, then it will answer 503",
+			mock: func() {
+				ts.authenticationService.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, &repository.DatabaseUnavailableError{
+					RetryAfter: 10 * time.Second,
+				})
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "When the request timed out while checking the user, then it will answer 503",
+			mock: func() {
+				ts.authenticationService.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, context.DeadlineExceeded)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "When the client went away while checking the user, then it will answer 499",
+			mock: func() {
+				ts.authenticationService.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, context.Canceled)
+			},
+			wantStatus: consts.StatusClientClosedRequest,
+		},
+		{
+			name: "When the user cannot be read, then it will answer 500",
+			mock: func() {
+				ts.authenticationService.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection reset"))
+			},
+			wantStatus: http.StatusInternalServerError,
 		},
 	}
 	for _, tt := range tests {
 		ts.T().Run(tt.name, func(t *testing.T) {
 			v := &VerifyJwtMiddleware{
-				authenticationService: tt.fields.authenticationService,
+				authenticationService: ts.authenticationService,
 			}
 
 			tt.mock()
 
-			got, got1 := v.isTokenAllowed(tt.args.jwtToken)
-			if got != tt.want {
-				t.Errorf("isTokenAllowed() got = %v, want %v", got, tt.want)
+			request := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+			request.Header.Set("Authorization", "Bearer dummy json web token")
+			recorder := httptest.NewRecorder()
+
+			err := v.Process(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(echo.New().NewContext(request, recorder))
+
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
 			}
-			if !reflect.DeepEqual(got1, tt.want1) {
-				t.Errorf("isTokenAllowed() got1 = %v, want %v", got1, tt.want1)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Process() status = %d, want %d", recorder.Code, tt.wantStatus)
 			}
 		})
 	}
@@ -0,0 +1,35 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailSender writes every message as an .eml file into a directory instead of
+// delivering it, so tests and local runs can read verification mails from disk.
+type FileMailSender struct {
+	directory string
+	from      string
+}
+
+func (f FileMailSender) Send(message MailMessage) error {
+
+	err := os.MkdirAll(f.directory, 0o755)
+
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), message.To)
+
+	return os.WriteFile(filepath.Join(f.directory, fileName), buildMailContent(f.from, message), 0o644)
+}
+
+func NewFileMailSender(directory string, from string) MailSenderInterface {
+	return FileMailSender{
+		directory: directory,
+		from:      from,
+	}
+}
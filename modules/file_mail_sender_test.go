@@ -0,0 +1,60 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailSender_Send(t *testing.T) {
+	type args struct {
+		message MailMessage
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantContains []string
+		wantErr      bool
+	}{
+		{
+			name: "When given valid message, it will write the message into an eml file",
+			args: args{
+				message: MailMessage{
+					To:      "rizqy@example.com",
+					Subject: "Verify your email",
+					Body:    "Your verification code is 123",
+				},
+			},
+			wantContains: []string{
+				"From: no-reply@example.com",
+				"To: rizqy@example.com",
+				"Subject: Verify your email",
+				"Your verification code is 123",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directory := t.TempDir()
+			f := NewFileMailSender(directory, "no-reply@example.com")
+			err := f.Send(tt.args.message)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			files, _ := filepath.Glob(filepath.Join(directory, "*.eml"))
+			if len(files) != 1 {
+				t.Errorf("Send() wrote %d files, want 1", len(files))
+				return
+			}
+			content, _ := os.ReadFile(files[0])
+			for _, want := range tt.wantContains {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Send() content = %s, want contains %s", content, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package modules
+
+type FileStorageInterface interface {
+	Write(name string, content []byte) error
+	Read(name string) ([]byte, error)
+	// Delete removes a file, a file that does not exist is not an error.
+	Delete(name string) error
+}
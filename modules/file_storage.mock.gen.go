@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./modules/file_storage.go
+
+// Package modules is a generated GoMock package.
+package modules
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFileStorageInterface is a mock of FileStorageInterface interface.
+type MockFileStorageInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileStorageInterfaceMockRecorder
+}
+
+// MockFileStorageInterfaceMockRecorder is the mock recorder for MockFileStorageInterface.
+type MockFileStorageInterfaceMockRecorder struct {
+	mock *MockFileStorageInterface
+}
+
+// NewMockFileStorageInterface creates a new mock instance.
+func NewMockFileStorageInterface(ctrl *gomock.Controller) *MockFileStorageInterface {
+	mock := &MockFileStorageInterface{ctrl: ctrl}
+	mock.recorder = &MockFileStorageInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFileStorageInterface) EXPECT() *MockFileStorageInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockFileStorageInterface) Delete(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFileStorageInterfaceMockRecorder) Delete(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFileStorageInterface)(nil).Delete), name)
+}
+
+// Read mocks base method.
+func (m *MockFileStorageInterface) Read(name string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", name)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockFileStorageInterfaceMockRecorder) Read(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockFileStorageInterface)(nil).Read), name)
+}
+
+// Write mocks base method.
+func (m *MockFileStorageInterface) Write(name string, content []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", name, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockFileStorageInterfaceMockRecorder) Write(name, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockFileStorageInterface)(nil).Write), name, content)
+}
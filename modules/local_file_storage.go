@@ -0,0 +1,73 @@
+package modules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// LocalFileStorage keeps files in a directory on the local disk.
+type LocalFileStorage struct {
+	directory string
+}
+
+func (l LocalFileStorage) Write(name string, content []byte) error {
+
+	path, err := l.path(name)
+
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(l.directory, 0o700)
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0o600)
+}
+
+func (l LocalFileStorage) Read(name string) ([]byte, error) {
+
+	path, err := l.path(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+func (l LocalFileStorage) Delete(name string) error {
+
+	path, err := l.path(name)
+
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// path only accepts plain file names, so a name can never point outside the directory.
+func (l LocalFileStorage) path(name string) (string, error) {
+
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", errors.New("invalid file name")
+	}
+
+	return filepath.Join(l.directory, name), nil
+}
+
+func NewLocalFileStorage(directory string) FileStorageInterface {
+	return LocalFileStorage{
+		directory: directory,
+	}
+}
@@ -0,0 +1,84 @@
+package modules
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalFileStorage_Write(t *testing.T) {
+	type args struct {
+		name    string
+		content []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "When given a plain file name, it will write the file so it can be read again",
+			args: args{
+				name:    "personal-data-export-1.zip",
+				content: []byte("archive"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "When given a file name pointing outside the directory, it will return error",
+			args: args{
+				name:    "../personal-data-export-1.zip",
+				content: []byte("archive"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLocalFileStorage(filepath.Join(t.TempDir(), "exports"))
+			err := l.Write(tt.args.name, tt.args.content)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := l.Read(tt.args.name)
+			if err != nil {
+				t.Errorf("Read() error = %v", err)
+				return
+			}
+			if !bytes.Equal(got, tt.args.content) {
+				t.Errorf("Read() got = %s, want %s", got, tt.args.content)
+			}
+		})
+	}
+}
+
+func TestLocalFileStorage_Delete(t *testing.T) {
+
+	l := NewLocalFileStorage(filepath.Join(t.TempDir(), "exports"))
+
+	if err := l.Write("personal-data-export-1.zip", []byte("archive")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Delete("personal-data-export-1.zip"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+
+	if _, err := l.Read("personal-data-export-1.zip"); errors.Is(err, os.ErrNotExist) == false {
+		t.Errorf("Read() of a deleted file error = %v, want %v", err, os.ErrNotExist)
+	}
+
+	if err := l.Delete("personal-data-export-1.zip"); err != nil {
+		t.Errorf("Delete() of a missing file error = %v, want nil", err)
+	}
+
+	if err := l.Delete("../personal-data-export-1.zip"); err == nil {
+		t.Errorf("Delete() of a file name pointing outside the directory error = nil, want error")
+	}
+}
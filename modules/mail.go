@@ -0,0 +1,11 @@
+package modules
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type MailSenderInterface interface {
+	Send(message MailMessage) error
+}
@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./modules/mail.go
+
+// Package modules is a generated GoMock package.
+package modules
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMailSenderInterface is a mock of MailSenderInterface interface.
+type MockMailSenderInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailSenderInterfaceMockRecorder
+}
+
+// MockMailSenderInterfaceMockRecorder is the mock recorder for MockMailSenderInterface.
+type MockMailSenderInterfaceMockRecorder struct {
+	mock *MockMailSenderInterface
+}
+
+// NewMockMailSenderInterface creates a new mock instance.
+func NewMockMailSenderInterface(ctrl *gomock.Controller) *MockMailSenderInterface {
+	mock := &MockMailSenderInterface{ctrl: ctrl}
+	mock.recorder = &MockMailSenderInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailSenderInterface) EXPECT() *MockMailSenderInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockMailSenderInterface) Send(message MailMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMailSenderInterfaceMockRecorder) Send(message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailSenderInterface)(nil).Send), message)
+}
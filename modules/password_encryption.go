@@ -0,0 +1,64 @@
+package modules
+
+import (
+	"bytes"
+	"filippo.io/age"
+	"io"
+)
+
+// passwordEncryptionWorkFactor is the scrypt work factor (log2 of N) of the age passphrase recipient. The age default of
+// 18 takes 256MB of memory per encryption, too much for the builds running at once.
+const passwordEncryptionWorkFactor = 15
+
+// EncryptWithPassword encrypts content into an age file (https://age-encryption.org/v1) with password as the passphrase,
+// so it can be opened with `age -d` or any other age implementation.
+func EncryptWithPassword(content []byte, password string) ([]byte, error) {
+
+	recipient, err := age.NewScryptRecipient(password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recipient.SetWorkFactor(passwordEncryptionWorkFactor)
+
+	encrypted := bytes.Buffer{}
+
+	writer, err := age.Encrypt(&encrypted, recipient)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = writer.Write(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return encrypted.Bytes(), nil
+}
+
+// DecryptWithPassword reverses EncryptWithPassword. It fails when the password is wrong or the content was modified.
+func DecryptWithPassword(encrypted []byte, password string) ([]byte, error) {
+
+	identity, err := age.NewScryptIdentity(password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := age.Decrypt(bytes.NewReader(encrypted), identity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,66 @@
+package modules
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptWithPassword(t *testing.T) {
+	content := []byte("personal data archive")
+
+	encrypted, err := EncryptWithPassword(content, "correct password")
+
+	if err != nil {
+		t.Fatalf("EncryptWithPassword() error = %v", err)
+	}
+
+	if bytes.Contains(encrypted, content) {
+		t.Errorf("EncryptWithPassword() result contains the plain content")
+	}
+
+	if bytes.HasPrefix(encrypted, []byte("age-encryption.org/v1\n-> scrypt ")) == false {
+		t.Errorf("EncryptWithPassword() result is not an age file with a passphrase stanza")
+	}
+
+	tests := []struct {
+		name      string
+		encrypted []byte
+		password  string
+		want      []byte
+		wantErr   bool
+	}{
+		{
+			name:      "When given the correct password, it will return the original content",
+			encrypted: encrypted,
+			password:  "correct password",
+			want:      content,
+			wantErr:   false,
+		},
+		{
+			name:      "When given a wrong password, it will return error",
+			encrypted: encrypted,
+			password:  "wrong password",
+			want:      nil,
+			wantErr:   true,
+		},
+		{
+			name:      "When the content is not encrypted, it will return error",
+			encrypted: content,
+			password:  "correct password",
+			want:      nil,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecryptWithPassword(tt.encrypted, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecryptWithPassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("DecryptWithPassword() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package modules
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// RS256KeySet is the key the tokens are signed with and the keys they are verified with. Keeping the public keys of
+// the previous signing keys lets the tokens issued before a key rotation stay valid until they expire.
+type RS256KeySet struct {
+	signingKey       *rsa.PrivateKey
+	signingKeyId     string
+	verificationKeys map[string]*rsa.PublicKey
+}
+
+// SigningKeyId is the kid header of the tokens signed with the key set.
+func (k *RS256KeySet) SigningKeyId() string {
+	return k.signingKeyId
+}
+
+// VerificationKeyIds are the kid headers of the tokens the key set verifies, sorted.
+func (k *RS256KeySet) VerificationKeyIds() []string {
+
+	keyIds := make([]string, 0, len(k.verificationKeys))
+
+	for keyId := range k.verificationKeys {
+		keyIds = append(keyIds, keyId)
+	}
+
+	sort.Strings(keyIds)
+
+	return keyIds
+}
+
+// getRS256KeyId derives the id of a key from its public key, so every instance sharing the key agrees on the id.
+func getRS256KeyId(publicKey *rsa.PublicKey) (string, error) {
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(der)
+
+	return hex.EncodeToString(digest[:8]), nil
+}
+
+// NewRS256KeySet parses the PEM encoded keys and checks that they work before anything uses them: the public key of
+// the private key must be one of the public keys, and a token signed with the private key must verify.
+func NewRS256KeySet(privateKey []byte, publicKeys ...[]byte) (*RS256KeySet, error) {
+
+	signingKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	signingKeyId, err := getRS256KeyId(&signingKey.PublicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keySet := &RS256KeySet{
+		signingKey:       signingKey,
+		signingKeyId:     signingKeyId,
+		verificationKeys: map[string]*rsa.PublicKey{},
+	}
+
+	for i, publicKey := range publicKeys {
+
+		verificationKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKey)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %d: %w", i+1, err)
+		}
+
+		keyId, err := getRS256KeyId(verificationKey)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keySet.verificationKeys[keyId] = verificationKey
+	}
+
+	if _, ok := keySet.verificationKeys[signingKeyId]; ok == false {
+		return nil, errors.New("no public key belongs to the private key")
+	}
+
+	jwtAuth := NewReloadableRS256Jwt(keySet)
+
+	token, err := jwtAuth.GenerateJwt(CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+
+	if err == nil {
+		_, err = jwtAuth.VerifyJwt(*token)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("the keys cannot sign and verify a token: %w", err)
+	}
+
+	return keySet, nil
+}
+
+// LoadRS256KeySet reads the PEM files of the private key and of the public keys.
+func LoadRS256KeySet(privateKeyPath string, publicKeyPaths ...string) (*RS256KeySet, error) {
+
+	privateKey, err := os.ReadFile(privateKeyPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys := make([][]byte, 0, len(publicKeyPaths))
+
+	for _, publicKeyPath := range publicKeyPaths {
+
+		publicKey, err := os.ReadFile(publicKeyPath)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Checked here as well to name the file.
+		if _, err := jwt.ParseRSAPublicKeyFromPEM(publicKey); err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", publicKeyPath, err)
+		}
+
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	return NewRS256KeySet(privateKey, publicKeys...)
+}
+
+// LoadRS256KeySetFromDirectory signs with the private key in the file signingKeyFile of directory and verifies with
+// the public keys in every file of directory matching publicKeyPattern, e.g. "*.pub".
+func LoadRS256KeySetFromDirectory(directory string, signingKeyFile string, publicKeyPattern string) (*RS256KeySet, error) {
+
+	publicKeyPaths, err := filepath.Glob(filepath.Join(directory, publicKeyPattern))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadRS256KeySet(filepath.Join(directory, signingKeyFile), publicKeyPaths...)
+}
+
+// ReloadableRS256Jwt signs and verifies the tokens with a key set that can be replaced while requests use it.
+type ReloadableRS256Jwt struct {
+	keySet *atomic.Pointer[RS256KeySet]
+}
+
+// SetKeySet makes the following tokens use keySet, the tokens being signed or verified keep the previous one.
+func (r ReloadableRS256Jwt) SetKeySet(keySet *RS256KeySet) {
+	r.keySet.Store(keySet)
+}
+
+func (r ReloadableRS256Jwt) KeySet() *RS256KeySet {
+	return r.keySet.Load()
+}
+
+func (r ReloadableRS256Jwt) GenerateJwt(claims CustomClaims) (*string, error) {
+
+	keySet := r.keySet.Load()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keySet.signingKeyId
+
+	tokenString, err := token.SignedString(keySet.signingKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenString, nil
+}
+
+func (r ReloadableRS256Jwt) VerifyJwt(tokenString string) (*CustomClaims, error) {
+
+	keySet := r.keySet.Load()
+
+	token, err := jwt.Parse(tokenString, func(jwtToken *jwt.Token) (interface{}, error) {
+
+		if _, ok := jwtToken.Method.(*jwt.SigningMethodRSA); !ok {
+
+			return nil, fmt.Errorf("unexpected method: %s", jwtToken.Header["alg"])
+		}
+
+		keyId, ok := jwtToken.Header["kid"].(string)
+
+		if ok == false {
+
+			// The tokens issued before they had a kid are tried with every key.
+			keys := jwt.VerificationKeySet{}
+
+			for _, verificationKey := range keySet.verificationKeys {
+				keys.Keys = append(keys.Keys, verificationKey)
+			}
+
+			return keys, nil
+		}
+
+		verificationKey, ok := keySet.verificationKeys[keyId]
+
+		if ok == false {
+			return nil, fmt.Errorf("unknown key: %s", keyId)
+		}
+
+		return verificationKey, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("validate: invalid")
+	}
+
+	userIdFloat, ok := claims["UserId"].(float64)
+
+	if ok == false {
+		return nil, fmt.Errorf("validate: invalid")
+	}
+
+	customClaims := CustomClaims{
+		UserId: int64(userIdFloat),
+	}
+
+	return &customClaims, nil
+}
+
+func NewReloadableRS256Jwt(keySet *RS256KeySet) ReloadableRS256Jwt {
+
+	r := ReloadableRS256Jwt{
+		keySet: &atomic.Pointer[RS256KeySet]{},
+	}
+
+	r.keySet.Store(keySet)
+
+	return r
+}
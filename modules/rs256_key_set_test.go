@@ -0,0 +1,202 @@
+package modules
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func generateRS256Key(t *testing.T) ([]byte, []byte) {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+}
+
+func TestNewRS256KeySet(t *testing.T) {
+
+	privateKey, publicKey := generateRS256Key(t)
+	_, otherPublicKey := generateRS256Key(t)
+
+	tests := []struct {
+		name       string
+		privateKey []byte
+		publicKeys [][]byte
+		wantErr    bool
+	}{
+		{
+			name:       "When the public key belongs to the private key, it will return the key set",
+			privateKey: privateKey,
+			publicKeys: [][]byte{otherPublicKey, publicKey},
+		},
+		{
+			name:       "When the private key is invalid, it will return error",
+			privateKey: []byte("a_invalid_private_key"),
+			publicKeys: [][]byte{publicKey},
+			wantErr:    true,
+		},
+		{
+			name:       "When a public key is invalid, it will return error",
+			privateKey: privateKey,
+			publicKeys: [][]byte{publicKey, []byte("a_invalid_public_key")},
+			wantErr:    true,
+		},
+		{
+			name:       "When no public key belongs to the private key, it will return error",
+			privateKey: privateKey,
+			publicKeys: [][]byte{otherPublicKey},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRS256KeySet(tt.privateKey, tt.publicKeys...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRS256KeySet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(got.VerificationKeyIds()) != len(tt.publicKeys) {
+				t.Errorf("NewRS256KeySet() verification keys = %v, want %d", got.VerificationKeyIds(), len(tt.publicKeys))
+			}
+		})
+	}
+}
+
+func TestReloadableRS256Jwt_VerifyJwt(t *testing.T) {
+
+	oldPrivateKey, oldPublicKey := generateRS256Key(t)
+	newPrivateKey, newPublicKey := generateRS256Key(t)
+
+	oldKeySet, err := NewRS256KeySet(oldPrivateKey, oldPublicKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		UserId:           123,
+	}
+
+	jwtAuth := NewReloadableRS256Jwt(oldKeySet)
+
+	oldToken, err := jwtAuth.GenerateJwt(claims)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenWithoutKeyId, err := NewRS256Jwt(oldPrivateKey, oldPublicKey).GenerateJwt(claims)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		publicKeys [][]byte
+		token      string
+		wantErr    bool
+	}{
+		{
+			name:       "When the key was rotated and the old public key is kept, it will verify the old token",
+			publicKeys: [][]byte{newPublicKey, oldPublicKey},
+			token:      *oldToken,
+		},
+		{
+			name:       "When the key was rotated and the old public key is removed, it will return error",
+			publicKeys: [][]byte{newPublicKey},
+			token:      *oldToken,
+			wantErr:    true,
+		},
+		{
+			name:       "When the token has no key id, it will verify it with any of the public keys",
+			publicKeys: [][]byte{newPublicKey, oldPublicKey},
+			token:      *tokenWithoutKeyId,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			newKeySet, err := NewRS256KeySet(newPrivateKey, tt.publicKeys...)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			jwtAuth.SetKeySet(newKeySet)
+
+			got, err := jwtAuth.VerifyJwt(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyJwt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.UserId != claims.UserId {
+				t.Errorf("VerifyJwt() user id = %d, want %d", got.UserId, claims.UserId)
+			}
+
+			newToken, err := jwtAuth.GenerateJwt(claims)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := jwtAuth.VerifyJwt(*newToken); err != nil {
+				t.Errorf("VerifyJwt() of a token of the new key error = %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadRS256KeySetFromDirectory(t *testing.T) {
+
+	privateKey, publicKey := generateRS256Key(t)
+	_, previousPublicKey := generateRS256Key(t)
+
+	directory := t.TempDir()
+
+	files := map[string][]byte{
+		"id_rsa":          privateKey,
+		"id_rsa.pub":      publicKey,
+		"previous.pub":    previousPublicKey,
+		"README.md":       []byte("not a key"),
+		"id_rsa.pub.orig": []byte("not a key either"),
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keySet, err := LoadRS256KeySetFromDirectory(directory, "id_rsa", "*.pub")
+
+	if err != nil {
+		t.Fatalf("LoadRS256KeySetFromDirectory() error = %v", err)
+	}
+
+	if got := len(keySet.VerificationKeyIds()); got != 2 {
+		t.Errorf("LoadRS256KeySetFromDirectory() verification keys = %d, want 2", got)
+	}
+
+	_, err = LoadRS256KeySetFromDirectory(t.TempDir(), "id_rsa", "*.pub")
+
+	if err == nil {
+		t.Errorf("LoadRS256KeySetFromDirectory() of an empty directory error = nil, want error")
+	}
+}
@@ -0,0 +1,56 @@
+package modules
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SmtpMailSender delivers mail through a plain SMTP server. Locally it is pointed at
+// the mailhog container from docker-compose, which accepts everything without auth.
+type SmtpMailSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func (s SmtpMailSender) Send(message MailMessage) error {
+
+	var auth smtp.Auth
+
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	address := net.JoinHostPort(s.host, s.port)
+
+	return smtp.SendMail(address, auth, s.from, []string{message.To}, buildMailContent(s.from, message))
+}
+
+func buildMailContent(from string, message MailMessage) []byte {
+
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	builder.WriteString(fmt.Sprintf("To: %s\r\n", message.To))
+	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", message.Subject))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(message.Body)
+
+	return []byte(builder.String())
+}
+
+func NewSmtpMailSender(host string, port string, username string, password string, from string) MailSenderInterface {
+	return SmtpMailSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
@@ -0,0 +1,28 @@
+package pojos
+
+import "time"
+
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditLog struct {
+	Id            int64                  `json:"id"`
+	ActorUserId   *int64                 `json:"actor_user_id"`
+	SubjectUserId *int64                 `json:"subject_user_id"`
+	Action        string                 `json:"action"`
+	Changes       map[string]AuditChange `json:"changes"`
+	RequestId     string                 `json:"request_id"`
+	IpAddress     string                 `json:"ip_address"`
+	PreviousHash  string                 `json:"previous_hash"`
+	Hash          string                 `json:"hash"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+type AuditLogs struct {
+	Data     []AuditLog `json:"data"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+	Total    int64      `json:"total"`
+}
@@ -0,0 +1,15 @@
+package pojos
+
+import "time"
+
+type DataExport struct {
+	Id            int64      `json:"id"`
+	Status        string     `json:"status"`
+	IsEncrypted   bool       `json:"is_encrypted"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	ExpiredAt     time.Time  `json:"expired_at"`
+	// DownloadUrl is only known when the export is requested, since only the hash of its token is stored.
+	DownloadUrl string `json:"download_url,omitempty"`
+}
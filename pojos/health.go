@@ -0,0 +1,15 @@
+package pojos
+
+type Health struct {
+	// Status is ok when every check passed.
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
+
+type HealthCheck struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+	// Error is empty when the check passed.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,20 @@
+package pojos
+
+import "time"
+
+type LoginEvent struct {
+	Id            int64     `json:"id"`
+	IsSuccess     bool      `json:"is_success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	Method        string    `json:"method"`
+	IpAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type LoginHistory struct {
+	Data     []LoginEvent `json:"data"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+	Total    int64        `json:"total"`
+}
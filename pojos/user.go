@@ -3,20 +3,34 @@ package pojos
 import "time"
 
 type User struct {
-	Id                int64     `json:"id"`
-	PhoneNumber       string    `json:"phone_number"`
-	FullName          string    `json:"full_name"`
-	LoginSuccessCount int64     `json:"login_success_count"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	Id                  int64      `json:"id"`
+	PhoneNumber         string     `json:"phone_number"`
+	FullName            string     `json:"full_name"`
+	Email               string     `json:"email,omitempty"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at,omitempty"`
+	Role                string     `json:"role"`
+	Status              string     `json:"status"`
+	StatusReason        string     `json:"status_reason,omitempty"`
+	StatusChangedAt     *time.Time `json:"status_changed_at,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	LoginSuccessCount   int64      `json:"login_success_count"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 type UserWithPassword struct {
-	Id                int64     `json:"id"`
-	PhoneNumber       string    `json:"phone_number"`
-	FullName          string    `json:"full_name"`
-	Password          string    `json:"password"`
-	LoginSuccessCount int64     `json:"login_success_count"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	Id                  int64      `json:"id"`
+	PhoneNumber         string     `json:"phone_number"`
+	FullName            string     `json:"full_name"`
+	Email               string     `json:"email,omitempty"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at,omitempty"`
+	Password            string     `json:"password"`
+	Role                string     `json:"role"`
+	Status              string     `json:"status"`
+	StatusReason        string     `json:"status_reason,omitempty"`
+	StatusChangedAt     *time.Time `json:"status_changed_at,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	LoginSuccessCount   int64      `json:"login_success_count"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
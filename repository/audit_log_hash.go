@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenesisAuditLogHash is the previous hash of the first entry of the audit log chain.
+const GenesisAuditLogHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// ComputeAuditLogHash hashes an audit log entry together with the hash of the entry before it,
+// so changing or removing any stored entry breaks every hash after it. Every field is prefixed with its
+// length, so moving text from one field to the next changes the hash.
+func ComputeAuditLogHash(previousHash string, input AppendAuditLogInput) string {
+
+	var encoded strings.Builder
+
+	for _, field := range getAuditLogHashFields(previousHash, input) {
+		fmt.Fprintf(&encoded, "%d:%s", len(field), field)
+	}
+
+	hashed := sha256.Sum256([]byte(encoded.String()))
+
+	return hex.EncodeToString(hashed[:])
+}
+
+func getAuditLogHashFields(previousHash string, input AppendAuditLogInput) []string {
+
+	return []string{
+		previousHash,
+		formatOptionalId(input.ActorUserId),
+		formatOptionalId(input.SubjectUserId),
+		input.Action,
+		input.Changes,
+		input.RequestId,
+		input.IpAddress,
+		input.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func formatOptionalId(id *int64) string {
+
+	if id == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(*id, 10)
+}
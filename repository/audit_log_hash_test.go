@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeAuditLogHash(t *testing.T) {
+
+	createdAt := time.Date(2024, 4, 18, 9, 50, 16, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    AppendAuditLogInput
+		other    AppendAuditLogInput
+		wantSame bool
+	}{
+		{
+			name:     "When the entries are equal, it will return the same hash",
+			input:    AppendAuditLogInput{Action: "login_success", RequestId: "request-id", CreatedAt: createdAt},
+			other:    AppendAuditLogInput{Action: "login_success", RequestId: "request-id", CreatedAt: createdAt},
+			wantSame: true,
+		},
+		{
+			name:     "When text moved from one field to the next across a separator, it will return another hash",
+			input:    AppendAuditLogInput{RequestId: "request-id\x1f103.10.10.1", IpAddress: "", CreatedAt: createdAt},
+			other:    AppendAuditLogInput{RequestId: "request-id", IpAddress: "103.10.10.1", CreatedAt: createdAt},
+			wantSame: false,
+		},
+		{
+			name:     "When text moved from one field to the next, it will return another hash",
+			input:    AppendAuditLogInput{Action: "login_success", Changes: "{}", CreatedAt: createdAt},
+			other:    AppendAuditLogInput{Action: "login_succes", Changes: "s{}", CreatedAt: createdAt},
+			wantSame: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeAuditLogHash(GenesisAuditLogHash, tt.input) == ComputeAuditLogHash(GenesisAuditLogHash, tt.other)
+			if got != tt.wantSame {
+				t.Errorf("ComputeAuditLogHash() same = %v, want %v", got, tt.wantSame)
+			}
+		})
+	}
+}
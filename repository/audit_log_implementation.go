@@ -0,0 +1,185 @@
+// This file contains the repository implementation of the audit log.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// auditLogAdvisoryLockKey serializes appends so every entry chains to the latest one.
+const auditLogAdvisoryLockKey = 7_261_001
+
+// AppendAuditLog holds the advisory lock until the transaction it runs in is over, so it comes last in a transaction:
+// the appends of every other transaction wait for it.
+func (r Repository) AppendAuditLog(ctx context.Context, input AppendAuditLogInput) (*AppendAuditLogOutput, error) {
+
+	// Postgres keeps microseconds, hash what will be read back later.
+	input.CreatedAt = input.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	var output *AppendAuditLogOutput
+
+	err := r.withinTransaction(ctx, TransactionOptions{}, func(txRepository Repository) error {
+
+		_, err := txRepository.tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1);`, auditLogAdvisoryLockKey)
+
+		if err != nil {
+			return err
+		}
+
+		previousHash := GenesisAuditLogHash
+
+		err = txRepository.tx.QueryRowContext(ctx, `SELECT hash FROM audit_logs ORDER BY id DESC LIMIT 1;`).Scan(&previousHash)
+
+		if err != nil && errors.Is(err, sql.ErrNoRows) == false {
+			return err
+		}
+
+		hash := ComputeAuditLogHash(previousHash, input)
+
+		query := `INSERT INTO audit_logs (actor_user_id, subject_user_id, action, changes, request_id, ip_address, previous_hash, hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id;`
+
+		var lastInsertId int64
+
+		err = txRepository.tx.QueryRowContext(ctx, query, optionalIdToNullInt64(input.ActorUserId), optionalIdToNullInt64(input.SubjectUserId),
+			input.Action, input.Changes, input.RequestId, input.IpAddress, previousHash, hash, input.CreatedAt).Scan(&lastInsertId)
+
+		if err != nil {
+			return err
+		}
+
+		if input.PersonalChanges != "" {
+
+			_, err = txRepository.tx.ExecContext(ctx, `INSERT INTO audit_log_personal_data (audit_log_id, changes, ip_address) VALUES ($1, $2, $3);`,
+				lastInsertId, input.PersonalChanges, input.PersonalIpAddress)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		output = &AppendAuditLogOutput{
+			Id:   lastInsertId,
+			Hash: hash,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// auditLogColumns selects an entry with its personal data, which is empty once the user was anonymized.
+const auditLogColumns = `id, actor_user_id, subject_user_id, action, audit_logs.changes, request_id, audit_logs.ip_address,
+	COALESCE(audit_log_personal_data.changes::text, ''), COALESCE(audit_log_personal_data.ip_address, ''), previous_hash, hash, created_at`
+
+const auditLogPersonalDataJoin = `LEFT JOIN audit_log_personal_data ON audit_log_personal_data.audit_log_id = audit_logs.id`
+
+func (r Repository) GetAuditLogs(ctx context.Context, input GetAuditLogsInput) (*GetAuditLogsOutput, error) {
+
+	var conditions []string
+	var args []interface{}
+
+	if input.ActorUserId != nil {
+		args = append(args, *input.ActorUserId)
+		conditions = append(conditions, fmt.Sprintf("actor_user_id = $%d", len(args)))
+	}
+
+	if input.SubjectUserId != nil {
+		args = append(args, *input.SubjectUserId)
+		conditions = append(conditions, fmt.Sprintf("subject_user_id = $%d", len(args)))
+	}
+
+	if input.Action != "" {
+		args = append(args, input.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+
+	where := ""
+
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	output := &GetAuditLogsOutput{
+		AuditLogs: []AuditLogOutput{},
+	}
+
+	err := r.executor().QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM audit_logs %s;`, where), args...).Scan(&output.Total)
+
+	if err != nil {
+		return nil, err
+	}
+
+	args = append(args, input.Limit, input.Offset)
+
+	query := fmt.Sprintf(`SELECT %s
+		FROM audit_logs %s %s ORDER BY id DESC LIMIT $%d OFFSET $%d;`, auditLogColumns, auditLogPersonalDataJoin, where, len(args)-1, len(args))
+
+	auditLogs, err := r.queryAuditLogs(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output.AuditLogs = auditLogs
+
+	return output, nil
+}
+
+func (r Repository) GetAuditLogsAfterId(ctx context.Context, input GetAuditLogsAfterIdInput) ([]AuditLogOutput, error) {
+
+	query := fmt.Sprintf(`SELECT %s
+		FROM audit_logs %s WHERE id > $1 ORDER BY id ASC LIMIT $2;`, auditLogColumns, auditLogPersonalDataJoin)
+
+	return r.queryAuditLogs(ctx, query, input.AfterId, input.Limit)
+}
+
+func (r Repository) queryAuditLogs(ctx context.Context, query string, args ...interface{}) ([]AuditLogOutput, error) {
+
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	auditLogs := []AuditLogOutput{}
+
+	for rows.Next() {
+
+		auditLog := AuditLogOutput{}
+
+		var actorUserId sql.NullInt64
+		var subjectUserId sql.NullInt64
+
+		err = rows.Scan(&auditLog.Id, &actorUserId, &subjectUserId, &auditLog.Action, &auditLog.Changes, &auditLog.RequestId,
+			&auditLog.IpAddress, &auditLog.PersonalChanges, &auditLog.PersonalIpAddress, &auditLog.PreviousHash, &auditLog.Hash, &auditLog.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		auditLog.ActorUserId = nullInt64ToPointer(actorUserId)
+		auditLog.SubjectUserId = nullInt64ToPointer(subjectUserId)
+
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return auditLogs, nil
+}
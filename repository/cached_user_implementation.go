@@ -0,0 +1,348 @@
+// This file contains the in-process cache of the users read by id.
+package repository
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachedUserRepository keeps the users read by GetById in an LRU cache for a TTL. The writes of this instance
+// invalidate the user right away, the writes of other instances arrive through a UserChangeListener.
+type CachedUserRepository struct {
+	UserRepositoryInterface
+
+	cache *userCache
+	// pending is set on the copy handed to a WithinTransaction callback, what the callback writes is invalidated after it.
+	pending *pendingInvalidations
+}
+
+type pendingInvalidations struct {
+	ids     []int64
+	isPurge bool
+}
+
+type NewCachedUserRepositoryOptions struct {
+	Repository UserRepositoryInterface
+	// Capacity is the number of users kept, the least recently used one is evicted first.
+	Capacity int
+	TTL      time.Duration
+}
+
+// GetById reads through the cache, the misses are read from the primary. In a transaction it always reads the database.
+func (r CachedUserRepository) GetById(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
+
+	if r.pending != nil {
+		return r.UserRepositoryInterface.GetById(ctx, input)
+	}
+
+	if user, ok := r.cache.get(input.Id); ok {
+		return user, nil
+	}
+
+	generation := r.cache.getGeneration()
+
+	// A lagging replica may still return the user as it was before an invalidation, e.g. active after a suspension,
+	// and the cache would keep that for the whole TTL. The generation only catches invalidations during the read.
+	user, err := r.UserRepositoryInterface.GetById(WithPrimary(ctx), input)
+
+	if err != nil || user == nil {
+		return user, err
+	}
+
+	r.cache.put(input.Id, user, generation)
+
+	return user, nil
+}
+
+func (r CachedUserRepository) Update(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error) {
+
+	defer r.invalidate(input.Id)
+
+	return r.UserRepositoryInterface.Update(ctx, input)
+}
+
+func (r CachedUserRepository) IncrementLoginSuccessCount(ctx context.Context, input IncrementLoginSuccessCountInput) (*UpdateUserOutput, error) {
+
+	defer r.invalidate(input.Id)
+
+	return r.UserRepositoryInterface.IncrementLoginSuccessCount(ctx, input)
+}
+
+func (r CachedUserRepository) UpdateEmail(ctx context.Context, input UpdateUserEmailInput) (*UpdateUserOutput, error) {
+
+	defer r.invalidate(input.Id)
+
+	return r.UserRepositoryInterface.UpdateEmail(ctx, input)
+}
+
+func (r CachedUserRepository) UpdateRole(ctx context.Context, input UpdateUserRoleInput) (*UpdateUserOutput, error) {
+
+	defer r.invalidate(input.Id)
+
+	return r.UserRepositoryInterface.UpdateRole(ctx, input)
+}
+
+func (r CachedUserRepository) UpdateStatus(ctx context.Context, input UpdateUserStatusInput) (*UpdateUserOutput, error) {
+
+	defer r.invalidate(input.Id)
+
+	return r.UserRepositoryInterface.UpdateStatus(ctx, input)
+}
+
+func (r CachedUserRepository) ScheduleDeletion(ctx context.Context, input ScheduleUserDeletionInput) (*UpdateUserOutput, error) {
+
+	defer r.invalidate(input.Id)
+
+	return r.UserRepositoryInterface.ScheduleDeletion(ctx, input)
+}
+
+func (r CachedUserRepository) CancelDeletion(ctx context.Context, input CancelUserDeletionInput) (*UpdateUserOutput, error) {
+
+	defer r.invalidate(input.Id)
+
+	return r.UserRepositoryInterface.CancelDeletion(ctx, input)
+}
+
+func (r CachedUserRepository) AnonymizeUser(ctx context.Context, input AnonymizeUserInput) (*AnonymizeUserOutput, error) {
+
+	defer r.invalidate(input.Id)
+
+	return r.UserRepositoryInterface.AnonymizeUser(ctx, input)
+}
+
+// VerifyEmail clears the whole cache, the input does not tell which user is verified.
+func (r CachedUserRepository) VerifyEmail(ctx context.Context, input VerifyEmailInput) (*VerifyEmailOutput, error) {
+
+	defer r.purge()
+
+	return r.UserRepositoryInterface.VerifyEmail(ctx, input)
+}
+
+// WithinTransaction hands fn a repository that bypasses the cache, the users it wrote are invalidated
+// once the transaction is over, whether it was committed or not.
+func (r CachedUserRepository) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+
+	if r.pending != nil {
+		return r.UserRepositoryInterface.WithinTransaction(ctx, opts, fn)
+	}
+
+	pending := &pendingInvalidations{}
+
+	defer func() {
+
+		if pending.isPurge {
+			r.cache.purge()
+		}
+
+		for _, id := range pending.ids {
+			r.cache.remove(id)
+		}
+	}()
+
+	return r.UserRepositoryInterface.WithinTransaction(ctx, opts, func(txRepository TransactionRepositoryInterface) error {
+
+		return fn(cachedTransactionRepository{
+			CachedUserRepository: CachedUserRepository{
+				UserRepositoryInterface: txRepository,
+				cache:                   r.cache,
+				pending:                 pending,
+			},
+			LoginEventRepositoryInterface: txRepository,
+			AuditLogRepositoryInterface:   txRepository,
+			DataExportRepositoryInterface: txRepository,
+		})
+	})
+}
+
+// cachedTransactionRepository is the repository handed to the callback of CachedUserRepository.WithinTransaction,
+// only the users go through the cache.
+type cachedTransactionRepository struct {
+	CachedUserRepository
+	LoginEventRepositoryInterface
+	AuditLogRepositoryInterface
+	DataExportRepositoryInterface
+}
+
+func (r cachedTransactionRepository) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+	return r.CachedUserRepository.WithinTransaction(ctx, opts, fn)
+}
+
+// Invalidate drops the cached user, e.g. when another instance changed it.
+func (r CachedUserRepository) Invalidate(id int64) {
+	r.cache.remove(id)
+}
+
+// Purge drops every cached user, e.g. when changes of other instances may have been missed.
+func (r CachedUserRepository) Purge() {
+	r.cache.purge()
+}
+
+func (r CachedUserRepository) GetUserCacheStats() UserCacheStatsOutput {
+	return r.cache.getStats()
+}
+
+func (r CachedUserRepository) invalidate(id int64) {
+
+	if r.pending != nil {
+		r.pending.ids = append(r.pending.ids, id)
+		return
+	}
+
+	r.cache.remove(id)
+}
+
+func (r CachedUserRepository) purge() {
+
+	if r.pending != nil {
+		r.pending.isPurge = true
+		return
+	}
+
+	r.cache.purge()
+}
+
+// userCache is an LRU cache whose entries expire after the TTL.
+type userCache struct {
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mutex   sync.Mutex
+	entries map[int64]*list.Element
+	// order has the most recently used entry at the front.
+	order *list.List
+	// generation changes with every invalidation, a read that started before one does not store what it read.
+	generation uint64
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+type userCacheEntry struct {
+	id        int64
+	user      GetUserByIdOutput
+	expiresAt time.Time
+}
+
+func (c *userCache) get(id int64) (*GetUserByIdOutput, bool) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[id]
+
+	if ok == false {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	entry := element.Value.(*userCacheEntry)
+
+	if c.now().After(entry.expiresAt) {
+		c.removeElement(element)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	c.hits.Add(1)
+
+	// The caller gets its own copy, changing it does not change the cache.
+	user := entry.user
+
+	return &user, true
+}
+
+func (c *userCache) getGeneration() uint64 {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// put stores the user read when the cache was at generation, unless the cache was invalidated meanwhile.
+func (c *userCache) put(id int64, user *GetUserByIdOutput, generation uint64) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if element, ok := c.entries[id]; ok {
+		c.removeElement(element)
+	}
+
+	c.entries[id] = c.order.PushFront(&userCacheEntry{
+		id:        id,
+		user:      *user,
+		expiresAt: c.now().Add(c.ttl),
+	})
+
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *userCache) remove(id int64) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+
+	if element, ok := c.entries[id]; ok {
+		c.removeElement(element)
+	}
+}
+
+func (c *userCache) purge() {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	c.entries = map[int64]*list.Element{}
+	c.order.Init()
+}
+
+func (c *userCache) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*userCacheEntry).id)
+}
+
+func (c *userCache) getStats() UserCacheStatsOutput {
+
+	c.mutex.Lock()
+	size := c.order.Len()
+	c.mutex.Unlock()
+
+	return UserCacheStatsOutput{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+		Capacity:  c.capacity,
+	}
+}
+
+func NewCachedUserRepository(opts NewCachedUserRepositoryOptions) *CachedUserRepository {
+
+	return &CachedUserRepository{
+		UserRepositoryInterface: opts.Repository,
+		cache: &userCache{
+			capacity: opts.Capacity,
+			ttl:      opts.TTL,
+			now:      time.Now,
+			entries:  map[int64]*list.Element{},
+			order:    list.New(),
+		},
+	}
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestCachedUserRepository_GetById(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		// act runs between a first and a second GetById of user 1.
+		act           func(r *CachedUserRepository, now *time.Time)
+		mock          func(userRepository *MockTransactionRepositoryInterface)
+		wantDbReads   int
+		wantHits      int64
+		wantEvictions int64
+	}{
+		{
+			name:        "When the user was read before, it will return it from the cache",
+			act:         func(r *CachedUserRepository, now *time.Time) {},
+			wantDbReads: 1,
+			wantHits:    1,
+		},
+		{
+			name: "When the TTL is over, it will read the user again",
+			act: func(r *CachedUserRepository, now *time.Time) {
+				*now = now.Add(2 * time.Minute)
+			},
+			wantDbReads: 2,
+			wantHits:    0,
+		},
+		{
+			name: "When the user is updated, it will read the user again",
+			act: func(r *CachedUserRepository, now *time.Time) {
+				r.Update(ctx, UpdateUserInput{Id: 1, Fields: []string{UserFieldFullName}, FullName: "Budi"})
+			},
+			mock: func(userRepository *MockTransactionRepositoryInterface) {
+				userRepository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&UpdateUserOutput{IsSuccessUpdate: true}, nil)
+			},
+			wantDbReads: 2,
+			wantHits:    0,
+		},
+		{
+			name: "When the user is updated in a transaction, it will read the user again after the transaction",
+			act: func(r *CachedUserRepository, now *time.Time) {
+				r.WithinTransaction(ctx, TransactionOptions{}, func(txRepository TransactionRepositoryInterface) error {
+					_, err := txRepository.IncrementLoginSuccessCount(ctx, IncrementLoginSuccessCountInput{Id: 1})
+					return err
+				})
+			},
+			mock: func(userRepository *MockTransactionRepositoryInterface) {
+				userRepository.EXPECT().WithinTransaction(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(RunTransactionDirectly(userRepository))
+				userRepository.EXPECT().IncrementLoginSuccessCount(gomock.Any(), gomock.Any()).Return(&UpdateUserOutput{IsSuccessUpdate: true}, nil)
+			},
+			wantDbReads: 2,
+			wantHits:    0,
+		},
+		{
+			name: "When another instance changed the user, it will read the user again",
+			act: func(r *CachedUserRepository, now *time.Time) {
+				r.Invalidate(1)
+			},
+			wantDbReads: 2,
+			wantHits:    0,
+		},
+		{
+			name: "When more users than the capacity were read since, it will have evicted the user",
+			act: func(r *CachedUserRepository, now *time.Time) {
+				r.GetById(ctx, GetUserByIdInput{Id: 2})
+				r.GetById(ctx, GetUserByIdInput{Id: 3})
+			},
+			wantDbReads:   4,
+			wantHits:      0,
+			wantEvictions: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			userRepository := NewMockTransactionRepositoryInterface(mockCtrl)
+			dbReads := 0
+			userRepository.EXPECT().GetById(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
+				dbReads++
+				return &GetUserByIdOutput{Id: input.Id}, nil
+			}).AnyTimes()
+			if tt.mock != nil {
+				tt.mock(userRepository)
+			}
+			r := NewCachedUserRepository(NewCachedUserRepositoryOptions{
+				Repository: userRepository,
+				Capacity:   2,
+				TTL:        time.Minute,
+			})
+			now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+			r.cache.now = func() time.Time {
+				return now
+			}
+			if _, err := r.GetById(ctx, GetUserByIdInput{Id: 1}); err != nil {
+				t.Fatalf("GetById() error = %v", err)
+			}
+			tt.act(r, &now)
+			got, err := r.GetById(ctx, GetUserByIdInput{Id: 1})
+			if err != nil || got.Id != 1 {
+				t.Fatalf("GetById() = %v, error = %v", got, err)
+			}
+			if dbReads != tt.wantDbReads {
+				t.Errorf("GetById() database reads = %v, want %v", dbReads, tt.wantDbReads)
+			}
+			stats := r.GetUserCacheStats()
+			if stats.Hits != tt.wantHits || stats.Evictions != tt.wantEvictions {
+				t.Errorf("GetUserCacheStats() = %+v, want %d hits and %d evictions", stats, tt.wantHits, tt.wantEvictions)
+			}
+		})
+	}
+}
+
+func TestCachedUserRepository_GetById_InvalidatedWhileReading(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	userRepository := NewMockUserRepositoryInterface(mockCtrl)
+	r := NewCachedUserRepository(NewCachedUserRepositoryOptions{
+		Repository: userRepository,
+		Capacity:   2,
+		TTL:        time.Minute,
+	})
+	// The user changes while the first read is on its way, what that read returns is already stale.
+	userRepository.EXPECT().GetById(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
+		r.Invalidate(input.Id)
+		return &GetUserByIdOutput{Id: input.Id, FullName: "stale"}, nil
+	})
+	userRepository.EXPECT().GetById(gomock.Any(), gomock.Any()).Return(&GetUserByIdOutput{Id: 1, FullName: "fresh"}, nil)
+	r.GetById(ctx, GetUserByIdInput{Id: 1})
+	got, err := r.GetById(ctx, GetUserByIdInput{Id: 1})
+	if err != nil || got.FullName != "fresh" {
+		t.Errorf("GetById() = %v, error = %v, want the fresh user", got, err)
+	}
+}
+
+func TestCachedUserRepository_GetById_ReadsMissesFromPrimary(t *testing.T) {
+	ctx := WithReadYourWrites(context.Background())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	userRepository := NewMockUserRepositoryInterface(mockCtrl)
+	r := NewCachedUserRepository(NewCachedUserRepositoryOptions{
+		Repository: userRepository,
+		Capacity:   2,
+		TTL:        time.Minute,
+	})
+	userRepository.EXPECT().GetById(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
+		if IsPinnedToPrimary(ctx) == false {
+			t.Errorf("GetById() read the miss from a replica, want the primary")
+		}
+		return &GetUserByIdOutput{Id: input.Id}, nil
+	})
+	if _, err := r.GetById(ctx, GetUserByIdInput{Id: 1}); err != nil {
+		t.Fatalf("GetById() error = %v", err)
+	}
+	if IsPinnedToPrimary(ctx) {
+		t.Errorf("GetById() pinned the later reads of the request to the primary")
+	}
+}
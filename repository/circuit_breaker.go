@@ -0,0 +1,131 @@
+// This file contains the circuit breaker that fails fast while the database is unreachable.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	CircuitBreakerClosed   = "closed"
+	CircuitBreakerOpen     = "open"
+	CircuitBreakerHalfOpen = "half_open"
+)
+
+// DatabaseUnavailableError is returned without calling the database while the circuit breaker is open.
+type DatabaseUnavailableError struct {
+	RetryAfter time.Duration
+}
+
+func (e *DatabaseUnavailableError) Error() string {
+	return fmt.Sprintf("database is unavailable, retry after %s", e.RetryAfter)
+}
+
+// RetryAfterSeconds is RetryAfter rounded up to whole seconds, as the Retry-After header needs it.
+func (e *DatabaseUnavailableError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// CircuitBreaker opens after FailureThreshold calls in a row found the database unreachable. While it is open
+// every call fails fast, after OpenDuration a single call is let through to probe the database: the breaker
+// closes when the probe reaches the database and opens again when it does not.
+type CircuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+	now              func() time.Time
+
+	mutex               sync.Mutex
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	isProbing           bool
+}
+
+type NewCircuitBreakerOptions struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+}
+
+// allow returns a DatabaseUnavailableError when the call must not reach the database, and whether the call is the
+// probe of a half open breaker.
+func (c *CircuitBreaker) allow() (bool, error) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.state == CircuitBreakerClosed {
+		return false, nil
+	}
+
+	retryAfter := c.openedAt.Add(c.openDuration).Sub(c.now())
+
+	if retryAfter > 0 || c.isProbing {
+
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+
+		return false, &DatabaseUnavailableError{RetryAfter: retryAfter}
+	}
+
+	c.state = CircuitBreakerHalfOpen
+	c.isProbing = true
+
+	return true, nil
+}
+
+// record counts the outcome of a call allow let through, isProbe is what allow returned for it. Errors of the
+// database itself, e.g. a unique violation, show that it is reachable. Once the breaker is not closed only the probe
+// changes it, the calls let through before it opened end without effect.
+func (c *CircuitBreaker) record(isProbe bool, err error) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if isProbe {
+		c.isProbing = false
+	} else if c.state != CircuitBreakerClosed {
+		return
+	}
+
+	// A cancelled call tells nothing about the database, a half open breaker lets the next call probe instead.
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+
+	if isDatabaseUnreachableError(err) == false {
+		c.state = CircuitBreakerClosed
+		c.consecutiveFailures = 0
+		return
+	}
+
+	c.consecutiveFailures++
+
+	if c.state == CircuitBreakerHalfOpen || c.consecutiveFailures >= c.failureThreshold {
+		c.state = CircuitBreakerOpen
+		c.openedAt = c.now()
+	}
+}
+
+// State is one of CircuitBreakerClosed, CircuitBreakerOpen and CircuitBreakerHalfOpen.
+func (c *CircuitBreaker) State() string {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.state
+}
+
+func NewCircuitBreaker(opts NewCircuitBreakerOptions) *CircuitBreaker {
+
+	return &CircuitBreaker{
+		failureThreshold: opts.FailureThreshold,
+		openDuration:     opts.OpenDuration,
+		now:              time.Now,
+		state:            CircuitBreakerClosed,
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	unreachableErr := syscall.ECONNREFUSED
+	type step struct {
+		// elapsed moves the clock of the breaker forward before the call.
+		elapsed        time.Duration
+		err            error
+		wantAllowed    bool
+		wantRetryAfter time.Duration
+		wantState      string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "When the database is unreachable as often as the threshold, it will open and fail fast",
+			steps: []step{
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerClosed},
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerOpen},
+				{elapsed: 4 * time.Second, wantAllowed: false, wantRetryAfter: 6 * time.Second, wantState: CircuitBreakerOpen},
+			},
+		},
+		{
+			name: "When a call reaches the database in between, it will start counting again",
+			steps: []step{
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerClosed},
+				{err: errors.New("unique violation"), wantAllowed: true, wantState: CircuitBreakerClosed},
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerClosed},
+			},
+		},
+		{
+			name: "When the probe after the open duration reaches the database, it will close",
+			steps: []step{
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerClosed},
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerOpen},
+				{elapsed: 10 * time.Second, err: nil, wantAllowed: true, wantState: CircuitBreakerClosed},
+				{err: nil, wantAllowed: true, wantState: CircuitBreakerClosed},
+			},
+		},
+		{
+			name: "When the probe after the open duration fails, it will open again for the whole duration",
+			steps: []step{
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerClosed},
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerOpen},
+				{elapsed: 10 * time.Second, err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerOpen},
+				{elapsed: time.Second, wantAllowed: false, wantRetryAfter: 9 * time.Second, wantState: CircuitBreakerOpen},
+			},
+		},
+		{
+			name: "When the probe is cancelled, it will let the next call probe",
+			steps: []step{
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerClosed},
+				{err: unreachableErr, wantAllowed: true, wantState: CircuitBreakerOpen},
+				{elapsed: 10 * time.Second, err: context.Canceled, wantAllowed: true, wantState: CircuitBreakerHalfOpen},
+				{err: nil, wantAllowed: true, wantState: CircuitBreakerClosed},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+			c := NewCircuitBreaker(NewCircuitBreakerOptions{
+				FailureThreshold: 2,
+				OpenDuration:     10 * time.Second,
+			})
+			c.now = func() time.Time {
+				return now
+			}
+			for i, step := range tt.steps {
+				now = now.Add(step.elapsed)
+				isProbe, err := c.allow()
+				if (err == nil) != step.wantAllowed {
+					t.Fatalf("step %d: allow() error = %v, wantAllowed %v", i, err, step.wantAllowed)
+				}
+				if err == nil {
+					c.record(isProbe, step.err)
+				} else if got := err.(*DatabaseUnavailableError).RetryAfter; got != step.wantRetryAfter {
+					t.Errorf("step %d: allow() RetryAfter = %v, want %v", i, got, step.wantRetryAfter)
+				}
+				if got := c.State(); got != step.wantState {
+					t.Errorf("step %d: State() = %v, want %v", i, got, step.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_record(t *testing.T) {
+	unreachableErr := syscall.ECONNREFUSED
+	tests := []struct {
+		name string
+		// lateErr is the outcome of a call let through before the breaker opened, it ends while the probe runs.
+		lateErr        error
+		wantProbeState string
+	}{
+		{
+			name:           "When a call from before the breaker opened reaches the database during the probe, it will keep probing",
+			lateErr:        nil,
+			wantProbeState: CircuitBreakerHalfOpen,
+		},
+		{
+			name:           "When a call from before the breaker opened fails during the probe, it will keep probing",
+			lateErr:        unreachableErr,
+			wantProbeState: CircuitBreakerHalfOpen,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+			c := NewCircuitBreaker(NewCircuitBreakerOptions{
+				FailureThreshold: 1,
+				OpenDuration:     10 * time.Second,
+			})
+			c.now = func() time.Time {
+				return now
+			}
+			isLateProbe, _ := c.allow()
+			isProbe, _ := c.allow()
+			c.record(isProbe, unreachableErr)
+			now = now.Add(10 * time.Second)
+			isProbe, err := c.allow()
+			if err != nil || isProbe == false {
+				t.Fatalf("allow() of the probe isProbe = %v, error = %v", isProbe, err)
+			}
+			c.record(isLateProbe, tt.lateErr)
+			if got := c.State(); got != tt.wantProbeState {
+				t.Errorf("State() after the late call = %v, want %v", got, tt.wantProbeState)
+			}
+			if _, err := c.allow(); err == nil {
+				t.Errorf("allow() while the probe runs error = nil, want DatabaseUnavailableError")
+			}
+			c.record(isProbe, nil)
+			if got := c.State(); got != CircuitBreakerClosed {
+				t.Errorf("State() after the probe = %v, want %v", got, CircuitBreakerClosed)
+			}
+		})
+	}
+}
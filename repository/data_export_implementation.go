@@ -0,0 +1,244 @@
+// This file contains the repository implementation of the personal data exports.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const dataExportColumns = `id, user_id, status, is_encrypted, file_name, failure_reason, expired_at, completed_at, created_at`
+
+// InsertDataExport returns a UniqueViolationError on UniqueFieldPendingDataExport while another export of the user is
+// pending or processing.
+func (r Repository) InsertDataExport(ctx context.Context, input InsertDataExportInput) (*InsertDataExportOutput, error) {
+
+	var lastInsertId int64
+
+	query := `INSERT INTO data_exports (user_id, is_encrypted, download_token_hash, expired_at) VALUES ($1, $2, $3, $4) RETURNING id;`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryStatement.QueryRowContext(ctx, input.UserId, input.IsEncrypted, input.DownloadTokenHash, input.ExpiredAt).Scan(&lastInsertId)
+
+	if err != nil {
+		return nil, translateUniqueViolation(err)
+	}
+
+	output := &InsertDataExportOutput{
+		Id: lastInsertId,
+	}
+
+	return output, nil
+}
+
+// GetDataExportById only returns the export when it belongs to the given user.
+func (r Repository) GetDataExportById(ctx context.Context, input GetDataExportByIdInput) (*DataExportOutput, error) {
+
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = $1 AND user_id = $2;`
+
+	return r.queryDataExport(ctx, query, input.Id, input.UserId)
+}
+
+func (r Repository) GetDataExportByTokenHash(ctx context.Context, input GetDataExportByTokenHashInput) (*DataExportOutput, error) {
+
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE download_token_hash = $1;`
+
+	return r.queryDataExport(ctx, query, input.DownloadTokenHash)
+}
+
+// UpdateDataExportStatus only updates an export that still has input.FromStatus and did not expire.
+func (r Repository) UpdateDataExportStatus(ctx context.Context, input UpdateDataExportStatusInput) (*UpdateDataExportOutput, error) {
+
+	query := `UPDATE data_exports SET status = $1, file_name = $2, failure_reason = $3,
+		completed_at = CASE WHEN $4 THEN now() ELSE completed_at END WHERE id = $5 AND status = $6 AND expired_at > now();`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.Status, stringToNullString(input.FileName),
+		stringToNullString(input.FailureReason), input.Status == "completed", input.Id, input.FromStatus)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateDataExportOutput{
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+// GetExpiredDataExports returns the expired exports whose archive is still stored, the longest expired first.
+func (r Repository) GetExpiredDataExports(ctx context.Context, input GetExpiredDataExportsInput) (*GetDataExportsOutput, error) {
+
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE file_name IS NOT NULL AND expired_at <= now()
+		ORDER BY expired_at LIMIT $1;`
+
+	return r.queryDataExports(ctx, query, input.Limit)
+}
+
+// ClearDataExportFile forgets the archive of an export once it is deleted.
+func (r Repository) ClearDataExportFile(ctx context.Context, input ClearDataExportFileInput) (*UpdateDataExportOutput, error) {
+
+	query := `UPDATE data_exports SET file_name = NULL WHERE id = $1 AND file_name IS NOT NULL;`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateDataExportOutput{
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+// FailStaleDataExports marks the exports failed that are still pending or processing although their build stopped,
+// e.g. because the instance building them was restarted.
+func (r Repository) FailStaleDataExports(ctx context.Context, input FailStaleDataExportsInput) (*FailStaleDataExportsOutput, error) {
+
+	query := `UPDATE data_exports SET status = 'failed', failure_reason = $1
+		WHERE status IN ('pending', 'processing') AND created_at < $2;`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.FailureReason, input.CreatedBefore)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &FailStaleDataExportsOutput{
+		FailedCount: rowsAffected,
+	}
+
+	return output, nil
+}
+
+func (r Repository) queryDataExport(ctx context.Context, query string, args ...interface{}) (*DataExportOutput, error) {
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := scanDataExport(queryStatement.QueryRowContext(ctx, args...).Scan)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r Repository) queryDataExports(ctx context.Context, query string, args ...interface{}) (*GetDataExportsOutput, error) {
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queryStatement.QueryContext(ctx, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanDataExports(rows)
+}
+
+// scanDataExport reads the dataExportColumns of a row.
+func scanDataExport(scan func(dest ...interface{}) error) (*DataExportOutput, error) {
+
+	result := DataExportOutput{}
+
+	var fileName sql.NullString
+	var failureReason sql.NullString
+	var completedAt sql.NullTime
+
+	err := scan(&result.Id, &result.UserId, &result.Status, &result.IsEncrypted, &fileName, &failureReason,
+		&result.ExpiredAt, &completedAt, &result.CreatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.FileName = fileName.String
+	result.FailureReason = failureReason.String
+	result.CompletedAt = nullTimeToPointer(completedAt)
+
+	return &result, nil
+}
+
+func scanDataExports(rows *sql.Rows) (*GetDataExportsOutput, error) {
+
+	defer rows.Close()
+
+	output := &GetDataExportsOutput{
+		DataExports: []DataExportOutput{},
+	}
+
+	for rows.Next() {
+
+		dataExport, err := scanDataExport(rows.Scan)
+
+		if err != nil {
+			return nil, err
+		}
+
+		output.DataExports = append(output.DataExports, *dataExport)
+	}
+
+	err := rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+type dataExportContractRepository interface {
+	UserRepositoryInterface
+	DataExportRepositoryInterface
+}
+
+func TestMemoryRepository_DataExportRepositoryContract(t *testing.T) {
+
+	runDataExportRepositoryContract(t, func(t *testing.T) dataExportContractRepository {
+		return NewMemoryRepository()
+	})
+}
+
+func TestRepository_DataExportRepositoryContract(t *testing.T) {
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	runDataExportRepositoryContract(t, func(t *testing.T) dataExportContractRepository {
+		return openTestRepository(t, dsn)
+	})
+}
+
+func TestSqliteRepository_DataExportRepositoryContract(t *testing.T) {
+
+	runDataExportRepositoryContract(t, func(t *testing.T) dataExportContractRepository {
+		return openTestSqliteRepository(t)
+	})
+}
+
+// runDataExportRepositoryContract checks the cleanup of the exports every implementation must share.
+func runDataExportRepositoryContract(t *testing.T, newRepository func(t *testing.T) dataExportContractRepository) {
+
+	ctx := context.Background()
+
+	insertUser := func(t *testing.T, repo dataExportContractRepository) int64 {
+
+		output, err := repo.Insert(ctx, InsertUserInput{
+			PhoneNumber: newContractPhoneNumber(),
+			FullName:    "Contract User",
+			Password:    "hashed-password",
+		})
+
+		if err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+
+		return output.Id
+	}
+
+	insertExport := func(t *testing.T, repo dataExportContractRepository, userId int64, expiredAt time.Time, fileName string) int64 {
+
+		output, err := repo.InsertDataExport(ctx, InsertDataExportInput{
+			UserId:            userId,
+			DownloadTokenHash: fmt.Sprintf("%d-%d", userId, time.Now().UnixNano()),
+			ExpiredAt:         expiredAt,
+		})
+
+		if err != nil {
+			t.Fatalf("InsertDataExport() error = %v", err)
+		}
+
+		if fileName == "" {
+			return output.Id
+		}
+
+		for _, update := range []UpdateDataExportStatusInput{
+			{Id: output.Id, FromStatus: "pending", Status: "processing"},
+			{Id: output.Id, FromStatus: "processing", Status: "completed", FileName: fileName},
+		} {
+
+			updated, err := repo.UpdateDataExportStatus(ctx, update)
+
+			if err != nil || updated.IsSuccessUpdate == false {
+				t.Fatalf("UpdateDataExportStatus() output = %v, error = %v", updated, err)
+			}
+		}
+
+		return output.Id
+	}
+
+	// anonymizeUser is the only way to expire an export before its expiry, the completed exports are inserted unexpired.
+	anonymizeUser := func(t *testing.T, repo dataExportContractRepository, userId int64) {
+
+		scheduled, err := repo.ScheduleDeletion(ctx, ScheduleUserDeletionInput{Id: userId, Reason: "leaving", GracePeriod: 0})
+
+		if err != nil || scheduled.IsSuccessUpdate == false {
+			t.Fatalf("ScheduleDeletion() output = %v, error = %v", scheduled, err)
+		}
+
+		anonymized, err := repo.AnonymizeUser(ctx, AnonymizeUserInput{Id: userId, FromStatus: "pending_deletion", FullName: "Deleted User", Reason: "deleted"})
+
+		if err != nil || anonymized.IsAnonymized == false {
+			t.Fatalf("AnonymizeUser() output = %v, error = %v", anonymized, err)
+		}
+	}
+
+	t.Run("When an export failed or expired, its status will not be updated", func(t *testing.T) {
+		repo := newRepository(t)
+
+		userId := insertUser(t, repo)
+		expiredId := insertExport(t, repo, userId, time.Now().Add(-time.Hour), "")
+
+		isLateUpdated := func(userId int64, exportId int64, wantStatus string) {
+
+			updated, err := repo.UpdateDataExportStatus(ctx, UpdateDataExportStatusInput{
+				Id:         exportId,
+				FromStatus: "pending",
+				Status:     "completed",
+				FileName:   "late.zip",
+			})
+
+			if err != nil || updated.IsSuccessUpdate {
+				t.Errorf("UpdateDataExportStatus() of export %d output = %v, error = %v", exportId, updated, err)
+			}
+
+			dataExport, _ := repo.GetDataExportById(ctx, GetDataExportByIdInput{Id: exportId, UserId: userId})
+
+			if dataExport.Status != wantStatus || dataExport.FileName != "" {
+				t.Errorf("GetDataExportById() after a late UpdateDataExportStatus() got = %+v, want status %v", dataExport, wantStatus)
+			}
+		}
+
+		isLateUpdated(userId, expiredId, "pending")
+
+		failedUserId := insertUser(t, repo)
+		failedId := insertExport(t, repo, failedUserId, time.Now().Add(time.Hour), "")
+
+		_, err := repo.FailStaleDataExports(ctx, FailStaleDataExportsInput{CreatedBefore: time.Now().Add(time.Minute), FailureReason: "interrupted"})
+
+		if err != nil {
+			t.Fatalf("FailStaleDataExports() error = %v", err)
+		}
+
+		isLateUpdated(failedUserId, failedId, "failed")
+	})
+
+	t.Run("When the user has an export being built, another one will not be inserted", func(t *testing.T) {
+		repo := newRepository(t)
+
+		userId := insertUser(t, repo)
+		insertExport(t, repo, userId, time.Now().Add(time.Hour), "completed.zip")
+		pendingId := insertExport(t, repo, userId, time.Now().Add(time.Hour), "")
+
+		for _, status := range []string{"pending", "processing"} {
+
+			if status == "processing" {
+				_, err := repo.UpdateDataExportStatus(ctx, UpdateDataExportStatusInput{Id: pendingId, FromStatus: "pending", Status: "processing"})
+
+				if err != nil {
+					t.Fatalf("UpdateDataExportStatus() error = %v", err)
+				}
+			}
+
+			_, err := repo.InsertDataExport(ctx, InsertDataExportInput{
+				UserId:            userId,
+				DownloadTokenHash: fmt.Sprintf("%d-%s", userId, status),
+				ExpiredAt:         time.Now().Add(time.Hour),
+			})
+
+			if IsUniqueViolation(err, UniqueFieldPendingDataExport) == false {
+				t.Errorf("InsertDataExport() while an export is %s error = %v, want a unique violation", status, err)
+			}
+		}
+
+		insertExport(t, repo, insertUser(t, repo), time.Now().Add(time.Hour), "")
+	})
+
+	t.Run("When an export expired, it will be returned until its file is cleared", func(t *testing.T) {
+		repo := newRepository(t)
+
+		expiredUserId := insertUser(t, repo)
+		expiredId := insertExport(t, repo, expiredUserId, time.Now().Add(time.Hour), "expired.zip")
+		anonymizeUser(t, repo, expiredUserId)
+		activeId := insertExport(t, repo, insertUser(t, repo), time.Now().Add(time.Hour), "active.zip")
+
+		isExpired := func() bool {
+
+			expired, err := repo.GetExpiredDataExports(ctx, GetExpiredDataExportsInput{Limit: 1000})
+
+			if err != nil {
+				t.Fatalf("GetExpiredDataExports() error = %v", err)
+			}
+
+			isExpired := false
+
+			for _, dataExport := range expired.DataExports {
+				isExpired = isExpired || dataExport.Id == expiredId
+
+				if dataExport.Id == activeId {
+					t.Errorf("GetExpiredDataExports() returned the export that did not expire")
+				}
+			}
+
+			return isExpired
+		}
+
+		if isExpired() == false {
+			t.Errorf("GetExpiredDataExports() did not return the expired export")
+		}
+
+		cleared, err := repo.ClearDataExportFile(ctx, ClearDataExportFileInput{Id: expiredId})
+
+		if err != nil || cleared.IsSuccessUpdate == false {
+			t.Fatalf("ClearDataExportFile() output = %v, error = %v", cleared, err)
+		}
+
+		if isExpired() {
+			t.Errorf("GetExpiredDataExports() returned the export whose file was cleared")
+		}
+
+		cleared, err = repo.ClearDataExportFile(ctx, ClearDataExportFileInput{Id: expiredId})
+
+		if err != nil || cleared.IsSuccessUpdate {
+			t.Errorf("ClearDataExportFile() of a cleared export output = %v, error = %v", cleared, err)
+		}
+	})
+
+	t.Run("When an export is pending since before the cutoff, it will be marked failed", func(t *testing.T) {
+		repo := newRepository(t)
+
+		userId := insertUser(t, repo)
+		exportId := insertExport(t, repo, userId, time.Now().Add(time.Hour), "")
+
+		failed, err := repo.FailStaleDataExports(ctx, FailStaleDataExportsInput{
+			CreatedBefore: time.Now().Add(-time.Hour),
+			FailureReason: "interrupted",
+		})
+
+		if err != nil {
+			t.Fatalf("FailStaleDataExports() error = %v", err)
+		}
+
+		dataExport, _ := repo.GetDataExportById(ctx, GetDataExportByIdInput{Id: exportId, UserId: userId})
+
+		if dataExport.Status != "pending" {
+			t.Errorf("FailStaleDataExports() failed an export created after the cutoff, output = %v", failed)
+		}
+
+		failed, err = repo.FailStaleDataExports(ctx, FailStaleDataExportsInput{
+			CreatedBefore: time.Now().Add(time.Minute),
+			FailureReason: "interrupted",
+		})
+
+		if err != nil || failed.FailedCount < 1 {
+			t.Fatalf("FailStaleDataExports() output = %v, error = %v", failed, err)
+		}
+
+		dataExport, _ = repo.GetDataExportById(ctx, GetDataExportByIdInput{Id: exportId, UserId: userId})
+
+		if dataExport.Status != "failed" || dataExport.FailureReason != "interrupted" {
+			t.Errorf("GetDataExportById() after FailStaleDataExports() got = %+v", dataExport)
+		}
+	})
+
+	t.Run("When a user is anonymized, its exports will expire", func(t *testing.T) {
+		repo := newRepository(t)
+
+		userId := insertUser(t, repo)
+		exportId := insertExport(t, repo, userId, time.Now().Add(time.Hour), "personal-data-export.zip")
+
+		anonymizeUser(t, repo, userId)
+
+		dataExport, _ := repo.GetDataExportById(ctx, GetDataExportByIdInput{Id: exportId, UserId: userId})
+
+		if dataExport.ExpiredAt.After(time.Now()) {
+			t.Errorf("GetDataExportById() after AnonymizeUser() expired at = %v, want the past", dataExport.ExpiredAt)
+		}
+
+		expired, err := repo.GetExpiredDataExports(ctx, GetExpiredDataExportsInput{Limit: 1000})
+
+		if err != nil {
+			t.Fatalf("GetExpiredDataExports() error = %v", err)
+		}
+
+		for _, dataExport := range expired.DataExports {
+			if dataExport.Id == exportId {
+				return
+			}
+		}
+
+		t.Errorf("GetExpiredDataExports() did not return the export of the anonymized user")
+	})
+}
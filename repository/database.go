@@ -0,0 +1,118 @@
+// This file contains the setup of the database connection pool.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"time"
+)
+
+type OpenDatabaseOptions struct {
+	Dsn             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ConnectTimeout is how long OpenDatabase waits for the database to accept connections.
+	ConnectTimeout time.Duration
+}
+
+// The database/sql driver names of the databases the repository supports.
+const (
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite3"
+)
+
+const (
+	databaseConnectInitialBackoff = 500 * time.Millisecond
+	databaseConnectMaxBackoff     = 10 * time.Second
+)
+
+// OpenDatabase opens a connection pool and waits, with exponential backoff, until the database is reachable.
+// The pool replaces broken connections by itself, so the service survives database restarts.
+func OpenDatabase(ctx context.Context, opts OpenDatabaseOptions) (*sql.DB, error) {
+
+	connector, err := pq.NewConnector(opts.Dsn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(newTracedConnector(connector, semconv.DBSystemPostgreSQL))
+
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+
+	err = waitForDatabase(ctx, db, opts.ConnectTimeout)
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func waitForDatabase(ctx context.Context, db *sql.DB, connectTimeout time.Duration) error {
+
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	backoff := databaseConnectInitialBackoff
+
+	for {
+
+		err := db.PingContext(ctx)
+
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+
+		if backoff > databaseConnectMaxBackoff {
+			backoff = databaseConnectMaxBackoff
+		}
+	}
+}
+
+func (r Repository) GetPoolStats() PoolStatsOutput {
+	return getPoolStats(r.Db)
+}
+
+func (r Repository) Ping(ctx context.Context) error {
+	return r.Db.PingContext(ctx)
+}
+
+func (r Repository) GetSchemaVersion(ctx context.Context) (int64, error) {
+	return getSchemaVersion(ctx, r.Db)
+}
+
+func getPoolStats(db *sql.DB) PoolStatsOutput {
+
+	stats := db.Stats()
+
+	return PoolStatsOutput{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		// Without a ResilientRepository in front the calls always reach the database.
+		CircuitBreakerState: CircuitBreakerClosed,
+	}
+}
@@ -0,0 +1,58 @@
+// This file contains the errors the repository layer translates database errors into.
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+)
+
+const postgresUniqueViolationCode = "23505"
+
+const (
+	UniqueFieldPhoneNumber = "phone_number"
+	UniqueFieldEmail       = "email"
+	// UniqueFieldPendingDataExport is the user id of the export being built, a user has at most one.
+	UniqueFieldPendingDataExport = "pending_data_export"
+)
+
+// uniqueConstraintFields maps the unique constraints to the field they keep unique.
+var uniqueConstraintFields = map[string]string{
+	"users_phone_number_key":                    UniqueFieldPhoneNumber,
+	"phone_number_unique_index":                 UniqueFieldPhoneNumber,
+	"users_email_key":                           UniqueFieldEmail,
+	"data_exports_user_id_pending_unique_index": UniqueFieldPendingDataExport,
+}
+
+// UniqueViolationError is returned when a write would store a value another row already has.
+type UniqueViolationError struct {
+	Field string
+}
+
+func (e *UniqueViolationError) Error() string {
+	return fmt.Sprintf("%s is already used", e.Field)
+}
+
+// translateUniqueViolation turns a unique violation on a known constraint into a UniqueViolationError,
+// any other error is returned as it is.
+func translateUniqueViolation(err error) error {
+
+	var pqErr *pq.Error
+
+	if errors.As(err, &pqErr) && pqErr.Code == postgresUniqueViolationCode {
+
+		if field, ok := uniqueConstraintFields[pqErr.Constraint]; ok {
+			return &UniqueViolationError{Field: field}
+		}
+	}
+
+	return err
+}
+
+// IsUniqueViolation reports whether err is a UniqueViolationError on field.
+func IsUniqueViolation(err error, field string) bool {
+
+	var uniqueViolationErr *UniqueViolationError
+
+	return errors.As(err, &uniqueViolationErr) && uniqueViolationErr.Field == field
+}
@@ -5,24 +5,41 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	_ "github.com/lib/pq"
+	"strings"
+	"time"
 )
 
 const TableUser = "users"
 
+const (
+	UserFieldPhoneNumber = "phone_number"
+	UserFieldFullName    = "full_name"
+)
+
+// updatableUserFields are the fields UpdateUserInput.Fields accepts, in the order they are written.
+var updatableUserFields = []string{UserFieldPhoneNumber, UserFieldFullName}
+
 type Repository struct {
-	Conn *sql.Conn
+	Db         *sql.DB
+	statements *statementCache
+	replicas   *replicaSet
+	// tx is set on the copy of the repository handed to a WithinTransaction callback.
+	tx *sql.Tx
 }
 
 type NewRepositoryOptions struct {
-	Conn *sql.Conn
+	Db *sql.DB
+	// Replicas serve GetById and GetByPhoneNumberIncludePassword, everything else runs on Db.
+	Replicas []*sql.DB
 }
 
 func (r Repository) GetById(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
 
-	query := `SELECT id, phone_number, full_name, login_success_count, created_at, updated_at FROM users WHERE id = $1;`
+	query := `SELECT id, phone_number, full_name, email, email_verified_at, role, status, status_reason, status_changed_at, deletion_scheduled_at, login_success_count, created_at, updated_at FROM users WHERE id = $1;`
 
-	queryStatement, err := r.Conn.PrepareContext(ctx, query)
+	queryStatement, err := r.prepareRead(ctx, query)
 
 	if err != nil {
 		return nil, err
@@ -30,8 +47,14 @@ func (r Repository) GetById(ctx context.Context, input GetUserByIdInput) (*GetUs
 
 	result := GetUserByIdOutput{}
 
+	var email sql.NullString
+	var emailVerifiedAt sql.NullTime
+	var statusReason sql.NullString
+	var statusChangedAt sql.NullTime
+	var deletionScheduledAt sql.NullTime
+
 	err = queryStatement.QueryRowContext(ctx, input.Id).
-		Scan(&result.Id, &result.PhoneNumber, &result.FullName, &result.LoginSuccessCount, &result.CreatedAt, &result.UpdatedAt)
+		Scan(&result.Id, &result.PhoneNumber, &result.FullName, &email, &emailVerifiedAt, &result.Role, &result.Status, &statusReason, &statusChangedAt, &deletionScheduledAt, &result.LoginSuccessCount, &result.CreatedAt, &result.UpdatedAt)
 
 	if err != nil {
 
@@ -42,26 +65,174 @@ func (r Repository) GetById(ctx context.Context, input GetUserByIdInput) (*GetUs
 		return nil, err
 	}
 
+	result.Email = email.String
+	result.EmailVerifiedAt = nullTimeToPointer(emailVerifiedAt)
+	result.StatusReason = statusReason.String
+	result.StatusChangedAt = nullTimeToPointer(statusChangedAt)
+	result.DeletionScheduledAt = nullTimeToPointer(deletionScheduledAt)
+
 	return &result, nil
 }
 
 func (r Repository) Update(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error) {
-	query := `UPDATE users SET phone_number = $1, full_name = $2, login_success_count = $3 WHERE id = $4;`
 
-	queryStatement, err := r.Conn.PrepareContext(ctx, query)
+	fields, args, err := getUpdatedUserFields(input)
 
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = queryStatement.ExecContext(ctx, input.PhoneNumber, input.FullName, input.LoginSuccessCount, input.Id)
+	assignments := []string{}
+
+	for i, field := range fields {
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", field, i+1))
+	}
+
+	args = append(args, input.Id)
+	query := fmt.Sprintf(`UPDATE users SET %s WHERE id = $%d;`, strings.Join(assignments, ", "), len(args))
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, args...)
+
+	if err != nil {
+		return nil, translateUniqueViolation(err)
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
 
 	if err != nil {
 		return nil, err
 	}
 
 	output := &UpdateUserOutput{
-		IsSuccessUpdate: true,
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+// IncrementLoginSuccessCount increments the counter in the database, so concurrent logins do not lose an increment.
+// Only an active user is updated, a user whose status changed since the login read it is not.
+func (r Repository) IncrementLoginSuccessCount(ctx context.Context, input IncrementLoginSuccessCountInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET login_success_count = login_success_count + 1 WHERE id = $1 AND status = 'active';`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateUserOutput{
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+func (r Repository) UpdateEmail(ctx context.Context, input UpdateUserEmailInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET email = $1, email_verified_at = NULL WHERE id = $2;`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.Email, input.Id)
+
+	if err != nil {
+		return nil, translateUniqueViolation(err)
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateUserOutput{
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+func (r Repository) UpdateRole(ctx context.Context, input UpdateUserRoleInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET role = $1 WHERE id = $2;`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.Role, input.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateUserOutput{
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+// UpdateStatus only succeeds while the user is still in FromStatus,
+// so two concurrent transitions of the same user cannot both be applied. A user pending deletion is left alone, only
+// CancelDeletion and AnonymizeUser end the user's own request.
+func (r Repository) UpdateStatus(ctx context.Context, input UpdateUserStatusInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET status = $1, status_reason = $2, status_changed_at = now()
+		WHERE id = $3 AND status = $4 AND status <> 'pending_deletion';`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.Status, stringToNullString(input.Reason), input.Id, input.FromStatus)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateUserOutput{
+		IsSuccessUpdate: rowsAffected > 0,
 	}
 
 	return output, nil
@@ -71,18 +242,18 @@ func (r Repository) Insert(ctx context.Context, input InsertUserInput) (output *
 
 	var lastInsertId int64
 
-	query := `INSERT INTO users (phone_number, full_name, password) VALUES ($1, $2, $3) RETURNING id;`
+	query := `INSERT INTO users (phone_number, full_name, email, password) VALUES ($1, $2, $3, $4) RETURNING id;`
 
-	queryStatement, err := r.Conn.PrepareContext(ctx, query)
+	queryStatement, err := r.prepareWrite(ctx, query)
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = queryStatement.QueryRowContext(ctx, input.PhoneNumber, input.FullName, input.Password).Scan(&lastInsertId)
+	err = queryStatement.QueryRowContext(ctx, input.PhoneNumber, input.FullName, stringToNullString(input.Email), input.Password).Scan(&lastInsertId)
 
 	if err != nil {
-		return nil, err
+		return nil, translateUniqueViolation(err)
 	}
 
 	output = &InsertUserOutput{
@@ -94,9 +265,9 @@ func (r Repository) Insert(ctx context.Context, input InsertUserInput) (output *
 
 func (r Repository) GetByPhoneNumberIncludePassword(ctx context.Context, input GetUserByPhoneNumberInput) (*GetUserByPhoneNumberOutput, error) {
 
-	query := `SELECT id, phone_number, full_name, password, login_success_count, created_at, updated_at FROM users WHERE phone_number = $1;`
+	query := `SELECT id, phone_number, full_name, email, email_verified_at, password, role, status, status_reason, status_changed_at, deletion_scheduled_at, login_success_count, created_at, updated_at FROM users WHERE phone_number = $1;`
 
-	queryStatement, err := r.Conn.PrepareContext(ctx, query)
+	queryStatement, err := r.prepareRead(ctx, query)
 
 	if err != nil {
 		return nil, err
@@ -104,8 +275,14 @@ func (r Repository) GetByPhoneNumberIncludePassword(ctx context.Context, input G
 
 	result := GetUserByPhoneNumberOutput{}
 
+	var email sql.NullString
+	var emailVerifiedAt sql.NullTime
+	var statusReason sql.NullString
+	var statusChangedAt sql.NullTime
+	var deletionScheduledAt sql.NullTime
+
 	err = queryStatement.QueryRowContext(ctx, input.PhoneNumber).
-		Scan(&result.Id, &result.PhoneNumber, &result.FullName, &result.Password, &result.LoginSuccessCount, &result.CreatedAt, &result.UpdatedAt)
+		Scan(&result.Id, &result.PhoneNumber, &result.FullName, &email, &emailVerifiedAt, &result.Password, &result.Role, &result.Status, &statusReason, &statusChangedAt, &deletionScheduledAt, &result.LoginSuccessCount, &result.CreatedAt, &result.UpdatedAt)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -115,12 +292,230 @@ func (r Repository) GetByPhoneNumberIncludePassword(ctx context.Context, input G
 		return nil, err
 	}
 
+	result.Email = email.String
+	result.EmailVerifiedAt = nullTimeToPointer(emailVerifiedAt)
+	result.StatusReason = statusReason.String
+	result.StatusChangedAt = nullTimeToPointer(statusChangedAt)
+	result.DeletionScheduledAt = nullTimeToPointer(deletionScheduledAt)
+
 	return &result, nil
 }
 
+func (r Repository) GetByEmailIncludePassword(ctx context.Context, input GetUserByEmailInput) (*GetUserByEmailOutput, error) {
+
+	query := `SELECT id, phone_number, full_name, email, email_verified_at, password, role, status, status_reason, status_changed_at, deletion_scheduled_at, login_success_count, created_at, updated_at FROM users WHERE email = $1;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := GetUserByEmailOutput{}
+
+	var emailVerifiedAt sql.NullTime
+	var statusReason sql.NullString
+	var statusChangedAt sql.NullTime
+	var deletionScheduledAt sql.NullTime
+
+	err = queryStatement.QueryRowContext(ctx, input.Email).
+		Scan(&result.Id, &result.PhoneNumber, &result.FullName, &result.Email, &emailVerifiedAt, &result.Password, &result.Role, &result.Status, &statusReason, &statusChangedAt, &deletionScheduledAt, &result.LoginSuccessCount, &result.CreatedAt, &result.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	result.EmailVerifiedAt = nullTimeToPointer(emailVerifiedAt)
+	result.StatusReason = statusReason.String
+	result.StatusChangedAt = nullTimeToPointer(statusChangedAt)
+	result.DeletionScheduledAt = nullTimeToPointer(deletionScheduledAt)
+
+	return &result, nil
+}
+
+func (r Repository) InsertEmailVerification(ctx context.Context, input InsertEmailVerificationInput) (*InsertEmailVerificationOutput, error) {
+
+	var lastInsertId int64
+
+	query := `INSERT INTO email_verifications (user_id, email, token_hash, expired_at) VALUES ($1, $2, $3, $4) RETURNING id;`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryStatement.QueryRowContext(ctx, input.UserId, input.Email, input.TokenHash, input.ExpiredAt).Scan(&lastInsertId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &InsertEmailVerificationOutput{
+		Id: lastInsertId,
+	}
+
+	return output, nil
+}
+
+func (r Repository) GetEmailVerificationByTokenHash(ctx context.Context, input GetEmailVerificationByTokenHashInput) (*GetEmailVerificationOutput, error) {
+
+	query := `SELECT id, user_id, email, expired_at, verified_at, created_at FROM email_verifications WHERE token_hash = $1;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := GetEmailVerificationOutput{}
+
+	var verifiedAt sql.NullTime
+
+	err = queryStatement.QueryRowContext(ctx, input.TokenHash).
+		Scan(&result.Id, &result.UserId, &result.Email, &result.ExpiredAt, &verifiedAt, &result.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	result.VerifiedAt = nullTimeToPointer(verifiedAt)
+
+	return &result, nil
+}
+
+// VerifyEmail marks the verification as used and the user's email as verified in one statement.
+// The user is only updated when the email still matches the one the verification was sent to.
+func (r Repository) VerifyEmail(ctx context.Context, input VerifyEmailInput) (*VerifyEmailOutput, error) {
+
+	query := `WITH verification AS (
+		UPDATE email_verifications SET verified_at = now() WHERE id = $1 AND verified_at IS NULL RETURNING user_id, email
+	)
+	UPDATE users SET email_verified_at = now() FROM verification WHERE users.id = verification.user_id AND users.email = verification.email;`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.EmailVerificationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &VerifyEmailOutput{
+		IsVerified: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+// getUpdatedUserFields returns the fields of input.Fields with their values. They are always in the order of
+// updatableUserFields, so every field mask maps to a single cached statement.
+func getUpdatedUserFields(input UpdateUserInput) ([]string, []interface{}, error) {
+
+	values := map[string]interface{}{
+		UserFieldPhoneNumber: input.PhoneNumber,
+		UserFieldFullName:    input.FullName,
+	}
+
+	requestedFields := map[string]bool{}
+
+	for _, field := range input.Fields {
+
+		if _, ok := values[field]; ok == false {
+			return nil, nil, fmt.Errorf("Field %s can not be updated", field)
+		}
+
+		requestedFields[field] = true
+	}
+
+	fields := []string{}
+	args := []interface{}{}
+
+	for _, field := range updatableUserFields {
+
+		if requestedFields[field] {
+			fields = append(fields, field)
+			args = append(args, values[field])
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, nil, errors.New("No field to update")
+	}
+
+	return fields, args, nil
+}
+
 func NewRepository(opts NewRepositoryOptions) *Repository {
 
 	return &Repository{
-		Conn: opts.Conn,
+		Db:         opts.Db,
+		statements: newStatementCache(opts.Db),
+		replicas:   newReplicaSet(opts.Replicas),
+	}
+}
+
+// Close closes the prepared statements of the repository, call it before closing the databases.
+func (r Repository) Close() error {
+
+	err := r.statements.close()
+	replicasErr := r.replicas.close()
+
+	if err != nil {
+		return err
+	}
+
+	return replicasErr
+}
+
+func stringToNullString(str string) sql.NullString {
+	return sql.NullString{
+		String: str,
+		Valid:  str != "",
 	}
 }
+
+func nullTimeToPointer(nullTime sql.NullTime) *time.Time {
+
+	if nullTime.Valid == false {
+		return nil
+	}
+
+	return &nullTime.Time
+}
+
+func optionalIdToNullInt64(id *int64) sql.NullInt64 {
+
+	if id == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: *id, Valid: true}
+}
+
+func nullInt64ToPointer(nullInt64 sql.NullInt64) *int64 {
+
+	if nullInt64.Valid == false {
+		return nil
+	}
+
+	return &nullInt64.Int64
+}
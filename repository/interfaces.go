@@ -1,7 +1,5 @@
 // This file contains the interfaces for the repository layer.
 // The repository layer is responsible for interacting with the database.
-// For testing purpose we will generate mock implementations of these
-// interfaces using mockgen. See the Makefile for more information.
 package repository
 
 import "context"
@@ -9,6 +7,62 @@ import "context"
 type UserRepositoryInterface interface {
 	GetById(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error)
 	GetByPhoneNumberIncludePassword(ctx context.Context, input GetUserByPhoneNumberInput) (*GetUserByPhoneNumberOutput, error)
+	GetByEmailIncludePassword(ctx context.Context, input GetUserByEmailInput) (*GetUserByEmailOutput, error)
 	Update(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error)
+	IncrementLoginSuccessCount(ctx context.Context, input IncrementLoginSuccessCountInput) (*UpdateUserOutput, error)
+	UpdateEmail(ctx context.Context, input UpdateUserEmailInput) (*UpdateUserOutput, error)
+	UpdateRole(ctx context.Context, input UpdateUserRoleInput) (*UpdateUserOutput, error)
+	UpdateStatus(ctx context.Context, input UpdateUserStatusInput) (*UpdateUserOutput, error)
+	ScheduleDeletion(ctx context.Context, input ScheduleUserDeletionInput) (*UpdateUserOutput, error)
+	CancelDeletion(ctx context.Context, input CancelUserDeletionInput) (*UpdateUserOutput, error)
+	GetUsersDueForDeletion(ctx context.Context, input GetUsersDueForDeletionInput) (*GetUsersDueForDeletionOutput, error)
+	AnonymizeUser(ctx context.Context, input AnonymizeUserInput) (*AnonymizeUserOutput, error)
 	Insert(ctx context.Context, input InsertUserInput) (*InsertUserOutput, error)
+	InsertEmailVerification(ctx context.Context, input InsertEmailVerificationInput) (*InsertEmailVerificationOutput, error)
+	GetEmailVerificationByTokenHash(ctx context.Context, input GetEmailVerificationByTokenHashInput) (*GetEmailVerificationOutput, error)
+	VerifyEmail(ctx context.Context, input VerifyEmailInput) (*VerifyEmailOutput, error)
+	WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error
+}
+
+type LoginEventRepositoryInterface interface {
+	InsertLoginEvent(ctx context.Context, input InsertLoginEventInput) (*InsertLoginEventOutput, error)
+	GetLoginEventsByUserId(ctx context.Context, input GetLoginEventsByUserIdInput) (*GetLoginEventsByUserIdOutput, error)
+}
+
+type DataExportRepositoryInterface interface {
+	InsertDataExport(ctx context.Context, input InsertDataExportInput) (*InsertDataExportOutput, error)
+	GetDataExportById(ctx context.Context, input GetDataExportByIdInput) (*DataExportOutput, error)
+	GetDataExportByTokenHash(ctx context.Context, input GetDataExportByTokenHashInput) (*DataExportOutput, error)
+	UpdateDataExportStatus(ctx context.Context, input UpdateDataExportStatusInput) (*UpdateDataExportOutput, error)
+	GetExpiredDataExports(ctx context.Context, input GetExpiredDataExportsInput) (*GetDataExportsOutput, error)
+	ClearDataExportFile(ctx context.Context, input ClearDataExportFileInput) (*UpdateDataExportOutput, error)
+	FailStaleDataExports(ctx context.Context, input FailStaleDataExportsInput) (*FailStaleDataExportsOutput, error)
+	WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error
+}
+
+type AuditLogRepositoryInterface interface {
+	AppendAuditLog(ctx context.Context, input AppendAuditLogInput) (*AppendAuditLogOutput, error)
+	GetAuditLogs(ctx context.Context, input GetAuditLogsInput) (*GetAuditLogsOutput, error)
+	GetAuditLogsAfterId(ctx context.Context, input GetAuditLogsAfterIdInput) ([]AuditLogOutput, error)
+}
+
+// TransactionRepositoryInterface is the repository handed to a WithinTransaction callback. The login events, audit
+// log entries and exports it writes are committed or rolled back together with the users.
+type TransactionRepositoryInterface interface {
+	UserRepositoryInterface
+	LoginEventRepositoryInterface
+	AuditLogRepositoryInterface
+	DataExportRepositoryInterface
+}
+
+type DatabaseRepositoryInterface interface {
+	GetPoolStats() PoolStatsOutput
+	// Ping checks that the database accepts connections.
+	Ping(ctx context.Context) error
+	// GetSchemaVersion returns the version of the latest applied migration, 0 when none is applied.
+	GetSchemaVersion(ctx context.Context) (int64, error)
+}
+
+type UserCacheInterface interface {
+	GetUserCacheStats() UserCacheStatsOutput
 }
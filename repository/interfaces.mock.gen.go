@@ -34,6 +34,51 @@ func (m *MockUserRepositoryInterface) EXPECT() *MockUserRepositoryInterfaceMockR
 	return m.recorder
 }
 
+// AnonymizeUser mocks base method.
+func (m *MockUserRepositoryInterface) AnonymizeUser(ctx context.Context, input AnonymizeUserInput) (*AnonymizeUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUser", ctx, input)
+	ret0, _ := ret[0].(*AnonymizeUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeUser indicates an expected call of AnonymizeUser.
+func (mr *MockUserRepositoryInterfaceMockRecorder) AnonymizeUser(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUser", reflect.TypeOf((*MockUserRepositoryInterface)(nil).AnonymizeUser), ctx, input)
+}
+
+// CancelDeletion mocks base method.
+func (m *MockUserRepositoryInterface) CancelDeletion(ctx context.Context, input CancelUserDeletionInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockUserRepositoryInterfaceMockRecorder) CancelDeletion(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockUserRepositoryInterface)(nil).CancelDeletion), ctx, input)
+}
+
+// GetByEmailIncludePassword mocks base method.
+func (m *MockUserRepositoryInterface) GetByEmailIncludePassword(ctx context.Context, input GetUserByEmailInput) (*GetUserByEmailOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmailIncludePassword", ctx, input)
+	ret0, _ := ret[0].(*GetUserByEmailOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmailIncludePassword indicates an expected call of GetByEmailIncludePassword.
+func (mr *MockUserRepositoryInterfaceMockRecorder) GetByEmailIncludePassword(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmailIncludePassword", reflect.TypeOf((*MockUserRepositoryInterface)(nil).GetByEmailIncludePassword), ctx, input)
+}
+
 // GetById mocks base method.
 func (m *MockUserRepositoryInterface) GetById(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +109,51 @@ func (mr *MockUserRepositoryInterfaceMockRecorder) GetByPhoneNumberIncludePasswo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPhoneNumberIncludePassword", reflect.TypeOf((*MockUserRepositoryInterface)(nil).GetByPhoneNumberIncludePassword), ctx, input)
 }
 
+// GetEmailVerificationByTokenHash mocks base method.
+func (m *MockUserRepositoryInterface) GetEmailVerificationByTokenHash(ctx context.Context, input GetEmailVerificationByTokenHashInput) (*GetEmailVerificationOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerificationByTokenHash", ctx, input)
+	ret0, _ := ret[0].(*GetEmailVerificationOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerificationByTokenHash indicates an expected call of GetEmailVerificationByTokenHash.
+func (mr *MockUserRepositoryInterfaceMockRecorder) GetEmailVerificationByTokenHash(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationByTokenHash", reflect.TypeOf((*MockUserRepositoryInterface)(nil).GetEmailVerificationByTokenHash), ctx, input)
+}
+
+// GetUsersDueForDeletion mocks base method.
+func (m *MockUserRepositoryInterface) GetUsersDueForDeletion(ctx context.Context, input GetUsersDueForDeletionInput) (*GetUsersDueForDeletionOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersDueForDeletion", ctx, input)
+	ret0, _ := ret[0].(*GetUsersDueForDeletionOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersDueForDeletion indicates an expected call of GetUsersDueForDeletion.
+func (mr *MockUserRepositoryInterfaceMockRecorder) GetUsersDueForDeletion(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersDueForDeletion", reflect.TypeOf((*MockUserRepositoryInterface)(nil).GetUsersDueForDeletion), ctx, input)
+}
+
+// IncrementLoginSuccessCount mocks base method.
+func (m *MockUserRepositoryInterface) IncrementLoginSuccessCount(ctx context.Context, input IncrementLoginSuccessCountInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementLoginSuccessCount", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementLoginSuccessCount indicates an expected call of IncrementLoginSuccessCount.
+func (mr *MockUserRepositoryInterfaceMockRecorder) IncrementLoginSuccessCount(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementLoginSuccessCount", reflect.TypeOf((*MockUserRepositoryInterface)(nil).IncrementLoginSuccessCount), ctx, input)
+}
+
 // Insert mocks base method.
 func (m *MockUserRepositoryInterface) Insert(ctx context.Context, input InsertUserInput) (*InsertUserOutput, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +169,36 @@ func (mr *MockUserRepositoryInterfaceMockRecorder) Insert(ctx, input interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockUserRepositoryInterface)(nil).Insert), ctx, input)
 }
 
+// InsertEmailVerification mocks base method.
+func (m *MockUserRepositoryInterface) InsertEmailVerification(ctx context.Context, input InsertEmailVerificationInput) (*InsertEmailVerificationOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertEmailVerification", ctx, input)
+	ret0, _ := ret[0].(*InsertEmailVerificationOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertEmailVerification indicates an expected call of InsertEmailVerification.
+func (mr *MockUserRepositoryInterfaceMockRecorder) InsertEmailVerification(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertEmailVerification", reflect.TypeOf((*MockUserRepositoryInterface)(nil).InsertEmailVerification), ctx, input)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockUserRepositoryInterface) ScheduleDeletion(ctx context.Context, input ScheduleUserDeletionInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockUserRepositoryInterfaceMockRecorder) ScheduleDeletion(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockUserRepositoryInterface)(nil).ScheduleDeletion), ctx, input)
+}
+
 // Update mocks base method.
 func (m *MockUserRepositoryInterface) Update(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error) {
 	m.ctrl.T.Helper()
@@ -93,3 +213,900 @@ func (mr *MockUserRepositoryInterfaceMockRecorder) Update(ctx, input interface{}
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepositoryInterface)(nil).Update), ctx, input)
 }
+
+// UpdateEmail mocks base method.
+func (m *MockUserRepositoryInterface) UpdateEmail(ctx context.Context, input UpdateUserEmailInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockUserRepositoryInterfaceMockRecorder) UpdateEmail(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUserRepositoryInterface)(nil).UpdateEmail), ctx, input)
+}
+
+// UpdateRole mocks base method.
+func (m *MockUserRepositoryInterface) UpdateRole(ctx context.Context, input UpdateUserRoleInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockUserRepositoryInterfaceMockRecorder) UpdateRole(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockUserRepositoryInterface)(nil).UpdateRole), ctx, input)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockUserRepositoryInterface) UpdateStatus(ctx context.Context, input UpdateUserStatusInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockUserRepositoryInterfaceMockRecorder) UpdateStatus(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockUserRepositoryInterface)(nil).UpdateStatus), ctx, input)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserRepositoryInterface) VerifyEmail(ctx context.Context, input VerifyEmailInput) (*VerifyEmailOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, input)
+	ret0, _ := ret[0].(*VerifyEmailOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserRepositoryInterfaceMockRecorder) VerifyEmail(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserRepositoryInterface)(nil).VerifyEmail), ctx, input)
+}
+
+// WithinTransaction mocks base method.
+func (m *MockUserRepositoryInterface) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(TransactionRepositoryInterface) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, opts, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockUserRepositoryInterfaceMockRecorder) WithinTransaction(ctx, opts, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockUserRepositoryInterface)(nil).WithinTransaction), ctx, opts, fn)
+}
+
+// MockLoginEventRepositoryInterface is a mock of LoginEventRepositoryInterface interface.
+type MockLoginEventRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginEventRepositoryInterfaceMockRecorder
+}
+
+// MockLoginEventRepositoryInterfaceMockRecorder is the mock recorder for MockLoginEventRepositoryInterface.
+type MockLoginEventRepositoryInterfaceMockRecorder struct {
+	mock *MockLoginEventRepositoryInterface
+}
+
+// NewMockLoginEventRepositoryInterface creates a new mock instance.
+func NewMockLoginEventRepositoryInterface(ctrl *gomock.Controller) *MockLoginEventRepositoryInterface {
+	mock := &MockLoginEventRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockLoginEventRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginEventRepositoryInterface) EXPECT() *MockLoginEventRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetLoginEventsByUserId mocks base method.
+func (m *MockLoginEventRepositoryInterface) GetLoginEventsByUserId(ctx context.Context, input GetLoginEventsByUserIdInput) (*GetLoginEventsByUserIdOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginEventsByUserId", ctx, input)
+	ret0, _ := ret[0].(*GetLoginEventsByUserIdOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginEventsByUserId indicates an expected call of GetLoginEventsByUserId.
+func (mr *MockLoginEventRepositoryInterfaceMockRecorder) GetLoginEventsByUserId(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginEventsByUserId", reflect.TypeOf((*MockLoginEventRepositoryInterface)(nil).GetLoginEventsByUserId), ctx, input)
+}
+
+// InsertLoginEvent mocks base method.
+func (m *MockLoginEventRepositoryInterface) InsertLoginEvent(ctx context.Context, input InsertLoginEventInput) (*InsertLoginEventOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLoginEvent", ctx, input)
+	ret0, _ := ret[0].(*InsertLoginEventOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertLoginEvent indicates an expected call of InsertLoginEvent.
+func (mr *MockLoginEventRepositoryInterfaceMockRecorder) InsertLoginEvent(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLoginEvent", reflect.TypeOf((*MockLoginEventRepositoryInterface)(nil).InsertLoginEvent), ctx, input)
+}
+
+// MockDataExportRepositoryInterface is a mock of DataExportRepositoryInterface interface.
+type MockDataExportRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataExportRepositoryInterfaceMockRecorder
+}
+
+// MockDataExportRepositoryInterfaceMockRecorder is the mock recorder for MockDataExportRepositoryInterface.
+type MockDataExportRepositoryInterfaceMockRecorder struct {
+	mock *MockDataExportRepositoryInterface
+}
+
+// NewMockDataExportRepositoryInterface creates a new mock instance.
+func NewMockDataExportRepositoryInterface(ctrl *gomock.Controller) *MockDataExportRepositoryInterface {
+	mock := &MockDataExportRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockDataExportRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataExportRepositoryInterface) EXPECT() *MockDataExportRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ClearDataExportFile mocks base method.
+func (m *MockDataExportRepositoryInterface) ClearDataExportFile(ctx context.Context, input ClearDataExportFileInput) (*UpdateDataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearDataExportFile", ctx, input)
+	ret0, _ := ret[0].(*UpdateDataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClearDataExportFile indicates an expected call of ClearDataExportFile.
+func (mr *MockDataExportRepositoryInterfaceMockRecorder) ClearDataExportFile(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearDataExportFile", reflect.TypeOf((*MockDataExportRepositoryInterface)(nil).ClearDataExportFile), ctx, input)
+}
+
+// FailStaleDataExports mocks base method.
+func (m *MockDataExportRepositoryInterface) FailStaleDataExports(ctx context.Context, input FailStaleDataExportsInput) (*FailStaleDataExportsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailStaleDataExports", ctx, input)
+	ret0, _ := ret[0].(*FailStaleDataExportsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailStaleDataExports indicates an expected call of FailStaleDataExports.
+func (mr *MockDataExportRepositoryInterfaceMockRecorder) FailStaleDataExports(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailStaleDataExports", reflect.TypeOf((*MockDataExportRepositoryInterface)(nil).FailStaleDataExports), ctx, input)
+}
+
+// GetDataExportById mocks base method.
+func (m *MockDataExportRepositoryInterface) GetDataExportById(ctx context.Context, input GetDataExportByIdInput) (*DataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportById", ctx, input)
+	ret0, _ := ret[0].(*DataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportById indicates an expected call of GetDataExportById.
+func (mr *MockDataExportRepositoryInterfaceMockRecorder) GetDataExportById(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportById", reflect.TypeOf((*MockDataExportRepositoryInterface)(nil).GetDataExportById), ctx, input)
+}
+
+// GetDataExportByTokenHash mocks base method.
+func (m *MockDataExportRepositoryInterface) GetDataExportByTokenHash(ctx context.Context, input GetDataExportByTokenHashInput) (*DataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportByTokenHash", ctx, input)
+	ret0, _ := ret[0].(*DataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportByTokenHash indicates an expected call of GetDataExportByTokenHash.
+func (mr *MockDataExportRepositoryInterfaceMockRecorder) GetDataExportByTokenHash(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportByTokenHash", reflect.TypeOf((*MockDataExportRepositoryInterface)(nil).GetDataExportByTokenHash), ctx, input)
+}
+
+// GetExpiredDataExports mocks base method.
+func (m *MockDataExportRepositoryInterface) GetExpiredDataExports(ctx context.Context, input GetExpiredDataExportsInput) (*GetDataExportsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredDataExports", ctx, input)
+	ret0, _ := ret[0].(*GetDataExportsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredDataExports indicates an expected call of GetExpiredDataExports.
+func (mr *MockDataExportRepositoryInterfaceMockRecorder) GetExpiredDataExports(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredDataExports", reflect.TypeOf((*MockDataExportRepositoryInterface)(nil).GetExpiredDataExports), ctx, input)
+}
+
+// InsertDataExport mocks base method.
+func (m *MockDataExportRepositoryInterface) InsertDataExport(ctx context.Context, input InsertDataExportInput) (*InsertDataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDataExport", ctx, input)
+	ret0, _ := ret[0].(*InsertDataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertDataExport indicates an expected call of InsertDataExport.
+func (mr *MockDataExportRepositoryInterfaceMockRecorder) InsertDataExport(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDataExport", reflect.TypeOf((*MockDataExportRepositoryInterface)(nil).InsertDataExport), ctx, input)
+}
+
+// UpdateDataExportStatus mocks base method.
+func (m *MockDataExportRepositoryInterface) UpdateDataExportStatus(ctx context.Context, input UpdateDataExportStatusInput) (*UpdateDataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDataExportStatus", ctx, input)
+	ret0, _ := ret[0].(*UpdateDataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDataExportStatus indicates an expected call of UpdateDataExportStatus.
+func (mr *MockDataExportRepositoryInterfaceMockRecorder) UpdateDataExportStatus(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDataExportStatus", reflect.TypeOf((*MockDataExportRepositoryInterface)(nil).UpdateDataExportStatus), ctx, input)
+}
+
+// WithinTransaction mocks base method.
+func (m *MockDataExportRepositoryInterface) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(TransactionRepositoryInterface) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, opts, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockDataExportRepositoryInterfaceMockRecorder) WithinTransaction(ctx, opts, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockDataExportRepositoryInterface)(nil).WithinTransaction), ctx, opts, fn)
+}
+
+// MockAuditLogRepositoryInterface is a mock of AuditLogRepositoryInterface interface.
+type MockAuditLogRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLogRepositoryInterfaceMockRecorder
+}
+
+// MockAuditLogRepositoryInterfaceMockRecorder is the mock recorder for MockAuditLogRepositoryInterface.
+type MockAuditLogRepositoryInterfaceMockRecorder struct {
+	mock *MockAuditLogRepositoryInterface
+}
+
+// NewMockAuditLogRepositoryInterface creates a new mock instance.
+func NewMockAuditLogRepositoryInterface(ctrl *gomock.Controller) *MockAuditLogRepositoryInterface {
+	mock := &MockAuditLogRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockAuditLogRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLogRepositoryInterface) EXPECT() *MockAuditLogRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AppendAuditLog mocks base method.
+func (m *MockAuditLogRepositoryInterface) AppendAuditLog(ctx context.Context, input AppendAuditLogInput) (*AppendAuditLogOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAuditLog", ctx, input)
+	ret0, _ := ret[0].(*AppendAuditLogOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendAuditLog indicates an expected call of AppendAuditLog.
+func (mr *MockAuditLogRepositoryInterfaceMockRecorder) AppendAuditLog(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuditLog", reflect.TypeOf((*MockAuditLogRepositoryInterface)(nil).AppendAuditLog), ctx, input)
+}
+
+// GetAuditLogs mocks base method.
+func (m *MockAuditLogRepositoryInterface) GetAuditLogs(ctx context.Context, input GetAuditLogsInput) (*GetAuditLogsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogs", ctx, input)
+	ret0, _ := ret[0].(*GetAuditLogsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogs indicates an expected call of GetAuditLogs.
+func (mr *MockAuditLogRepositoryInterfaceMockRecorder) GetAuditLogs(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogs", reflect.TypeOf((*MockAuditLogRepositoryInterface)(nil).GetAuditLogs), ctx, input)
+}
+
+// GetAuditLogsAfterId mocks base method.
+func (m *MockAuditLogRepositoryInterface) GetAuditLogsAfterId(ctx context.Context, input GetAuditLogsAfterIdInput) ([]AuditLogOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogsAfterId", ctx, input)
+	ret0, _ := ret[0].([]AuditLogOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogsAfterId indicates an expected call of GetAuditLogsAfterId.
+func (mr *MockAuditLogRepositoryInterfaceMockRecorder) GetAuditLogsAfterId(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogsAfterId", reflect.TypeOf((*MockAuditLogRepositoryInterface)(nil).GetAuditLogsAfterId), ctx, input)
+}
+
+// MockTransactionRepositoryInterface is a mock of TransactionRepositoryInterface interface.
+type MockTransactionRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionRepositoryInterfaceMockRecorder
+}
+
+// MockTransactionRepositoryInterfaceMockRecorder is the mock recorder for MockTransactionRepositoryInterface.
+type MockTransactionRepositoryInterfaceMockRecorder struct {
+	mock *MockTransactionRepositoryInterface
+}
+
+// NewMockTransactionRepositoryInterface creates a new mock instance.
+func NewMockTransactionRepositoryInterface(ctrl *gomock.Controller) *MockTransactionRepositoryInterface {
+	mock := &MockTransactionRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockTransactionRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionRepositoryInterface) EXPECT() *MockTransactionRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AnonymizeUser mocks base method.
+func (m *MockTransactionRepositoryInterface) AnonymizeUser(ctx context.Context, input AnonymizeUserInput) (*AnonymizeUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUser", ctx, input)
+	ret0, _ := ret[0].(*AnonymizeUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeUser indicates an expected call of AnonymizeUser.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) AnonymizeUser(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUser", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).AnonymizeUser), ctx, input)
+}
+
+// AppendAuditLog mocks base method.
+func (m *MockTransactionRepositoryInterface) AppendAuditLog(ctx context.Context, input AppendAuditLogInput) (*AppendAuditLogOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAuditLog", ctx, input)
+	ret0, _ := ret[0].(*AppendAuditLogOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendAuditLog indicates an expected call of AppendAuditLog.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) AppendAuditLog(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuditLog", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).AppendAuditLog), ctx, input)
+}
+
+// CancelDeletion mocks base method.
+func (m *MockTransactionRepositoryInterface) CancelDeletion(ctx context.Context, input CancelUserDeletionInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) CancelDeletion(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).CancelDeletion), ctx, input)
+}
+
+// ClearDataExportFile mocks base method.
+func (m *MockTransactionRepositoryInterface) ClearDataExportFile(ctx context.Context, input ClearDataExportFileInput) (*UpdateDataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearDataExportFile", ctx, input)
+	ret0, _ := ret[0].(*UpdateDataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClearDataExportFile indicates an expected call of ClearDataExportFile.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) ClearDataExportFile(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearDataExportFile", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).ClearDataExportFile), ctx, input)
+}
+
+// FailStaleDataExports mocks base method.
+func (m *MockTransactionRepositoryInterface) FailStaleDataExports(ctx context.Context, input FailStaleDataExportsInput) (*FailStaleDataExportsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailStaleDataExports", ctx, input)
+	ret0, _ := ret[0].(*FailStaleDataExportsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailStaleDataExports indicates an expected call of FailStaleDataExports.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) FailStaleDataExports(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailStaleDataExports", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).FailStaleDataExports), ctx, input)
+}
+
+// GetAuditLogs mocks base method.
+func (m *MockTransactionRepositoryInterface) GetAuditLogs(ctx context.Context, input GetAuditLogsInput) (*GetAuditLogsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogs", ctx, input)
+	ret0, _ := ret[0].(*GetAuditLogsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogs indicates an expected call of GetAuditLogs.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetAuditLogs(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogs", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetAuditLogs), ctx, input)
+}
+
+// GetAuditLogsAfterId mocks base method.
+func (m *MockTransactionRepositoryInterface) GetAuditLogsAfterId(ctx context.Context, input GetAuditLogsAfterIdInput) ([]AuditLogOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogsAfterId", ctx, input)
+	ret0, _ := ret[0].([]AuditLogOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogsAfterId indicates an expected call of GetAuditLogsAfterId.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetAuditLogsAfterId(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogsAfterId", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetAuditLogsAfterId), ctx, input)
+}
+
+// GetByEmailIncludePassword mocks base method.
+func (m *MockTransactionRepositoryInterface) GetByEmailIncludePassword(ctx context.Context, input GetUserByEmailInput) (*GetUserByEmailOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmailIncludePassword", ctx, input)
+	ret0, _ := ret[0].(*GetUserByEmailOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmailIncludePassword indicates an expected call of GetByEmailIncludePassword.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetByEmailIncludePassword(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmailIncludePassword", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetByEmailIncludePassword), ctx, input)
+}
+
+// GetById mocks base method.
+func (m *MockTransactionRepositoryInterface) GetById(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, input)
+	ret0, _ := ret[0].(*GetUserByIdOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetById(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetById), ctx, input)
+}
+
+// GetByPhoneNumberIncludePassword mocks base method.
+func (m *MockTransactionRepositoryInterface) GetByPhoneNumberIncludePassword(ctx context.Context, input GetUserByPhoneNumberInput) (*GetUserByPhoneNumberOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPhoneNumberIncludePassword", ctx, input)
+	ret0, _ := ret[0].(*GetUserByPhoneNumberOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPhoneNumberIncludePassword indicates an expected call of GetByPhoneNumberIncludePassword.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetByPhoneNumberIncludePassword(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPhoneNumberIncludePassword", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetByPhoneNumberIncludePassword), ctx, input)
+}
+
+// GetDataExportById mocks base method.
+func (m *MockTransactionRepositoryInterface) GetDataExportById(ctx context.Context, input GetDataExportByIdInput) (*DataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportById", ctx, input)
+	ret0, _ := ret[0].(*DataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportById indicates an expected call of GetDataExportById.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetDataExportById(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportById", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetDataExportById), ctx, input)
+}
+
+// GetDataExportByTokenHash mocks base method.
+func (m *MockTransactionRepositoryInterface) GetDataExportByTokenHash(ctx context.Context, input GetDataExportByTokenHashInput) (*DataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportByTokenHash", ctx, input)
+	ret0, _ := ret[0].(*DataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportByTokenHash indicates an expected call of GetDataExportByTokenHash.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetDataExportByTokenHash(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportByTokenHash", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetDataExportByTokenHash), ctx, input)
+}
+
+// GetEmailVerificationByTokenHash mocks base method.
+func (m *MockTransactionRepositoryInterface) GetEmailVerificationByTokenHash(ctx context.Context, input GetEmailVerificationByTokenHashInput) (*GetEmailVerificationOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerificationByTokenHash", ctx, input)
+	ret0, _ := ret[0].(*GetEmailVerificationOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerificationByTokenHash indicates an expected call of GetEmailVerificationByTokenHash.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetEmailVerificationByTokenHash(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationByTokenHash", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetEmailVerificationByTokenHash), ctx, input)
+}
+
+// GetExpiredDataExports mocks base method.
+func (m *MockTransactionRepositoryInterface) GetExpiredDataExports(ctx context.Context, input GetExpiredDataExportsInput) (*GetDataExportsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredDataExports", ctx, input)
+	ret0, _ := ret[0].(*GetDataExportsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredDataExports indicates an expected call of GetExpiredDataExports.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetExpiredDataExports(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredDataExports", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetExpiredDataExports), ctx, input)
+}
+
+// GetLoginEventsByUserId mocks base method.
+func (m *MockTransactionRepositoryInterface) GetLoginEventsByUserId(ctx context.Context, input GetLoginEventsByUserIdInput) (*GetLoginEventsByUserIdOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginEventsByUserId", ctx, input)
+	ret0, _ := ret[0].(*GetLoginEventsByUserIdOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginEventsByUserId indicates an expected call of GetLoginEventsByUserId.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetLoginEventsByUserId(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginEventsByUserId", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetLoginEventsByUserId), ctx, input)
+}
+
+// GetUsersDueForDeletion mocks base method.
+func (m *MockTransactionRepositoryInterface) GetUsersDueForDeletion(ctx context.Context, input GetUsersDueForDeletionInput) (*GetUsersDueForDeletionOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersDueForDeletion", ctx, input)
+	ret0, _ := ret[0].(*GetUsersDueForDeletionOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersDueForDeletion indicates an expected call of GetUsersDueForDeletion.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) GetUsersDueForDeletion(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersDueForDeletion", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).GetUsersDueForDeletion), ctx, input)
+}
+
+// IncrementLoginSuccessCount mocks base method.
+func (m *MockTransactionRepositoryInterface) IncrementLoginSuccessCount(ctx context.Context, input IncrementLoginSuccessCountInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementLoginSuccessCount", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementLoginSuccessCount indicates an expected call of IncrementLoginSuccessCount.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) IncrementLoginSuccessCount(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementLoginSuccessCount", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).IncrementLoginSuccessCount), ctx, input)
+}
+
+// Insert mocks base method.
+func (m *MockTransactionRepositoryInterface) Insert(ctx context.Context, input InsertUserInput) (*InsertUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, input)
+	ret0, _ := ret[0].(*InsertUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) Insert(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).Insert), ctx, input)
+}
+
+// InsertDataExport mocks base method.
+func (m *MockTransactionRepositoryInterface) InsertDataExport(ctx context.Context, input InsertDataExportInput) (*InsertDataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDataExport", ctx, input)
+	ret0, _ := ret[0].(*InsertDataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertDataExport indicates an expected call of InsertDataExport.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) InsertDataExport(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDataExport", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).InsertDataExport), ctx, input)
+}
+
+// InsertEmailVerification mocks base method.
+func (m *MockTransactionRepositoryInterface) InsertEmailVerification(ctx context.Context, input InsertEmailVerificationInput) (*InsertEmailVerificationOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertEmailVerification", ctx, input)
+	ret0, _ := ret[0].(*InsertEmailVerificationOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertEmailVerification indicates an expected call of InsertEmailVerification.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) InsertEmailVerification(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertEmailVerification", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).InsertEmailVerification), ctx, input)
+}
+
+// InsertLoginEvent mocks base method.
+func (m *MockTransactionRepositoryInterface) InsertLoginEvent(ctx context.Context, input InsertLoginEventInput) (*InsertLoginEventOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLoginEvent", ctx, input)
+	ret0, _ := ret[0].(*InsertLoginEventOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertLoginEvent indicates an expected call of InsertLoginEvent.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) InsertLoginEvent(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLoginEvent", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).InsertLoginEvent), ctx, input)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockTransactionRepositoryInterface) ScheduleDeletion(ctx context.Context, input ScheduleUserDeletionInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) ScheduleDeletion(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).ScheduleDeletion), ctx, input)
+}
+
+// Update mocks base method.
+func (m *MockTransactionRepositoryInterface) Update(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) Update(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).Update), ctx, input)
+}
+
+// UpdateDataExportStatus mocks base method.
+func (m *MockTransactionRepositoryInterface) UpdateDataExportStatus(ctx context.Context, input UpdateDataExportStatusInput) (*UpdateDataExportOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDataExportStatus", ctx, input)
+	ret0, _ := ret[0].(*UpdateDataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDataExportStatus indicates an expected call of UpdateDataExportStatus.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) UpdateDataExportStatus(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDataExportStatus", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).UpdateDataExportStatus), ctx, input)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockTransactionRepositoryInterface) UpdateEmail(ctx context.Context, input UpdateUserEmailInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) UpdateEmail(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).UpdateEmail), ctx, input)
+}
+
+// UpdateRole mocks base method.
+func (m *MockTransactionRepositoryInterface) UpdateRole(ctx context.Context, input UpdateUserRoleInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) UpdateRole(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).UpdateRole), ctx, input)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockTransactionRepositoryInterface) UpdateStatus(ctx context.Context, input UpdateUserStatusInput) (*UpdateUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, input)
+	ret0, _ := ret[0].(*UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) UpdateStatus(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).UpdateStatus), ctx, input)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockTransactionRepositoryInterface) VerifyEmail(ctx context.Context, input VerifyEmailInput) (*VerifyEmailOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, input)
+	ret0, _ := ret[0].(*VerifyEmailOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) VerifyEmail(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).VerifyEmail), ctx, input)
+}
+
+// WithinTransaction mocks base method.
+func (m *MockTransactionRepositoryInterface) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(TransactionRepositoryInterface) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, opts, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockTransactionRepositoryInterfaceMockRecorder) WithinTransaction(ctx, opts, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockTransactionRepositoryInterface)(nil).WithinTransaction), ctx, opts, fn)
+}
+
+// MockDatabaseRepositoryInterface is a mock of DatabaseRepositoryInterface interface.
+type MockDatabaseRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockDatabaseRepositoryInterfaceMockRecorder
+}
+
+// MockDatabaseRepositoryInterfaceMockRecorder is the mock recorder for MockDatabaseRepositoryInterface.
+type MockDatabaseRepositoryInterfaceMockRecorder struct {
+	mock *MockDatabaseRepositoryInterface
+}
+
+// NewMockDatabaseRepositoryInterface creates a new mock instance.
+func NewMockDatabaseRepositoryInterface(ctrl *gomock.Controller) *MockDatabaseRepositoryInterface {
+	mock := &MockDatabaseRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockDatabaseRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDatabaseRepositoryInterface) EXPECT() *MockDatabaseRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetPoolStats mocks base method.
+func (m *MockDatabaseRepositoryInterface) GetPoolStats() PoolStatsOutput {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPoolStats")
+	ret0, _ := ret[0].(PoolStatsOutput)
+	return ret0
+}
+
+// GetPoolStats indicates an expected call of GetPoolStats.
+func (mr *MockDatabaseRepositoryInterfaceMockRecorder) GetPoolStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPoolStats", reflect.TypeOf((*MockDatabaseRepositoryInterface)(nil).GetPoolStats))
+}
+
+// GetSchemaVersion mocks base method.
+func (m *MockDatabaseRepositoryInterface) GetSchemaVersion(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaVersion", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemaVersion indicates an expected call of GetSchemaVersion.
+func (mr *MockDatabaseRepositoryInterfaceMockRecorder) GetSchemaVersion(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaVersion", reflect.TypeOf((*MockDatabaseRepositoryInterface)(nil).GetSchemaVersion), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockDatabaseRepositoryInterface) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockDatabaseRepositoryInterfaceMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockDatabaseRepositoryInterface)(nil).Ping), ctx)
+}
+
+// MockUserCacheInterface is a mock of UserCacheInterface interface.
+type MockUserCacheInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserCacheInterfaceMockRecorder
+}
+
+// MockUserCacheInterfaceMockRecorder is the mock recorder for MockUserCacheInterface.
+type MockUserCacheInterfaceMockRecorder struct {
+	mock *MockUserCacheInterface
+}
+
+// NewMockUserCacheInterface creates a new mock instance.
+func NewMockUserCacheInterface(ctrl *gomock.Controller) *MockUserCacheInterface {
+	mock := &MockUserCacheInterface{ctrl: ctrl}
+	mock.recorder = &MockUserCacheInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserCacheInterface) EXPECT() *MockUserCacheInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetUserCacheStats mocks base method.
+func (m *MockUserCacheInterface) GetUserCacheStats() UserCacheStatsOutput {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCacheStats")
+	ret0, _ := ret[0].(UserCacheStatsOutput)
+	return ret0
+}
+
+// GetUserCacheStats indicates an expected call of GetUserCacheStats.
+func (mr *MockUserCacheInterfaceMockRecorder) GetUserCacheStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCacheStats", reflect.TypeOf((*MockUserCacheInterface)(nil).GetUserCacheStats))
+}
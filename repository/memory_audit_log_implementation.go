@@ -0,0 +1,141 @@
+// This file contains the in-memory repository implementation of the audit log.
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+func (r MemoryRepository) AppendAuditLog(ctx context.Context, input AppendAuditLogInput) (*AppendAuditLogOutput, error) {
+
+	// Hash what Repository.AppendAuditLog would read back from Postgres.
+	input.CreatedAt = input.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	r.store.auditLogsMu.Lock()
+	defer r.store.auditLogsMu.Unlock()
+
+	previousHash := GenesisAuditLogHash
+
+	if len(r.store.auditLogs) > 0 {
+		previousHash = r.store.auditLogs[len(r.store.auditLogs)-1].Hash
+	}
+
+	auditLog := AuditLogOutput{
+		Id:            int64(len(r.store.auditLogs)) + 1,
+		ActorUserId:   copyId(input.ActorUserId),
+		SubjectUserId: copyId(input.SubjectUserId),
+		Action:        input.Action,
+		Changes:       input.Changes,
+		RequestId:     input.RequestId,
+		IpAddress:     input.IpAddress,
+		PreviousHash:  previousHash,
+		Hash:          ComputeAuditLogHash(previousHash, input),
+		CreatedAt:     input.CreatedAt,
+	}
+
+	if input.PersonalChanges != "" {
+		auditLog.PersonalChanges = input.PersonalChanges
+		auditLog.PersonalIpAddress = input.PersonalIpAddress
+	}
+
+	r.store.auditLogs = append(r.store.auditLogs, auditLog)
+
+	// The entries are appended in transactions, which hold the users lock until they are over, so the entry is still
+	// the latest one when its transaction is rolled back.
+	r.onRollback(func() {
+
+		r.store.auditLogsMu.Lock()
+		defer r.store.auditLogsMu.Unlock()
+
+		r.store.auditLogs = r.store.auditLogs[:auditLog.Id-1]
+	})
+
+	output := &AppendAuditLogOutput{
+		Id:   auditLog.Id,
+		Hash: auditLog.Hash,
+	}
+
+	return output, nil
+}
+
+func (r MemoryRepository) GetAuditLogs(ctx context.Context, input GetAuditLogsInput) (*GetAuditLogsOutput, error) {
+
+	r.store.auditLogsMu.Lock()
+	defer r.store.auditLogsMu.Unlock()
+
+	auditLogs := []AuditLogOutput{}
+
+	for i := len(r.store.auditLogs) - 1; i >= 0; i-- {
+
+		auditLog := r.store.auditLogs[i]
+
+		if input.ActorUserId != nil && (auditLog.ActorUserId == nil || *auditLog.ActorUserId != *input.ActorUserId) {
+			continue
+		}
+
+		if input.SubjectUserId != nil && (auditLog.SubjectUserId == nil || *auditLog.SubjectUserId != *input.SubjectUserId) {
+			continue
+		}
+
+		if input.Action != "" && auditLog.Action != input.Action {
+			continue
+		}
+
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	output := &GetAuditLogsOutput{
+		AuditLogs: copyAuditLogs(paginate(auditLogs, input.Limit, input.Offset)),
+		Total:     int64(len(auditLogs)),
+	}
+
+	return output, nil
+}
+
+func (r MemoryRepository) GetAuditLogsAfterId(ctx context.Context, input GetAuditLogsAfterIdInput) ([]AuditLogOutput, error) {
+
+	r.store.auditLogsMu.Lock()
+	defer r.store.auditLogsMu.Unlock()
+
+	// Ids start at 1 without gaps, so the entry after AfterId is at index AfterId.
+	offset := int(input.AfterId)
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return copyAuditLogs(paginate(r.store.auditLogs, input.Limit, offset)), nil
+}
+
+// anonymizeAuditLogs deletes the personal data of the entries about the user and the user's ip address from the
+// entries the user acted in, like Repository.AnonymizeUser.
+func (r MemoryRepository) anonymizeAuditLogs(userId int64) {
+
+	r.store.auditLogsMu.Lock()
+	defer r.store.auditLogsMu.Unlock()
+
+	for i, auditLog := range r.store.auditLogs {
+
+		if auditLog.SubjectUserId != nil && *auditLog.SubjectUserId == userId {
+			r.store.auditLogs[i].PersonalChanges = ""
+			r.store.auditLogs[i].PersonalIpAddress = ""
+		}
+
+		if auditLog.ActorUserId != nil && *auditLog.ActorUserId == userId {
+			r.store.auditLogs[i].PersonalIpAddress = ""
+		}
+	}
+}
+
+func copyAuditLogs(auditLogs []AuditLogOutput) []AuditLogOutput {
+
+	copied := []AuditLogOutput{}
+
+	for _, auditLog := range auditLogs {
+		auditLog.ActorUserId = copyId(auditLog.ActorUserId)
+		auditLog.SubjectUserId = copyId(auditLog.SubjectUserId)
+		copied = append(copied, auditLog)
+	}
+
+	return copied
+}
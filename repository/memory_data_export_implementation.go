@@ -0,0 +1,210 @@
+// This file contains the in-memory repository implementation of the personal data exports.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type memoryDataExport struct {
+	DataExportOutput
+	DownloadTokenHash string
+}
+
+func (r MemoryRepository) InsertDataExport(ctx context.Context, input InsertDataExportInput) (*InsertDataExportOutput, error) {
+
+	r.store.dataExportsMu.Lock()
+	defer r.store.dataExportsMu.Unlock()
+
+	for _, dataExport := range r.store.dataExports {
+		if dataExport.DownloadTokenHash == input.DownloadTokenHash {
+			return nil, fmt.Errorf("Data export download token hash is already used")
+		}
+
+		if dataExport.UserId == input.UserId && (dataExport.Status == "pending" || dataExport.Status == "processing") {
+			return nil, &UniqueViolationError{Field: UniqueFieldPendingDataExport}
+		}
+	}
+
+	r.store.lastDataExportId++
+
+	r.store.dataExports = append(r.store.dataExports, memoryDataExport{
+		DataExportOutput: DataExportOutput{
+			Id:          r.store.lastDataExportId,
+			UserId:      input.UserId,
+			Status:      "pending",
+			IsEncrypted: input.IsEncrypted,
+			ExpiredAt:   input.ExpiredAt,
+			CreatedAt:   time.Now(),
+		},
+		DownloadTokenHash: input.DownloadTokenHash,
+	})
+
+	dataExportId := r.store.lastDataExportId
+
+	r.onRollback(func() {
+
+		r.store.dataExportsMu.Lock()
+		defer r.store.dataExportsMu.Unlock()
+
+		for i, dataExport := range r.store.dataExports {
+			if dataExport.Id == dataExportId {
+				r.store.dataExports = append(r.store.dataExports[:i], r.store.dataExports[i+1:]...)
+				break
+			}
+		}
+	})
+
+	output := &InsertDataExportOutput{
+		Id: dataExportId,
+	}
+
+	return output, nil
+}
+
+// GetDataExportById only returns the export when it belongs to the given user.
+func (r MemoryRepository) GetDataExportById(ctx context.Context, input GetDataExportByIdInput) (*DataExportOutput, error) {
+
+	return r.findDataExport(func(dataExport memoryDataExport) bool {
+		return dataExport.Id == input.Id && dataExport.UserId == input.UserId
+	}), nil
+}
+
+func (r MemoryRepository) GetDataExportByTokenHash(ctx context.Context, input GetDataExportByTokenHashInput) (*DataExportOutput, error) {
+
+	return r.findDataExport(func(dataExport memoryDataExport) bool {
+		return dataExport.DownloadTokenHash == input.DownloadTokenHash
+	}), nil
+}
+
+func (r MemoryRepository) UpdateDataExportStatus(ctx context.Context, input UpdateDataExportStatusInput) (*UpdateDataExportOutput, error) {
+
+	r.store.dataExportsMu.Lock()
+	defer r.store.dataExportsMu.Unlock()
+
+	output := &UpdateDataExportOutput{}
+	now := time.Now()
+
+	for i, dataExport := range r.store.dataExports {
+
+		if dataExport.Id != input.Id || dataExport.Status != input.FromStatus || dataExport.ExpiredAt.After(now) == false {
+			continue
+		}
+
+		dataExport.Status = input.Status
+		dataExport.FileName = input.FileName
+		dataExport.FailureReason = input.FailureReason
+
+		if input.Status == "completed" {
+			dataExport.CompletedAt = &now
+		}
+
+		r.store.dataExports[i] = dataExport
+		output.IsSuccessUpdate = true
+	}
+
+	return output, nil
+}
+
+func (r MemoryRepository) GetExpiredDataExports(ctx context.Context, input GetExpiredDataExportsInput) (*GetDataExportsOutput, error) {
+
+	r.store.dataExportsMu.Lock()
+	defer r.store.dataExportsMu.Unlock()
+
+	now := time.Now()
+	expiredDataExports := []DataExportOutput{}
+
+	for _, dataExport := range r.store.dataExports {
+		if dataExport.FileName != "" && dataExport.ExpiredAt.After(now) == false {
+			found := dataExport.DataExportOutput
+			found.CompletedAt = copyTime(found.CompletedAt)
+			expiredDataExports = append(expiredDataExports, found)
+		}
+	}
+
+	sort.Slice(expiredDataExports, func(i, j int) bool {
+		return expiredDataExports[i].ExpiredAt.Before(expiredDataExports[j].ExpiredAt)
+	})
+
+	if len(expiredDataExports) > input.Limit {
+		expiredDataExports = expiredDataExports[:input.Limit]
+	}
+
+	return &GetDataExportsOutput{DataExports: expiredDataExports}, nil
+}
+
+func (r MemoryRepository) ClearDataExportFile(ctx context.Context, input ClearDataExportFileInput) (*UpdateDataExportOutput, error) {
+
+	r.store.dataExportsMu.Lock()
+	defer r.store.dataExportsMu.Unlock()
+
+	output := &UpdateDataExportOutput{}
+
+	for i, dataExport := range r.store.dataExports {
+		if dataExport.Id == input.Id && dataExport.FileName != "" {
+			r.store.dataExports[i].FileName = ""
+			output.IsSuccessUpdate = true
+		}
+	}
+
+	return output, nil
+}
+
+func (r MemoryRepository) FailStaleDataExports(ctx context.Context, input FailStaleDataExportsInput) (*FailStaleDataExportsOutput, error) {
+
+	r.store.dataExportsMu.Lock()
+	defer r.store.dataExportsMu.Unlock()
+
+	output := &FailStaleDataExportsOutput{}
+
+	for i, dataExport := range r.store.dataExports {
+
+		if dataExport.Status != "pending" && dataExport.Status != "processing" {
+			continue
+		}
+
+		if dataExport.CreatedAt.Before(input.CreatedBefore) == false {
+			continue
+		}
+
+		r.store.dataExports[i].Status = "failed"
+		r.store.dataExports[i].FailureReason = input.FailureReason
+		output.FailedCount++
+	}
+
+	return output, nil
+}
+
+// expireUserDataExports ends the download links of the user's exports, like Repository.AnonymizeUser.
+func (r MemoryRepository) expireUserDataExports(userId int64) {
+
+	r.store.dataExportsMu.Lock()
+	defer r.store.dataExportsMu.Unlock()
+
+	now := time.Now()
+
+	for i, dataExport := range r.store.dataExports {
+		if dataExport.UserId == userId && dataExport.ExpiredAt.After(now) {
+			r.store.dataExports[i].ExpiredAt = now
+		}
+	}
+}
+
+func (r MemoryRepository) findDataExport(match func(dataExport memoryDataExport) bool) *DataExportOutput {
+
+	r.store.dataExportsMu.Lock()
+	defer r.store.dataExportsMu.Unlock()
+
+	for _, dataExport := range r.store.dataExports {
+
+		if match(dataExport) {
+			found := dataExport.DataExportOutput
+			found.CompletedAt = copyTime(found.CompletedAt)
+			return &found
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,641 @@
+// This file contains the in-memory repository implementation, used to run the service without Postgres.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository keeps everything in memory with the semantics of Repository: unique phone numbers and
+// emails, auto increment ids and updated_at refreshed on every change of a user like the users trigger does.
+// All data is lost when the process stops.
+type MemoryRepository struct {
+	store *memoryStore
+	// tx is set on the copy of the repository handed to a WithinTransaction callback.
+	tx *memoryUserTables
+}
+
+// memoryStore guards every table with its own mutex. usersMu is held for the whole of a transaction,
+// so transactions on the users tables are serializable.
+type memoryStore struct {
+	usersMu    sync.Mutex
+	userTables *memoryUserTables
+
+	loginEventsMu    sync.Mutex
+	loginEvents      []LoginEventOutput
+	lastLoginEventId int64
+
+	auditLogsMu sync.Mutex
+	auditLogs   []AuditLogOutput
+
+	dataExportsMu    sync.Mutex
+	dataExports      []memoryDataExport
+	lastDataExportId int64
+}
+
+// memoryUserTables are the tables UserRepositoryInterface writes, a transaction works on a copy of them.
+type memoryUserTables struct {
+	users                   map[int64]GetUserByPhoneNumberOutput
+	lastUserId              int64
+	emailVerifications      map[int64]memoryEmailVerification
+	lastEmailVerificationId int64
+	// rollbacks undo what a transaction wrote to the other tables, which are not copied.
+	rollbacks []func()
+}
+
+type memoryEmailVerification struct {
+	GetEmailVerificationOutput
+	TokenHash string
+}
+
+func NewMemoryRepository() *MemoryRepository {
+
+	return &MemoryRepository{
+		store: &memoryStore{
+			userTables: &memoryUserTables{
+				users:              map[int64]GetUserByPhoneNumberOutput{},
+				emailVerifications: map[int64]memoryEmailVerification{},
+			},
+		},
+	}
+}
+
+func (t *memoryUserTables) clone() *memoryUserTables {
+
+	cloned := &memoryUserTables{
+		users:                   make(map[int64]GetUserByPhoneNumberOutput, len(t.users)),
+		lastUserId:              t.lastUserId,
+		emailVerifications:      make(map[int64]memoryEmailVerification, len(t.emailVerifications)),
+		lastEmailVerificationId: t.lastEmailVerificationId,
+	}
+
+	for id, user := range t.users {
+		cloned.users[id] = user
+	}
+
+	for id, emailVerification := range t.emailVerifications {
+		cloned.emailVerifications[id] = emailVerification
+	}
+
+	return cloned
+}
+
+// checkUnique returns a UniqueViolationError when another user than id already has the phone number or email.
+func (t *memoryUserTables) checkUnique(id int64, phoneNumber string, email string) error {
+
+	for _, user := range t.users {
+
+		if user.Id == id {
+			continue
+		}
+
+		if user.PhoneNumber == phoneNumber {
+			return &UniqueViolationError{Field: UniqueFieldPhoneNumber}
+		}
+
+		if email != "" && user.Email == email {
+			return &UniqueViolationError{Field: UniqueFieldEmail}
+		}
+	}
+
+	return nil
+}
+
+// onRollback registers undo to run when the transaction of the repository is rolled back.
+func (r MemoryRepository) onRollback(undo func()) {
+
+	if r.tx != nil {
+		r.tx.rollbacks = append(r.tx.rollbacks, undo)
+	}
+}
+
+// withUserTables runs fn on the transaction's tables, or on the shared tables while holding their lock.
+func (r MemoryRepository) withUserTables(fn func(tables *memoryUserTables) error) error {
+
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	r.store.usersMu.Lock()
+	defer r.store.usersMu.Unlock()
+
+	return fn(r.store.userTables)
+}
+
+// updateUser applies change to the user when it exists and matches, refreshing updated_at like the users trigger.
+func (r MemoryRepository) updateUser(id int64, change func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error)) (*UpdateUserOutput, error) {
+
+	output := &UpdateUserOutput{}
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		user, ok := tables.users[id]
+
+		if ok == false {
+			return nil
+		}
+
+		isChanged, err := change(&user, tables)
+
+		if err != nil || isChanged == false {
+			return err
+		}
+
+		user.UpdatedAt = time.Now()
+		tables.users[id] = user
+		output.IsSuccessUpdate = true
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// WithinTransaction runs fn on a copy of the users tables, the copy replaces the tables only when fn returns nil.
+// The login events, audit log entries and exports fn wrote are removed again when it returns an error.
+// Calling it on a repository that is already in a transaction runs fn in that transaction.
+func (r MemoryRepository) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	r.store.usersMu.Lock()
+	defer r.store.usersMu.Unlock()
+
+	txRepository := r
+	txRepository.tx = r.store.userTables.clone()
+
+	err := fn(txRepository)
+
+	if err != nil {
+
+		for i := len(txRepository.tx.rollbacks) - 1; i >= 0; i-- {
+			txRepository.tx.rollbacks[i]()
+		}
+
+		return err
+	}
+
+	txRepository.tx.rollbacks = nil
+
+	if opts.ReadOnly == false {
+		r.store.userTables = txRepository.tx
+	}
+
+	return nil
+}
+
+func (r MemoryRepository) GetById(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
+
+	var output *GetUserByIdOutput
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		user, ok := tables.users[input.Id]
+
+		if ok {
+			output = &GetUserByIdOutput{
+				Id:                  user.Id,
+				PhoneNumber:         user.PhoneNumber,
+				FullName:            user.FullName,
+				Email:               user.Email,
+				EmailVerifiedAt:     copyTime(user.EmailVerifiedAt),
+				Role:                user.Role,
+				Status:              user.Status,
+				StatusReason:        user.StatusReason,
+				StatusChangedAt:     copyTime(user.StatusChangedAt),
+				DeletionScheduledAt: copyTime(user.DeletionScheduledAt),
+				LoginSuccessCount:   user.LoginSuccessCount,
+				CreatedAt:           user.CreatedAt,
+				UpdatedAt:           user.UpdatedAt,
+			}
+		}
+
+		return nil
+	})
+
+	return output, err
+}
+
+func (r MemoryRepository) GetByPhoneNumberIncludePassword(ctx context.Context, input GetUserByPhoneNumberInput) (*GetUserByPhoneNumberOutput, error) {
+
+	var output *GetUserByPhoneNumberOutput
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		for _, user := range tables.users {
+
+			if user.PhoneNumber == input.PhoneNumber {
+				output = copyUser(user)
+				break
+			}
+		}
+
+		return nil
+	})
+
+	return output, err
+}
+
+func (r MemoryRepository) GetByEmailIncludePassword(ctx context.Context, input GetUserByEmailInput) (*GetUserByEmailOutput, error) {
+
+	var output *GetUserByEmailOutput
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		for _, user := range tables.users {
+
+			if user.Email != "" && user.Email == input.Email {
+				found := GetUserByEmailOutput(*copyUser(user))
+				output = &found
+				break
+			}
+		}
+
+		return nil
+	})
+
+	return output, err
+}
+
+func (r MemoryRepository) Insert(ctx context.Context, input InsertUserInput) (*InsertUserOutput, error) {
+
+	var output *InsertUserOutput
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		err := tables.checkUnique(0, input.PhoneNumber, input.Email)
+
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		tables.lastUserId++
+
+		tables.users[tables.lastUserId] = GetUserByPhoneNumberOutput{
+			Id:          tables.lastUserId,
+			PhoneNumber: input.PhoneNumber,
+			FullName:    input.FullName,
+			Email:       input.Email,
+			Password:    input.Password,
+			Role:        "user",
+			Status:      "active",
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+
+		output = &InsertUserOutput{
+			Id: tables.lastUserId,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (r MemoryRepository) Update(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error) {
+
+	fields, _, err := getUpdatedUserFields(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.updateUser(input.Id, func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error) {
+
+		for _, field := range fields {
+
+			if field == UserFieldPhoneNumber {
+				user.PhoneNumber = input.PhoneNumber
+			}
+
+			if field == UserFieldFullName {
+				user.FullName = input.FullName
+			}
+		}
+
+		return true, tables.checkUnique(user.Id, user.PhoneNumber, user.Email)
+	})
+}
+
+func (r MemoryRepository) IncrementLoginSuccessCount(ctx context.Context, input IncrementLoginSuccessCountInput) (*UpdateUserOutput, error) {
+
+	return r.updateUser(input.Id, func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error) {
+
+		if user.Status != "active" {
+			return false, nil
+		}
+
+		user.LoginSuccessCount++
+
+		return true, nil
+	})
+}
+
+func (r MemoryRepository) UpdateEmail(ctx context.Context, input UpdateUserEmailInput) (*UpdateUserOutput, error) {
+
+	return r.updateUser(input.Id, func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error) {
+		user.Email = input.Email
+		user.EmailVerifiedAt = nil
+		return true, tables.checkUnique(user.Id, user.PhoneNumber, user.Email)
+	})
+}
+
+func (r MemoryRepository) UpdateRole(ctx context.Context, input UpdateUserRoleInput) (*UpdateUserOutput, error) {
+
+	return r.updateUser(input.Id, func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error) {
+		user.Role = input.Role
+		return true, nil
+	})
+}
+
+func (r MemoryRepository) UpdateStatus(ctx context.Context, input UpdateUserStatusInput) (*UpdateUserOutput, error) {
+
+	return r.updateUser(input.Id, func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error) {
+
+		if user.Status != input.FromStatus || user.Status == "pending_deletion" {
+			return false, nil
+		}
+
+		now := time.Now()
+		user.Status = input.Status
+		user.StatusReason = input.Reason
+		user.StatusChangedAt = &now
+
+		return true, nil
+	})
+}
+
+func (r MemoryRepository) ScheduleDeletion(ctx context.Context, input ScheduleUserDeletionInput) (*UpdateUserOutput, error) {
+
+	return r.updateUser(input.Id, func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error) {
+
+		if user.Status != "active" {
+			return false, nil
+		}
+
+		now := time.Now()
+		deletionScheduledAt := now.Add(input.GracePeriod)
+		user.Status = "pending_deletion"
+		user.StatusReason = input.Reason
+		user.StatusChangedAt = &now
+		user.DeletionScheduledAt = &deletionScheduledAt
+
+		return true, nil
+	})
+}
+
+func (r MemoryRepository) CancelDeletion(ctx context.Context, input CancelUserDeletionInput) (*UpdateUserOutput, error) {
+
+	return r.updateUser(input.Id, func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error) {
+
+		if user.Status != "pending_deletion" {
+			return false, nil
+		}
+
+		now := time.Now()
+		user.Status = "active"
+		user.StatusReason = ""
+		user.StatusChangedAt = &now
+		user.DeletionScheduledAt = nil
+
+		return true, nil
+	})
+}
+
+func (r MemoryRepository) GetUsersDueForDeletion(ctx context.Context, input GetUsersDueForDeletionInput) (*GetUsersDueForDeletionOutput, error) {
+
+	output := &GetUsersDueForDeletionOutput{
+		UserIds: []int64{},
+	}
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		now := time.Now()
+		dueUsers := []GetUserByPhoneNumberOutput{}
+
+		for _, user := range tables.users {
+			if user.Status == "pending_deletion" && user.DeletionScheduledAt != nil && user.DeletionScheduledAt.After(now) == false {
+				dueUsers = append(dueUsers, user)
+			}
+		}
+
+		sort.Slice(dueUsers, func(i, j int) bool {
+			return dueUsers[i].DeletionScheduledAt.Before(*dueUsers[j].DeletionScheduledAt)
+		})
+
+		for i := 0; i < len(dueUsers) && i < input.Limit; i++ {
+			output.UserIds = append(output.UserIds, dueUsers[i].Id)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// AnonymizeUser mirrors Repository.AnonymizeUser, including the cleanup of the verifications, login events,
+// audit log personal data and exports.
+func (r MemoryRepository) AnonymizeUser(ctx context.Context, input AnonymizeUserInput) (*AnonymizeUserOutput, error) {
+
+	updateOutput, err := r.updateUser(input.Id, func(user *GetUserByPhoneNumberOutput, tables *memoryUserTables) (bool, error) {
+
+		now := time.Now()
+
+		if user.Status != input.FromStatus {
+			return false, nil
+		}
+
+		if user.Status == "pending_deletion" && (user.DeletionScheduledAt == nil || user.DeletionScheduledAt.After(now)) {
+			return false, nil
+		}
+
+		user.PhoneNumber = fmt.Sprintf("#%d", user.Id)
+		user.FullName = input.FullName
+		user.Email = ""
+		user.EmailVerifiedAt = nil
+		user.Password = ""
+		user.Status = "deleted"
+		user.StatusReason = input.Reason
+		user.StatusChangedAt = &now
+		user.DeletionScheduledAt = nil
+
+		for id, emailVerification := range tables.emailVerifications {
+			if emailVerification.UserId == user.Id {
+				delete(tables.emailVerifications, id)
+			}
+		}
+
+		return true, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if updateOutput.IsSuccessUpdate {
+		r.anonymizeLoginEvents(input.Id)
+		r.anonymizeAuditLogs(input.Id)
+		r.expireUserDataExports(input.Id)
+	}
+
+	return &AnonymizeUserOutput{IsAnonymized: updateOutput.IsSuccessUpdate}, nil
+}
+
+func (r MemoryRepository) InsertEmailVerification(ctx context.Context, input InsertEmailVerificationInput) (*InsertEmailVerificationOutput, error) {
+
+	var output *InsertEmailVerificationOutput
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		if _, ok := tables.users[input.UserId]; ok == false {
+			return fmt.Errorf("User %d does not exist", input.UserId)
+		}
+
+		for _, emailVerification := range tables.emailVerifications {
+			if emailVerification.TokenHash == input.TokenHash {
+				return fmt.Errorf("Email verification token hash is already used")
+			}
+		}
+
+		tables.lastEmailVerificationId++
+
+		tables.emailVerifications[tables.lastEmailVerificationId] = memoryEmailVerification{
+			GetEmailVerificationOutput: GetEmailVerificationOutput{
+				Id:        tables.lastEmailVerificationId,
+				UserId:    input.UserId,
+				Email:     input.Email,
+				ExpiredAt: input.ExpiredAt,
+				CreatedAt: time.Now(),
+			},
+			TokenHash: input.TokenHash,
+		}
+
+		output = &InsertEmailVerificationOutput{
+			Id: tables.lastEmailVerificationId,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (r MemoryRepository) GetEmailVerificationByTokenHash(ctx context.Context, input GetEmailVerificationByTokenHashInput) (*GetEmailVerificationOutput, error) {
+
+	var output *GetEmailVerificationOutput
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		for _, emailVerification := range tables.emailVerifications {
+
+			if emailVerification.TokenHash == input.TokenHash {
+				found := emailVerification.GetEmailVerificationOutput
+				found.VerifiedAt = copyTime(found.VerifiedAt)
+				output = &found
+				break
+			}
+		}
+
+		return nil
+	})
+
+	return output, err
+}
+
+// VerifyEmail mirrors Repository.VerifyEmail: the verification is used up even when the user's email changed since.
+func (r MemoryRepository) VerifyEmail(ctx context.Context, input VerifyEmailInput) (*VerifyEmailOutput, error) {
+
+	output := &VerifyEmailOutput{}
+
+	err := r.withUserTables(func(tables *memoryUserTables) error {
+
+		emailVerification, ok := tables.emailVerifications[input.EmailVerificationId]
+
+		if ok == false || emailVerification.VerifiedAt != nil {
+			return nil
+		}
+
+		now := time.Now()
+		emailVerification.VerifiedAt = &now
+		tables.emailVerifications[emailVerification.Id] = emailVerification
+
+		user, ok := tables.users[emailVerification.UserId]
+
+		if ok == false || user.Email != emailVerification.Email {
+			return nil
+		}
+
+		user.EmailVerifiedAt = &now
+		user.UpdatedAt = now
+		tables.users[user.Id] = user
+		output.IsVerified = true
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// GetPoolStats reports an empty pool, there are no database connections.
+func (r MemoryRepository) GetPoolStats() PoolStatsOutput {
+	return PoolStatsOutput{
+		CircuitBreakerState: CircuitBreakerClosed,
+	}
+}
+
+// Ping always succeeds, there is no database to reach.
+func (r MemoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// GetSchemaVersion returns 0, the in-memory repository has no migrations.
+func (r MemoryRepository) GetSchemaVersion(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func copyUser(user GetUserByPhoneNumberOutput) *GetUserByPhoneNumberOutput {
+
+	user.EmailVerifiedAt = copyTime(user.EmailVerifiedAt)
+	user.StatusChangedAt = copyTime(user.StatusChangedAt)
+	user.DeletionScheduledAt = copyTime(user.DeletionScheduledAt)
+
+	return &user
+}
+
+func copyTime(value *time.Time) *time.Time {
+
+	if value == nil {
+		return nil
+	}
+
+	copied := *value
+
+	return &copied
+}
@@ -0,0 +1,124 @@
+// This file contains the in-memory repository implementation of the login events.
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+func (r MemoryRepository) InsertLoginEvent(ctx context.Context, input InsertLoginEventInput) (*InsertLoginEventOutput, error) {
+
+	r.store.loginEventsMu.Lock()
+	defer r.store.loginEventsMu.Unlock()
+
+	r.store.lastLoginEventId++
+
+	r.store.loginEvents = append(r.store.loginEvents, LoginEventOutput{
+		Id:            r.store.lastLoginEventId,
+		UserId:        copyId(input.UserId),
+		IsSuccess:     input.IsSuccess,
+		FailureReason: input.FailureReason,
+		Method:        input.Method,
+		IpAddress:     input.IpAddress,
+		UserAgent:     input.UserAgent,
+		CreatedAt:     time.Now(),
+	})
+
+	loginEventId := r.store.lastLoginEventId
+
+	r.onRollback(func() {
+
+		r.store.loginEventsMu.Lock()
+		defer r.store.loginEventsMu.Unlock()
+
+		for i, loginEvent := range r.store.loginEvents {
+			if loginEvent.Id == loginEventId {
+				r.store.loginEvents = append(r.store.loginEvents[:i], r.store.loginEvents[i+1:]...)
+				break
+			}
+		}
+	})
+
+	output := &InsertLoginEventOutput{
+		Id: loginEventId,
+	}
+
+	return output, nil
+}
+
+func (r MemoryRepository) GetLoginEventsByUserId(ctx context.Context, input GetLoginEventsByUserIdInput) (*GetLoginEventsByUserIdOutput, error) {
+
+	r.store.loginEventsMu.Lock()
+	defer r.store.loginEventsMu.Unlock()
+
+	loginEvents := []LoginEventOutput{}
+
+	for _, loginEvent := range r.store.loginEvents {
+		if loginEvent.UserId != nil && *loginEvent.UserId == input.UserId {
+			loginEvents = append(loginEvents, loginEvent)
+		}
+	}
+
+	sort.Slice(loginEvents, func(i, j int) bool {
+
+		if loginEvents[i].CreatedAt.Equal(loginEvents[j].CreatedAt) {
+			return loginEvents[i].Id > loginEvents[j].Id
+		}
+
+		return loginEvents[i].CreatedAt.After(loginEvents[j].CreatedAt)
+	})
+
+	output := &GetLoginEventsByUserIdOutput{
+		LoginEvents: []LoginEventOutput{},
+		Total:       int64(len(loginEvents)),
+	}
+
+	for _, loginEvent := range paginate(loginEvents, input.Limit, input.Offset) {
+		loginEvent.UserId = copyId(loginEvent.UserId)
+		output.LoginEvents = append(output.LoginEvents, loginEvent)
+	}
+
+	return output, nil
+}
+
+// anonymizeLoginEvents clears the ip address and user agent of the user's login events, like Repository.AnonymizeUser.
+func (r MemoryRepository) anonymizeLoginEvents(userId int64) {
+
+	r.store.loginEventsMu.Lock()
+	defer r.store.loginEventsMu.Unlock()
+
+	for i, loginEvent := range r.store.loginEvents {
+		if loginEvent.UserId != nil && *loginEvent.UserId == userId {
+			r.store.loginEvents[i].IpAddress = ""
+			r.store.loginEvents[i].UserAgent = ""
+		}
+	}
+}
+
+// paginate returns the page of items selected by LIMIT limit OFFSET offset.
+func paginate[T any](items []T, limit int, offset int) []T {
+
+	if offset >= len(items) {
+		return nil
+	}
+
+	items = items[offset:]
+
+	if limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
+
+func copyId(id *int64) *int64 {
+
+	if id == nil {
+		return nil
+	}
+
+	copied := *id
+
+	return &copied
+}
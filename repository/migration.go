@@ -0,0 +1,478 @@
+// This file contains the versioned schema migrations and the migrator that applies them.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationAdvisoryLockKey keeps app replicas that start at the same time from migrating concurrently.
+const migrationAdvisoryLockKey = 7_261_002
+
+//go:embed migrations/*.sql sqlite_migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationDialect holds what differs between the databases the migrator supports.
+type migrationDialect struct {
+	directory        string
+	createTableQuery string
+	// tableExistsQuery tells whether schema_migrations exists, without creating it.
+	tableExistsQuery string
+	// lockQuery and unlockQuery are empty when the database has no advisory locks.
+	lockQuery   string
+	unlockQuery string
+}
+
+var migrationDialects = map[string]migrationDialect{
+	DriverPostgres: {
+		directory: "migrations",
+		createTableQuery: `CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version    BIGINT PRIMARY KEY,
+			name       VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		tableExistsQuery: `SELECT to_regclass('schema_migrations') IS NOT NULL;`,
+		lockQuery:        `SELECT pg_advisory_lock($1);`,
+		unlockQuery:      `SELECT pg_advisory_unlock($1);`,
+	},
+	// SQLite has no advisory locks, run checks the applied versions again inside each migration's transaction instead.
+	DriverSqlite: {
+		directory: "sqlite_migrations",
+		createTableQuery: `CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version    INTEGER PRIMARY KEY,
+			name       VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		tableExistsQuery: `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations';`,
+	},
+}
+
+// migrationFileNamePattern matches e.g. 0001_create_users.up.sql.
+var migrationFileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version int64
+	Name    string
+	// AppliedAt is nil while the migration is pending.
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    migrationDialect
+	migrations []Migration
+}
+
+type NewMigratorOptions struct {
+	Db *sql.DB
+	// Driver selects the migrations of DriverPostgres or DriverSqlite, DriverPostgres when empty.
+	Driver string
+}
+
+func NewMigrator(opts NewMigratorOptions) (*Migrator, error) {
+
+	driver := opts.Driver
+
+	if driver == "" {
+		driver = DriverPostgres
+	}
+
+	dialect, ok := migrationDialects[driver]
+
+	if ok == false {
+		return nil, fmt.Errorf("There are no migrations for driver %s", driver)
+	}
+
+	migrations, err := loadMigrations(embeddedMigrations, dialect.directory)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         opts.Db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+// loadMigrations reads the up and down files of dir, sorted by version.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+
+	entries, err := fs.ReadDir(fsys, dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := map[int64]*Migration{}
+
+	for _, entry := range entries {
+
+		matches := migrationFileNamePattern.FindStringSubmatch(entry.Name())
+
+		if matches == nil {
+			return nil, fmt.Errorf("Migration file %s must be named <version>_<name>.<up|down>.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := migrationsByVersion[version]
+
+		if ok == false {
+			migration = &Migration{Version: version, Name: matches[2]}
+			migrationsByVersion[version] = migration
+		}
+
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("Migration version %d is used by %s and %s", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+
+	for _, migration := range migrationsByVersion {
+
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("Migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// LatestVersion is the version Up migrates to, 0 when there are no migrations.
+func (m *Migrator) LatestVersion() int64 {
+
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.LatestVersion())
+}
+
+// Down rolls back the latest applied migration, it does nothing when no migration is applied.
+func (m *Migrator) Down(ctx context.Context) ([]Migration, error) {
+
+	var migrated []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+
+		appliedVersions, err := m.getAppliedVersions(ctx, conn)
+
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+
+			if _, ok := appliedVersions[m.migrations[i].Version]; ok == false {
+				continue
+			}
+
+			isRun, err := m.run(ctx, conn, m.migrations[i], false)
+
+			if err != nil {
+				return err
+			}
+
+			if isRun {
+				migrated = append(migrated, m.migrations[i])
+			}
+
+			return nil
+		}
+
+		return nil
+	})
+
+	return migrated, err
+}
+
+// To applies the pending migrations up to version and rolls back the applied ones above it,
+// version 0 rolls back every migration. It returns the migrations that were run, in the order they were run.
+func (m *Migrator) To(ctx context.Context, version int64) ([]Migration, error) {
+
+	if version != 0 && m.hasVersion(version) == false {
+		return nil, fmt.Errorf("Migration version %d does not exist", version)
+	}
+
+	var migrated []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+
+		appliedVersions, err := m.getAppliedVersions(ctx, conn)
+
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+
+			migration := m.migrations[i]
+
+			if _, ok := appliedVersions[migration.Version]; ok == false || migration.Version <= version {
+				continue
+			}
+
+			isRun, err := m.run(ctx, conn, migration, false)
+
+			if err != nil {
+				return err
+			}
+
+			if isRun {
+				migrated = append(migrated, migration)
+			}
+		}
+
+		for _, migration := range m.migrations {
+
+			if _, ok := appliedVersions[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+
+			isRun, err := m.run(ctx, conn, migration, true)
+
+			if err != nil {
+				return err
+			}
+
+			if isRun {
+				migrated = append(migrated, migration)
+			}
+		}
+
+		return nil
+	})
+
+	return migrated, err
+}
+
+// Status lists every known migration with the time it was applied. It only reads, so it neither waits for a running
+// migration nor creates schema_migrations, every migration is pending while that table does not exist.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+
+	conn, err := m.db.Conn(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	var tableExists bool
+
+	err = conn.QueryRowContext(ctx, m.dialect.tableExistsQuery).Scan(&tableExists)
+
+	if err != nil {
+		return nil, err
+	}
+
+	appliedVersions := map[int64]time.Time{}
+
+	if tableExists {
+
+		appliedVersions, err = m.getAppliedVersions(ctx, conn)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var statuses []MigrationStatus
+
+	for _, migration := range m.migrations {
+
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+
+		if appliedAt, ok := appliedVersions[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// getSchemaVersion reads the latest applied version without the migration lock, it fails while
+// schema_migrations does not exist yet.
+func getSchemaVersion(ctx context.Context, db *sql.DB) (int64, error) {
+
+	var version int64
+
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&version)
+
+	return version, err
+}
+
+func (m *Migrator) hasVersion(version int64) bool {
+
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock, when the database has one,
+// a second migrator waits until the first one is done.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+
+	conn, err := m.db.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if m.dialect.lockQuery != "" {
+
+		_, err = conn.ExecContext(ctx, m.dialect.lockQuery, migrationAdvisoryLockKey)
+
+		if err != nil {
+			return err
+		}
+
+		// The lock belongs to the session, so it is released even when ctx is already done.
+		defer conn.ExecContext(context.Background(), m.dialect.unlockQuery, migrationAdvisoryLockKey)
+	}
+
+	_, err = conn.ExecContext(ctx, m.dialect.createTableQuery)
+
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) getAppliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations;`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	appliedVersions := map[int64]time.Time{}
+
+	for rows.Next() {
+
+		var version int64
+		var appliedAt time.Time
+
+		err = rows.Scan(&version, &appliedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		appliedVersions[version] = appliedAt
+	}
+
+	return appliedVersions, rows.Err()
+}
+
+// run applies or rolls back a single migration in its own transaction, together with its schema_migrations row.
+// It reports whether it ran the migration, it does not when another migrator already did.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, isUp bool) (bool, error) {
+
+	tx, err := conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	// Without an advisory lock another migrator may have run the migration since the applied versions were read.
+	var appliedCount int
+
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = $1;`, migration.Version).Scan(&appliedCount)
+
+	if err != nil {
+		return false, err
+	}
+
+	if (appliedCount > 0) == isUp {
+		return false, nil
+	}
+
+	script := migration.Down
+
+	if isUp {
+		script = migration.Up
+	}
+
+	_, err = tx.ExecContext(ctx, script)
+
+	if err != nil {
+		return false, fmt.Errorf("Migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if isUp {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`, migration.Version, migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1;`, migration.Version)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
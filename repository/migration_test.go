@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "When given up and down files, it will return the migrations sorted by version",
+			files: fstest.MapFS{
+				"migrations/0002_create_posts.up.sql":   {Data: []byte("CREATE TABLE posts ();")},
+				"migrations/0002_create_posts.down.sql": {Data: []byte("DROP TABLE posts;")},
+				"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+				"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+			},
+			want: []Migration{
+				{Version: 1, Name: "create_users", Up: "CREATE TABLE users ();", Down: "DROP TABLE users;"},
+				{Version: 2, Name: "create_posts", Up: "CREATE TABLE posts ();", Down: "DROP TABLE posts;"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "When a migration has no down file, it will return error",
+			files: fstest.MapFS{
+				"migrations/0001_create_users.up.sql": {Data: []byte("CREATE TABLE users ();")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "When two migrations share a version, it will return error",
+			files: fstest.MapFS{
+				"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+				"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+				"migrations/0001_create_posts.up.sql":   {Data: []byte("CREATE TABLE posts ();")},
+				"migrations/0001_create_posts.down.sql": {Data: []byte("DROP TABLE posts;")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "When a file is not named like a migration, it will return error",
+			files: fstest.MapFS{
+				"migrations/create_users.sql": {Data: []byte("CREATE TABLE users ();")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadMigrations(tt.files, "migrations")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadMigrations() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMigrator(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		driver string
+	}{
+		{name: "postgres", driver: DriverPostgres},
+		{name: "sqlite", driver: DriverSqlite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			migrator, err := NewMigrator(NewMigratorOptions{Driver: tt.driver})
+
+			if err != nil {
+				t.Fatalf("NewMigrator() error = %v", err)
+			}
+
+			if migrator.LatestVersion() != int64(len(migrator.migrations)) {
+				t.Errorf("NewMigrator() versions are not numbered 1 to %d", len(migrator.migrations))
+			}
+		})
+	}
+}
+
+// TestNewMigrator_SameVersions keeps the SQLite migrations in step with the Postgres ones.
+func TestNewMigrator_SameVersions(t *testing.T) {
+
+	postgresMigrator, err := NewMigrator(NewMigratorOptions{Driver: DriverPostgres})
+
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	sqliteMigrator, err := NewMigrator(NewMigratorOptions{Driver: DriverSqlite})
+
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	if len(postgresMigrator.migrations) != len(sqliteMigrator.migrations) {
+		t.Fatalf("NewMigrator() got %d SQLite migrations, want %d", len(sqliteMigrator.migrations), len(postgresMigrator.migrations))
+	}
+
+	for i, migration := range postgresMigrator.migrations {
+		if sqliteMigrator.migrations[i].Name != migration.Name {
+			t.Errorf("NewMigrator() SQLite migration %d is %s, want %s", migration.Version, sqliteMigrator.migrations[i].Name, migration.Name)
+		}
+	}
+}
+
+func TestNewMigrator_UnknownDriver(t *testing.T) {
+
+	_, err := NewMigrator(NewMigratorOptions{Driver: "mysql"})
+
+	if err == nil {
+		t.Errorf("NewMigrator() error = nil, want error")
+	}
+}
+
+func TestSqliteRepository_GetSchemaVersion(t *testing.T) {
+
+	repository := openTestSqliteRepository(t)
+
+	migrator, err := NewMigrator(NewMigratorOptions{Db: repository.Db, Driver: DriverSqlite})
+
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		version int64
+	}{
+		{name: "When every migration is applied, it will return the latest version", version: migrator.LatestVersion()},
+		{name: "When a migration is rolled back, it will return the version before it", version: migrator.LatestVersion() - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			_, err := migrator.To(context.Background(), tt.version)
+
+			if err != nil {
+				t.Fatalf("Migrator.To() error = %v", err)
+			}
+
+			got, err := repository.GetSchemaVersion(context.Background())
+
+			if err != nil {
+				t.Fatalf("GetSchemaVersion() error = %v", err)
+			}
+
+			if got != tt.version {
+				t.Errorf("GetSchemaVersion() = %d, want %d", got, tt.version)
+			}
+		})
+	}
+}
+
+func TestSqliteMigrator_Status(t *testing.T) {
+
+	ctx := context.Background()
+
+	db, err := OpenSqliteDatabase(ctx, OpenSqliteDatabaseOptions{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		BusyTimeout:  5 * time.Second,
+		MaxOpenConns: 1,
+	})
+
+	if err != nil {
+		t.Fatalf("OpenSqliteDatabase() error = %v", err)
+	}
+
+	defer db.Close()
+
+	migrator, err := NewMigrator(NewMigratorOptions{Db: db, Driver: DriverSqlite})
+
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Errorf("Status() of a new database has %d applied, want every migration pending", status.Version)
+		}
+	}
+
+	var tableCount int
+
+	err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations';`).Scan(&tableCount)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tableCount != 0 {
+		t.Errorf("Status() created schema_migrations, want it to only read")
+	}
+
+	_, err = migrator.To(ctx, 2)
+
+	if err != nil {
+		t.Fatalf("Migrator.To() error = %v", err)
+	}
+
+	statuses, err = migrator.Status(ctx)
+
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	for _, status := range statuses {
+		if isApplied := status.AppliedAt != nil; isApplied != (status.Version <= 2) {
+			t.Errorf("Status() migration %d applied = %v, want %v", status.Version, isApplied, status.Version <= 2)
+		}
+	}
+}
+
+func TestSqliteMigrator_run(t *testing.T) {
+
+	ctx := context.Background()
+
+	db, err := OpenSqliteDatabase(ctx, OpenSqliteDatabaseOptions{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		BusyTimeout:  5 * time.Second,
+		MaxOpenConns: 1,
+	})
+
+	if err != nil {
+		t.Fatalf("OpenSqliteDatabase() error = %v", err)
+	}
+
+	defer db.Close()
+
+	migrator, err := NewMigrator(NewMigratorOptions{Db: db, Driver: DriverSqlite})
+
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	migrated, err := migrator.To(ctx, 1)
+
+	if err != nil || len(migrated) != 1 {
+		t.Fatalf("Migrator.To() migrated = %v, error = %v", migrated, err)
+	}
+
+	conn, err := db.Conn(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	// As if another instance applied the migration after the applied versions were read.
+	isRun, err := migrator.run(ctx, conn, migrator.migrations[0], true)
+
+	if err != nil || isRun {
+		t.Errorf("run() of an applied migration isRun = %v, error = %v, want false", isRun, err)
+	}
+
+	isRun, err = migrator.run(ctx, conn, migrator.migrations[0], false)
+
+	if err != nil || isRun == false {
+		t.Errorf("run() rolling back an applied migration isRun = %v, error = %v, want true", isRun, err)
+	}
+}
+
+// baselineSchema is the former database.sql, without its ALTER DATABASE that names the database.
+const baselineSchema = `
+CREATE TABLE users
+(
+    id                  BIGSERIAL PRIMARY KEY,
+    phone_number        VARCHAR(13)  NOT NULL UNIQUE,
+    full_name           VARCHAR(60)  NOT NULL,
+    password            VARCHAR(255) NOT NULL,
+    login_success_count BIGINT    DEFAULT 0,
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX phone_number_unique_index ON users (phone_number);
+
+CREATE FUNCTION update_updated_at_users_task()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER update_users_updated_at
+BEFORE UPDATE
+ON
+   users
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at_users_task();
+`
+
+// TestMigrator_UpOnBaselineSchema migrates a database created from the former database.sql, in a schema of its own.
+func TestMigrator_UpOnBaselineSchema(t *testing.T) {
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+
+	// A single connection keeps the search_path of the schema for every statement.
+	db, err := OpenDatabase(ctx, OpenDatabaseOptions{
+		Dsn:            dsn,
+		MaxOpenConns:   1,
+		MaxIdleConns:   1,
+		ConnectTimeout: 10 * time.Second,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	schema := fmt.Sprintf("migration_baseline_test_%d", time.Now().UnixNano())
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(`CREATE SCHEMA %s; SET search_path TO %s;`, schema, schema))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.ExecContext(context.Background(), fmt.Sprintf(`DROP SCHEMA %s CASCADE;`, schema))
+
+	_, err = db.ExecContext(ctx, baselineSchema)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO users (phone_number, full_name, password) VALUES ('+628123456789', 'Baseline User', 'hash');`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := NewMigrator(NewMigratorOptions{Db: db})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = migrator.Up(ctx)
+
+	if err != nil {
+		t.Fatalf("Migrator.Up() error = %v", err)
+	}
+
+	// The columns of every later feature exist, the existing user got their defaults.
+	var role, status string
+	var email sql.NullString
+	var deletionScheduledAt sql.NullTime
+
+	err = db.QueryRowContext(ctx, `SELECT email, role, status, deletion_scheduled_at FROM users WHERE phone_number = '+628123456789';`).
+		Scan(&email, &role, &status, &deletionScheduledAt)
+
+	if err != nil {
+		t.Fatalf("Migrator.Up() did not add the columns: %v", err)
+	}
+
+	if email.Valid || role != "user" || status != "active" || deletionScheduledAt.Valid {
+		t.Errorf("Migrator.Up() user = %v %s %s %v, want no email, user, active and no deletion", email, role, status, deletionScheduledAt)
+	}
+
+	_, err = db.ExecContext(ctx, `UPDATE users SET status = 'unknown';`)
+
+	if err == nil {
+		t.Errorf("Migrator.Up() did not add the status check")
+	}
+}
@@ -0,0 +1,9 @@
+DROP TABLE users;
+
+DROP FUNCTION update_updated_at_users_task();
+
+DO $$
+BEGIN
+    EXECUTE format('ALTER DATABASE %I RESET timezone', current_database());
+END;
+$$;
@@ -0,0 +1,38 @@
+-- The users table of the former database.sql, so a database created from it can adopt the migrations: IF NOT EXISTS
+-- skips what it already has. The columns added since then follow in their own migrations.
+
+DO $$
+BEGIN
+    EXECUTE format('ALTER DATABASE %I SET timezone TO %L', current_database(), 'Asia/Jakarta');
+END;
+$$;
+
+CREATE TABLE IF NOT EXISTS users
+(
+    id                  BIGSERIAL PRIMARY KEY,
+    phone_number        VARCHAR(13)  NOT NULL UNIQUE,
+    full_name           VARCHAR(60)  NOT NULL,
+    password            VARCHAR(255) NOT NULL,
+    login_success_count BIGINT    DEFAULT 0,
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS phone_number_unique_index ON users (phone_number);
+
+CREATE OR REPLACE FUNCTION update_updated_at_users_task()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+
+CREATE TRIGGER update_users_updated_at
+BEFORE UPDATE
+ON
+   users
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at_users_task();
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS email_verifications
+(
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT       NOT NULL REFERENCES users (id),
+    email       VARCHAR(255) NOT NULL,
+    token_hash  VARCHAR(64)  NOT NULL UNIQUE,
+    expired_at  TIMESTAMP    NOT NULL,
+    verified_at TIMESTAMP    NULL,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS email_verifications_user_id_index ON email_verifications (user_id);
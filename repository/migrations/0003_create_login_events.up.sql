@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS login_events
+(
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        BIGINT       NULL REFERENCES users (id),
+    is_success     BOOLEAN      NOT NULL,
+    failure_reason VARCHAR(30)  NULL,
+    method         VARCHAR(20)  NOT NULL,
+    ip_address     VARCHAR(45)  NOT NULL,
+    user_agent     VARCHAR(255) NOT NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS login_events_user_id_created_at_index ON login_events (user_id, created_at DESC);
@@ -0,0 +1,18 @@
+-- Personal data exports. The archive itself is stored outside the database (file_name),
+-- the download link token is only kept as a hash.
+CREATE TABLE IF NOT EXISTS data_exports
+(
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT       NOT NULL REFERENCES users (id),
+    status              VARCHAR(20)  NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    is_encrypted        BOOLEAN      NOT NULL DEFAULT FALSE,
+    download_token_hash VARCHAR(64)  NOT NULL UNIQUE,
+    file_name           VARCHAR(255) NULL,
+    failure_reason      VARCHAR(255) NULL,
+    expired_at          TIMESTAMPTZ  NOT NULL,
+    completed_at        TIMESTAMPTZ  NULL,
+    created_at          TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS data_exports_user_id_index ON data_exports (user_id);
@@ -0,0 +1,4 @@
+-- DROP TABLE does not fire the truncate trigger, so the append-only rule does not block the rollback.
+DROP TABLE audit_logs;
+
+DROP FUNCTION prevent_audit_logs_modification_task();
@@ -0,0 +1,43 @@
+-- Append-only audit log. Every entry stores the hash of the previous entry, see repository.ComputeAuditLogHash.
+-- changes is JSON (not JSONB) on purpose: the stored text must stay byte-identical to the hashed text.
+CREATE TABLE IF NOT EXISTS audit_logs
+(
+    id              BIGSERIAL PRIMARY KEY,
+    actor_user_id   BIGINT       NULL REFERENCES users (id),
+    subject_user_id BIGINT       NULL REFERENCES users (id),
+    action          VARCHAR(40)  NOT NULL,
+    changes         JSON         NOT NULL,
+    request_id      VARCHAR(64)  NOT NULL,
+    ip_address      VARCHAR(45)  NOT NULL,
+    previous_hash   VARCHAR(64)  NOT NULL,
+    hash            VARCHAR(64)  NOT NULL UNIQUE,
+    created_at      TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_subject_user_id_index ON audit_logs (subject_user_id);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_user_id_index ON audit_logs (actor_user_id);
+
+CREATE OR REPLACE FUNCTION prevent_audit_logs_modification_task()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS prevent_audit_logs_modification ON audit_logs;
+
+CREATE TRIGGER prevent_audit_logs_modification
+BEFORE UPDATE OR DELETE
+ON
+   audit_logs
+FOR EACH ROW
+EXECUTE PROCEDURE prevent_audit_logs_modification_task();
+
+DROP TRIGGER IF EXISTS prevent_audit_logs_truncate ON audit_logs;
+
+CREATE TRIGGER prevent_audit_logs_truncate
+BEFORE TRUNCATE
+ON
+   audit_logs
+FOR EACH STATEMENT
+EXECUTE PROCEDURE prevent_audit_logs_modification_task();
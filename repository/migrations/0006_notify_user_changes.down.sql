@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS notify_user_changes ON users;
+
+DROP FUNCTION IF EXISTS notify_user_changes_task();
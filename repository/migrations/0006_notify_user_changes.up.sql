@@ -0,0 +1,17 @@
+-- Tells the other app instances which user changed, so they drop it from their user cache.
+CREATE OR REPLACE FUNCTION notify_user_changes_task()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('user_changes', OLD.id::text);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS notify_user_changes ON users;
+
+CREATE TRIGGER notify_user_changes
+AFTER UPDATE OR DELETE
+ON
+   users
+FOR EACH ROW
+EXECUTE PROCEDURE notify_user_changes_task();
@@ -0,0 +1,4 @@
+-- The optional email, a second login identifier once it is verified.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email             VARCHAR(255) NULL UNIQUE,
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP    NULL;
@@ -0,0 +1,6 @@
+-- The existing users are active.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status            VARCHAR(20)  NOT NULL DEFAULT 'active'
+        CHECK (status IN ('pending', 'active', 'suspended', 'locked', 'pending_deletion', 'deleted')),
+    ADD COLUMN IF NOT EXISTS status_reason     VARCHAR(255) NULL,
+    ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP    NULL;
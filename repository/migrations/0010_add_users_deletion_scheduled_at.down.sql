@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_pending_deletion_index;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deletion_scheduled_at;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS users_pending_deletion_index ON users (deletion_scheduled_at) WHERE status = 'pending_deletion';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS data_exports_user_id_pending_unique_index;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_status_check,
+    ADD CONSTRAINT users_status_check
+        CHECK (status IN ('pending', 'active', 'suspended', 'locked', 'pending_deletion', 'deleted'));
@@ -0,0 +1,5 @@
+-- No account was ever moved to pending, the status is no longer allowed.
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_status_check,
+    ADD CONSTRAINT users_status_check
+        CHECK (status IN ('active', 'suspended', 'locked', 'pending_deletion', 'deleted'));
@@ -0,0 +1 @@
+DROP TABLE audit_log_personal_data;
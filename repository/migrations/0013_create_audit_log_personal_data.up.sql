@@ -0,0 +1,8 @@
+-- The personal data of the audit log entries, kept outside the hash chain so anonymizing a user can delete it.
+-- changes holds the before/after values of the fields audit_logs.changes only names.
+CREATE TABLE IF NOT EXISTS audit_log_personal_data
+(
+    audit_log_id BIGINT      PRIMARY KEY REFERENCES audit_logs (id),
+    changes      JSON        NOT NULL,
+    ip_address   VARCHAR(45) NOT NULL
+);
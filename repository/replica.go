@@ -0,0 +1,105 @@
+// This file contains the routing of reads to the read replicas.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+type primaryPinKey struct{}
+
+// primaryPin is shared by every repository call made with the context of one request.
+type primaryPin struct {
+	isPinned atomic.Bool
+}
+
+// WithReadYourWrites returns a context whose reads go to the primary once something was written with it,
+// so a request reads back its own writes while the replicas are still catching up.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryPinKey{}, &primaryPin{})
+}
+
+// PinToPrimary sends the later reads made with ctx to the primary, every write calls it.
+// It does nothing for a context without WithReadYourWrites.
+func PinToPrimary(ctx context.Context) {
+
+	if pin, ok := ctx.Value(primaryPinKey{}).(*primaryPin); ok {
+		pin.isPinned.Store(true)
+	}
+}
+
+// WithPrimary returns a context whose reads go to the primary, the later reads made with ctx itself are not pinned.
+func WithPrimary(ctx context.Context) context.Context {
+
+	pin := &primaryPin{}
+	pin.isPinned.Store(true)
+
+	return context.WithValue(ctx, primaryPinKey{}, pin)
+}
+
+func IsPinnedToPrimary(ctx context.Context) bool {
+
+	pin, ok := ctx.Value(primaryPinKey{}).(*primaryPin)
+
+	return ok && pin.isPinned.Load()
+}
+
+// replicaSet spreads the reads over the replicas round-robin.
+type replicaSet struct {
+	dbs        []*sql.DB
+	statements []*statementCache
+	next       atomic.Uint64
+}
+
+func newReplicaSet(dbs []*sql.DB) *replicaSet {
+
+	replicas := &replicaSet{
+		dbs: dbs,
+	}
+
+	for _, db := range dbs {
+		replicas.statements = append(replicas.statements, newStatementCache(db))
+	}
+
+	return replicas
+}
+
+func (s *replicaSet) nextStatements() *statementCache {
+	return s.statements[(s.next.Add(1)-1)%uint64(len(s.statements))]
+}
+
+func (s *replicaSet) close() error {
+
+	var firstErr error
+
+	for _, statements := range s.statements {
+
+		err := statements.close()
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// prepareRead returns the statement of a read a replica may serve. The primary serves it in a transaction,
+// when there are no replicas and once the request wrote something.
+func (r Repository) prepareRead(ctx context.Context, query string) (*sql.Stmt, error) {
+
+	if r.tx != nil || len(r.replicas.statements) == 0 || IsPinnedToPrimary(ctx) {
+		return r.prepare(ctx, query)
+	}
+
+	return r.replicas.nextStatements().prepare(ctx, query)
+}
+
+// prepareWrite returns the statement of a write, later reads of the request go to the primary.
+func (r Repository) prepareWrite(ctx context.Context, query string) (*sql.Stmt, error) {
+
+	PinToPrimary(ctx)
+
+	return r.prepare(ctx, query)
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestMarkerDatabase opens a SQLite file that answers markerQuery with name, so a test can tell which database served a read.
+func openTestMarkerDatabase(t *testing.T, name string) *sql.DB {
+
+	db, err := OpenSqliteDatabase(context.Background(), OpenSqliteDatabaseOptions{
+		Path:         filepath.Join(t.TempDir(), name+".db"),
+		BusyTimeout:  5 * time.Second,
+		MaxOpenConns: 2,
+	})
+
+	if err != nil {
+		t.Fatalf("OpenSqliteDatabase() error = %v", err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE marker (name TEXT); INSERT INTO marker (name) VALUES (?);`, name)
+
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+const markerQuery = `SELECT name FROM marker;`
+
+func TestRepository_prepareRead(t *testing.T) {
+	tests := []struct {
+		name         string
+		replicaNames []string
+		isTracked    bool
+		isWritten    bool
+		isInTx       bool
+		want         []string
+	}{
+		{
+			name:         "When there are no replicas, it will read from the primary",
+			replicaNames: nil,
+			isTracked:    true,
+			want:         []string{"primary", "primary"},
+		},
+		{
+			name:         "When there are replicas, it will spread the reads over them",
+			replicaNames: []string{"replica1", "replica2"},
+			isTracked:    true,
+			want:         []string{"replica1", "replica2", "replica1"},
+		},
+		{
+			name:         "When the request wrote something, it will read its own writes from the primary",
+			replicaNames: []string{"replica1"},
+			isTracked:    true,
+			isWritten:    true,
+			want:         []string{"primary", "primary"},
+		},
+		{
+			name:         "When the context does not track the writes, it will keep reading from the replicas",
+			replicaNames: []string{"replica1"},
+			isTracked:    false,
+			isWritten:    true,
+			want:         []string{"replica1", "replica1"},
+		},
+		{
+			name:         "When in a transaction, it will read from the primary",
+			replicaNames: []string{"replica1"},
+			isTracked:    true,
+			isInTx:       true,
+			want:         []string{"primary", "primary"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replicas []*sql.DB
+			for _, replicaName := range tt.replicaNames {
+				replicas = append(replicas, openTestMarkerDatabase(t, replicaName))
+			}
+			r := NewRepository(NewRepositoryOptions{
+				Db:       openTestMarkerDatabase(t, "primary"),
+				Replicas: replicas,
+			})
+			defer r.Close()
+			ctx := context.Background()
+			if tt.isTracked {
+				ctx = WithReadYourWrites(ctx)
+			}
+			if tt.isWritten {
+				if _, err := r.prepareWrite(ctx, markerQuery); err != nil {
+					t.Fatalf("prepareWrite() error = %v", err)
+				}
+			}
+			var got []string
+			read := func(r Repository) error {
+				for range tt.want {
+					statement, err := r.prepareRead(ctx, markerQuery)
+					if err != nil {
+						return err
+					}
+					var name string
+					if err = statement.QueryRowContext(ctx).Scan(&name); err != nil {
+						return err
+					}
+					got = append(got, name)
+				}
+				return nil
+			}
+			var err error
+			if tt.isInTx {
+				err = r.withinTransaction(ctx, TransactionOptions{ReadOnly: true}, read)
+			} else {
+				err = read(*r)
+			}
+			if err != nil {
+				t.Fatalf("prepareRead() error = %v", err)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("prepareRead() read %d from %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package repository
+
+type Repositories struct {
+	User       UserRepositoryInterface
+	LoginEvent LoginEventRepositoryInterface
+	AuditLog   AuditLogRepositoryInterface
+	DataExport DataExportRepositoryInterface
+	Database   DatabaseRepositoryInterface
+	// UserCache is nil when the users are not cached.
+	UserCache UserCacheInterface
+}
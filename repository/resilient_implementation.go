@@ -0,0 +1,276 @@
+// This file contains the repository that retries transient database errors and fails fast while the database is unreachable.
+package repository
+
+import (
+	"context"
+	"github.com/SawitProRecruitment/UserService/logging"
+)
+
+// ResilientRepository wraps the repositories of a driver. Every call goes through the circuit breaker and
+// is retried on transient errors, the calls made in a WithinTransaction callback are retried together with it.
+type ResilientRepository struct {
+	repositories   Repositories
+	retryPolicy    RetryPolicy
+	circuitBreaker *CircuitBreaker
+}
+
+type NewResilientRepositoryOptions struct {
+	Repositories   Repositories
+	RetryPolicy    RetryPolicy
+	CircuitBreaker *CircuitBreaker
+}
+
+func (r ResilientRepository) GetById(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
+	return callResilient(ctx, r, false, func() (*GetUserByIdOutput, error) {
+		return r.repositories.User.GetById(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetByPhoneNumberIncludePassword(ctx context.Context, input GetUserByPhoneNumberInput) (*GetUserByPhoneNumberOutput, error) {
+	return callResilient(ctx, r, false, func() (*GetUserByPhoneNumberOutput, error) {
+		return r.repositories.User.GetByPhoneNumberIncludePassword(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetByEmailIncludePassword(ctx context.Context, input GetUserByEmailInput) (*GetUserByEmailOutput, error) {
+	return callResilient(ctx, r, false, func() (*GetUserByEmailOutput, error) {
+		return r.repositories.User.GetByEmailIncludePassword(ctx, input)
+	})
+}
+
+func (r ResilientRepository) Update(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateUserOutput, error) {
+		return r.repositories.User.Update(ctx, input)
+	})
+}
+
+func (r ResilientRepository) IncrementLoginSuccessCount(ctx context.Context, input IncrementLoginSuccessCountInput) (*UpdateUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateUserOutput, error) {
+		return r.repositories.User.IncrementLoginSuccessCount(ctx, input)
+	})
+}
+
+func (r ResilientRepository) UpdateEmail(ctx context.Context, input UpdateUserEmailInput) (*UpdateUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateUserOutput, error) {
+		return r.repositories.User.UpdateEmail(ctx, input)
+	})
+}
+
+func (r ResilientRepository) UpdateRole(ctx context.Context, input UpdateUserRoleInput) (*UpdateUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateUserOutput, error) {
+		return r.repositories.User.UpdateRole(ctx, input)
+	})
+}
+
+func (r ResilientRepository) UpdateStatus(ctx context.Context, input UpdateUserStatusInput) (*UpdateUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateUserOutput, error) {
+		return r.repositories.User.UpdateStatus(ctx, input)
+	})
+}
+
+func (r ResilientRepository) ScheduleDeletion(ctx context.Context, input ScheduleUserDeletionInput) (*UpdateUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateUserOutput, error) {
+		return r.repositories.User.ScheduleDeletion(ctx, input)
+	})
+}
+
+func (r ResilientRepository) CancelDeletion(ctx context.Context, input CancelUserDeletionInput) (*UpdateUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateUserOutput, error) {
+		return r.repositories.User.CancelDeletion(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetUsersDueForDeletion(ctx context.Context, input GetUsersDueForDeletionInput) (*GetUsersDueForDeletionOutput, error) {
+	return callResilient(ctx, r, false, func() (*GetUsersDueForDeletionOutput, error) {
+		return r.repositories.User.GetUsersDueForDeletion(ctx, input)
+	})
+}
+
+func (r ResilientRepository) AnonymizeUser(ctx context.Context, input AnonymizeUserInput) (*AnonymizeUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*AnonymizeUserOutput, error) {
+		return r.repositories.User.AnonymizeUser(ctx, input)
+	})
+}
+
+func (r ResilientRepository) Insert(ctx context.Context, input InsertUserInput) (*InsertUserOutput, error) {
+	return callResilient(ctx, r, true, func() (*InsertUserOutput, error) {
+		return r.repositories.User.Insert(ctx, input)
+	})
+}
+
+func (r ResilientRepository) InsertEmailVerification(ctx context.Context, input InsertEmailVerificationInput) (*InsertEmailVerificationOutput, error) {
+	return callResilient(ctx, r, true, func() (*InsertEmailVerificationOutput, error) {
+		return r.repositories.User.InsertEmailVerification(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetEmailVerificationByTokenHash(ctx context.Context, input GetEmailVerificationByTokenHashInput) (*GetEmailVerificationOutput, error) {
+	return callResilient(ctx, r, false, func() (*GetEmailVerificationOutput, error) {
+		return r.repositories.User.GetEmailVerificationByTokenHash(ctx, input)
+	})
+}
+
+func (r ResilientRepository) VerifyEmail(ctx context.Context, input VerifyEmailInput) (*VerifyEmailOutput, error) {
+	return callResilient(ctx, r, true, func() (*VerifyEmailOutput, error) {
+		return r.repositories.User.VerifyEmail(ctx, input)
+	})
+}
+
+// WithinTransaction runs the whole transaction again when it fails with an error that rolled it back,
+// e.g. a serialization failure, so fn must be safe to call more than once.
+func (r ResilientRepository) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+	return r.call(ctx, true, func() error {
+		return r.repositories.User.WithinTransaction(ctx, opts, fn)
+	})
+}
+
+func (r ResilientRepository) InsertLoginEvent(ctx context.Context, input InsertLoginEventInput) (*InsertLoginEventOutput, error) {
+	return callResilient(ctx, r, true, func() (*InsertLoginEventOutput, error) {
+		return r.repositories.LoginEvent.InsertLoginEvent(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetLoginEventsByUserId(ctx context.Context, input GetLoginEventsByUserIdInput) (*GetLoginEventsByUserIdOutput, error) {
+	return callResilient(ctx, r, false, func() (*GetLoginEventsByUserIdOutput, error) {
+		return r.repositories.LoginEvent.GetLoginEventsByUserId(ctx, input)
+	})
+}
+
+func (r ResilientRepository) AppendAuditLog(ctx context.Context, input AppendAuditLogInput) (*AppendAuditLogOutput, error) {
+	return callResilient(ctx, r, true, func() (*AppendAuditLogOutput, error) {
+		return r.repositories.AuditLog.AppendAuditLog(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetAuditLogs(ctx context.Context, input GetAuditLogsInput) (*GetAuditLogsOutput, error) {
+	return callResilient(ctx, r, false, func() (*GetAuditLogsOutput, error) {
+		return r.repositories.AuditLog.GetAuditLogs(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetAuditLogsAfterId(ctx context.Context, input GetAuditLogsAfterIdInput) ([]AuditLogOutput, error) {
+	return callResilient(ctx, r, false, func() ([]AuditLogOutput, error) {
+		return r.repositories.AuditLog.GetAuditLogsAfterId(ctx, input)
+	})
+}
+
+func (r ResilientRepository) InsertDataExport(ctx context.Context, input InsertDataExportInput) (*InsertDataExportOutput, error) {
+	return callResilient(ctx, r, true, func() (*InsertDataExportOutput, error) {
+		return r.repositories.DataExport.InsertDataExport(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetDataExportById(ctx context.Context, input GetDataExportByIdInput) (*DataExportOutput, error) {
+	return callResilient(ctx, r, false, func() (*DataExportOutput, error) {
+		return r.repositories.DataExport.GetDataExportById(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetDataExportByTokenHash(ctx context.Context, input GetDataExportByTokenHashInput) (*DataExportOutput, error) {
+	return callResilient(ctx, r, false, func() (*DataExportOutput, error) {
+		return r.repositories.DataExport.GetDataExportByTokenHash(ctx, input)
+	})
+}
+
+func (r ResilientRepository) UpdateDataExportStatus(ctx context.Context, input UpdateDataExportStatusInput) (*UpdateDataExportOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateDataExportOutput, error) {
+		return r.repositories.DataExport.UpdateDataExportStatus(ctx, input)
+	})
+}
+
+func (r ResilientRepository) GetExpiredDataExports(ctx context.Context, input GetExpiredDataExportsInput) (*GetDataExportsOutput, error) {
+	return callResilient(ctx, r, false, func() (*GetDataExportsOutput, error) {
+		return r.repositories.DataExport.GetExpiredDataExports(ctx, input)
+	})
+}
+
+func (r ResilientRepository) ClearDataExportFile(ctx context.Context, input ClearDataExportFileInput) (*UpdateDataExportOutput, error) {
+	return callResilient(ctx, r, true, func() (*UpdateDataExportOutput, error) {
+		return r.repositories.DataExport.ClearDataExportFile(ctx, input)
+	})
+}
+
+func (r ResilientRepository) FailStaleDataExports(ctx context.Context, input FailStaleDataExportsInput) (*FailStaleDataExportsOutput, error) {
+	return callResilient(ctx, r, true, func() (*FailStaleDataExportsOutput, error) {
+		return r.repositories.DataExport.FailStaleDataExports(ctx, input)
+	})
+}
+
+// GetPoolStats adds the state of the circuit breaker to the statistics of the wrapped pool.
+func (r ResilientRepository) GetPoolStats() PoolStatsOutput {
+
+	output := r.repositories.Database.GetPoolStats()
+	output.CircuitBreakerState = r.circuitBreaker.State()
+
+	return output
+}
+
+// Ping goes through the circuit breaker without retries, a health check wants the answer of this moment.
+// While the breaker is open it fails without reaching the database.
+func (r ResilientRepository) Ping(ctx context.Context) error {
+
+	isProbe, err := r.circuitBreaker.allow()
+
+	if err != nil {
+		return err
+	}
+
+	err = r.repositories.Database.Ping(ctx)
+
+	r.circuitBreaker.record(isProbe, err)
+
+	return err
+}
+
+func (r ResilientRepository) GetSchemaVersion(ctx context.Context) (int64, error) {
+	return callResilient(ctx, r, false, func() (int64, error) {
+		return r.repositories.Database.GetSchemaVersion(ctx)
+	})
+}
+
+// call runs fn through the circuit breaker and retries it, an open breaker ends the retries. The error is wrapped
+// with the stack of the service call it failed, which is logged when the request fails with it.
+func (r ResilientRepository) call(ctx context.Context, isWrite bool, fn func() error) error {
+
+	err := retry(ctx, r.retryPolicy, isWrite, func() error {
+
+		isProbe, err := r.circuitBreaker.allow()
+
+		if err != nil {
+			return err
+		}
+
+		err = fn()
+
+		r.circuitBreaker.record(isProbe, err)
+
+		return err
+	})
+
+	return logging.WithStack(err)
+}
+
+func callResilient[T any](ctx context.Context, r ResilientRepository, isWrite bool, fn func() (T, error)) (T, error) {
+
+	var output T
+
+	err := r.call(ctx, isWrite, func() error {
+
+		var err error
+
+		output, err = fn()
+
+		return err
+	})
+
+	return output, err
+}
+
+func NewResilientRepository(opts NewResilientRepositoryOptions) *ResilientRepository {
+
+	return &ResilientRepository{
+		repositories:   opts.Repositories,
+		retryPolicy:    opts.RetryPolicy,
+		circuitBreaker: opts.CircuitBreaker,
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestResilientRepository(userRepository UserRepositoryInterface) *ResilientRepository {
+
+	return NewResilientRepository(NewResilientRepositoryOptions{
+		Repositories: Repositories{User: userRepository},
+		RetryPolicy: RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		},
+		CircuitBreaker: NewCircuitBreaker(NewCircuitBreakerOptions{
+			FailureThreshold: 3,
+			OpenDuration:     time.Minute,
+		}),
+	})
+}
+
+func TestResilientRepository_GetById(t *testing.T) {
+	tests := []struct {
+		name            string
+		mock            func(userRepository *MockUserRepositoryInterface)
+		want            *GetUserByIdOutput
+		wantUnavailable bool
+	}{
+		{
+			name: "When the first read hits a connection reset, it will return the user of the retry",
+			mock: func(userRepository *MockUserRepositoryInterface) {
+				gomock.InOrder(
+					userRepository.EXPECT().GetById(gomock.Any(), GetUserByIdInput{Id: 1}).Return(nil, syscall.ECONNRESET),
+					userRepository.EXPECT().GetById(gomock.Any(), GetUserByIdInput{Id: 1}).Return(&GetUserByIdOutput{Id: 1}, nil),
+				)
+			},
+			want:            &GetUserByIdOutput{Id: 1},
+			wantUnavailable: false,
+		},
+		{
+			name: "When the database stays unreachable, it will open the circuit breaker and fail fast",
+			mock: func(userRepository *MockUserRepositoryInterface) {
+				userRepository.EXPECT().GetById(gomock.Any(), GetUserByIdInput{Id: 1}).Return(nil, syscall.ECONNREFUSED).Times(3)
+			},
+			want:            nil,
+			wantUnavailable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			userRepository := NewMockUserRepositoryInterface(mockCtrl)
+			tt.mock(userRepository)
+			r := newTestResilientRepository(userRepository)
+			got, err := r.GetById(context.Background(), GetUserByIdInput{Id: 1})
+			if tt.wantUnavailable == false && err != nil {
+				t.Fatalf("GetById() error = %v", err)
+			}
+			if tt.wantUnavailable {
+				// The attempts of the first call open the breaker, the second call does not reach the database.
+				got, err = r.GetById(context.Background(), GetUserByIdInput{Id: 1})
+				var unavailableErr *DatabaseUnavailableError
+				if errors.As(err, &unavailableErr) == false {
+					t.Fatalf("GetById() error = %v, want DatabaseUnavailableError", err)
+				}
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && got.Id != tt.want.Id) {
+				t.Errorf("GetById() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResilientRepository_ErrorStack(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	unexpectedErr := errors.New("unexpected error")
+
+	userRepository := NewMockUserRepositoryInterface(mockCtrl)
+	userRepository.EXPECT().GetById(gomock.Any(), GetUserByIdInput{Id: 1}).Return(nil, unexpectedErr)
+
+	_, err := newTestResilientRepository(userRepository).GetById(context.Background(), GetUserByIdInput{Id: 1})
+
+	if errors.Is(err, unexpectedErr) == false {
+		t.Fatalf("GetById() error = %v, want %v", err, unexpectedErr)
+	}
+
+	stack, _ := logging.ErrorStack(err).Value.Any().([]string)
+
+	if strings.Contains(strings.Join(stack, "\n"), "TestResilientRepository_ErrorStack") == false {
+		t.Errorf("GetById() error stack = %v, want it to reach the caller", stack)
+	}
+}
+
+func TestResilientRepository_WithinTransaction(t *testing.T) {
+	tests := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "When the transaction fails with a serialization failure, it will run it again",
+			errs:         []error{&pq.Error{Code: "40001"}, nil},
+			wantAttempts: 2,
+			wantErr:      false,
+		},
+		{
+			name:         "When the connection is lost during the transaction, it will not run it again because it may have committed",
+			errs:         []error{syscall.ECONNRESET},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			userRepository := NewMockUserRepositoryInterface(mockCtrl)
+			attempts := 0
+			userRepository.EXPECT().WithinTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+					attempts++
+					return tt.errs[attempts-1]
+				}).Times(tt.wantAttempts)
+			r := newTestResilientRepository(userRepository)
+			err := r.WithinTransaction(context.Background(), TransactionOptions{}, func(txRepository TransactionRepositoryInterface) error {
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WithinTransaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
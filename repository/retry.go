@@ -0,0 +1,129 @@
+// This file contains the classification of transient database errors and the retries of the calls that failed with one.
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// The Postgres error codes of failures that go away when the call is made again.
+const (
+	postgresSerializationFailureCode = "40001"
+	postgresDeadlockDetectedCode     = "40P01"
+	postgresAdminShutdownCode        = "57P01"
+	postgresCrashShutdownCode        = "57P02"
+	postgresCannotConnectNowCode     = "57P03"
+	// postgresConnectionExceptionClass is the class of e.g. 08006 connection_failure.
+	postgresConnectionExceptionClass = "08"
+	postgresConnectionFailureCode    = "08001"
+	postgresConnectionRejectedCode   = "08004"
+)
+
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, 1 disables the retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// IsRetryableError reports whether err is transient: a serialization failure, a deadlock, a busy SQLite database
+// or the database being unreachable, e.g. a connection reset or an admin shutdown during a failover.
+func IsRetryableError(err error) bool {
+	return isDatabaseUnreachableError(err) || isRolledBackError(err)
+}
+
+// isDatabaseUnreachableError reports whether err means the database cannot be reached, the errors the circuit breaker counts.
+func isDatabaseUnreachableError(err error) bool {
+
+	if err == nil {
+		return false
+	}
+
+	var pqErr *pq.Error
+
+	if errors.As(err, &pqErr) {
+
+		switch pqErr.Code {
+		case postgresAdminShutdownCode, postgresCrashShutdownCode, postgresCannotConnectNowCode:
+			return true
+		}
+
+		return pqErr.Code.Class() == postgresConnectionExceptionClass
+	}
+
+	var netErr net.Error
+
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.As(err, &netErr)
+}
+
+// isRolledBackError reports whether err is transient and guarantees that the failed call changed nothing,
+// so even a write can be made again.
+func isRolledBackError(err error) bool {
+
+	var pqErr *pq.Error
+
+	if errors.As(err, &pqErr) {
+
+		switch pqErr.Code {
+		case postgresSerializationFailureCode, postgresDeadlockDetectedCode, postgresCannotConnectNowCode,
+			postgresConnectionFailureCode, postgresConnectionRejectedCode:
+			return true
+		}
+
+		return false
+	}
+
+	var sqliteErr sqlite3.Error
+
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+
+	// database/sql only returns ErrBadConn when the driver did not send the query, the connection was never established when it was refused.
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// retry calls fn until it succeeds, fails with an error that is not worth retrying or the attempts are used up.
+// A write is only retried when the failed attempt surely changed nothing, a connection lost halfway through
+// a write may have applied it. Between attempts it waits a random time up to an exponentially growing backoff.
+func retry(ctx context.Context, policy RetryPolicy, isWrite bool, fn func() error) error {
+
+	backoff := policy.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+
+		err := fn()
+
+		if err == nil || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		if isRolledBackError(err) == false && (isWrite || isDatabaseUnreachableError(err) == false) {
+			return err
+		}
+
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoff) + 1)))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
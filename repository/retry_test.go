@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pq.Error{Code: "40P01"}, want: true},
+		{name: "admin shutdown", err: &pq.Error{Code: "57P01"}, want: true},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, want: true},
+		{name: "connection reset", err: fmt.Errorf("read tcp: %w", syscall.ECONNRESET), want: true},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: true},
+		{name: "busy SQLite database", err: sqlite3.Error{Code: sqlite3.ErrBusy}, want: true},
+		{name: "unique violation", err: &pq.Error{Code: "23505"}, want: false},
+		{name: "unique violation translated", err: &UniqueViolationError{Field: UniqueFieldEmail}, want: false},
+		{name: "cancelled request", err: context.Canceled, want: false},
+		{name: "no error", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_retry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+	tests := []struct {
+		name         string
+		isWrite      bool
+		errs         []error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "When a read fails with a connection reset, it will retry it until it succeeds",
+			isWrite:      false,
+			errs:         []error{syscall.ECONNRESET, syscall.ECONNRESET, nil},
+			wantAttempts: 3,
+			wantErr:      false,
+		},
+		{
+			name:         "When a write fails with a connection reset, it will not retry it because it may have been applied",
+			isWrite:      true,
+			errs:         []error{syscall.ECONNRESET, nil},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "When a write fails with a serialization failure, it will retry it",
+			isWrite:      true,
+			errs:         []error{&pq.Error{Code: "40001"}, nil},
+			wantAttempts: 2,
+			wantErr:      false,
+		},
+		{
+			name:         "When the attempts are used up, it will return the last error",
+			isWrite:      false,
+			errs:         []error{io.EOF, io.EOF, io.EOF, nil},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "When the error is not transient, it will return it right away",
+			isWrite:      false,
+			errs:         []error{errors.New("syntax error"), nil},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := retry(context.Background(), policy, tt.isWrite, func() error {
+				attempts++
+				return tt.errs[attempts-1]
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("retry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("retry() attempts = %v, want %v", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
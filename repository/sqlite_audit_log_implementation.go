@@ -0,0 +1,174 @@
+// This file contains the SQLite repository implementation of the audit log.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AppendAuditLog needs no advisory lock, the transaction starts with BEGIN IMMEDIATE and so holds the write lock
+// from reading the latest hash until the new entry is committed.
+func (r SqliteRepository) AppendAuditLog(ctx context.Context, input AppendAuditLogInput) (*AppendAuditLogOutput, error) {
+
+	// Hash what will be read back later, like Repository.AppendAuditLog.
+	input.CreatedAt = input.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	var output *AppendAuditLogOutput
+
+	err := r.withinTransaction(ctx, func(txRepository SqliteRepository) error {
+
+		previousHash := GenesisAuditLogHash
+
+		err := txRepository.tx.QueryRowContext(ctx, `SELECT hash FROM audit_logs ORDER BY id DESC LIMIT 1;`).Scan(&previousHash)
+
+		if err != nil && errors.Is(err, sql.ErrNoRows) == false {
+			return err
+		}
+
+		hash := ComputeAuditLogHash(previousHash, input)
+
+		query := `INSERT INTO audit_logs (actor_user_id, subject_user_id, action, changes, request_id, ip_address, previous_hash, hash, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;`
+
+		var lastInsertId int64
+
+		err = txRepository.tx.QueryRowContext(ctx, query, optionalIdToNullInt64(input.ActorUserId), optionalIdToNullInt64(input.SubjectUserId),
+			input.Action, input.Changes, input.RequestId, input.IpAddress, previousHash, hash, input.CreatedAt).Scan(&lastInsertId)
+
+		if err != nil {
+			return err
+		}
+
+		if input.PersonalChanges != "" {
+
+			_, err = txRepository.tx.ExecContext(ctx, `INSERT INTO audit_log_personal_data (audit_log_id, changes, ip_address) VALUES (?, ?, ?);`,
+				lastInsertId, input.PersonalChanges, input.PersonalIpAddress)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		output = &AppendAuditLogOutput{
+			Id:   lastInsertId,
+			Hash: hash,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// sqliteAuditLogColumns selects what auditLogColumns selects, without the Postgres cast.
+const sqliteAuditLogColumns = `id, actor_user_id, subject_user_id, action, audit_logs.changes, request_id, audit_logs.ip_address,
+	COALESCE(audit_log_personal_data.changes, ''), COALESCE(audit_log_personal_data.ip_address, ''), previous_hash, hash, created_at`
+
+func (r SqliteRepository) GetAuditLogs(ctx context.Context, input GetAuditLogsInput) (*GetAuditLogsOutput, error) {
+
+	var conditions []string
+	var args []interface{}
+
+	if input.ActorUserId != nil {
+		args = append(args, *input.ActorUserId)
+		conditions = append(conditions, "actor_user_id = ?")
+	}
+
+	if input.SubjectUserId != nil {
+		args = append(args, *input.SubjectUserId)
+		conditions = append(conditions, "subject_user_id = ?")
+	}
+
+	if input.Action != "" {
+		args = append(args, input.Action)
+		conditions = append(conditions, "action = ?")
+	}
+
+	where := ""
+
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	output := &GetAuditLogsOutput{
+		AuditLogs: []AuditLogOutput{},
+	}
+
+	err := r.executor().QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM audit_logs %s;`, where), args...).Scan(&output.Total)
+
+	if err != nil {
+		return nil, err
+	}
+
+	args = append(args, input.Limit, input.Offset)
+
+	query := fmt.Sprintf(`SELECT %s
+		FROM audit_logs %s %s ORDER BY id DESC LIMIT ? OFFSET ?;`, sqliteAuditLogColumns, auditLogPersonalDataJoin, where)
+
+	auditLogs, err := r.queryAuditLogs(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output.AuditLogs = auditLogs
+
+	return output, nil
+}
+
+func (r SqliteRepository) GetAuditLogsAfterId(ctx context.Context, input GetAuditLogsAfterIdInput) ([]AuditLogOutput, error) {
+
+	query := fmt.Sprintf(`SELECT %s
+		FROM audit_logs %s WHERE id > ? ORDER BY id ASC LIMIT ?;`, sqliteAuditLogColumns, auditLogPersonalDataJoin)
+
+	return r.queryAuditLogs(ctx, query, input.AfterId, input.Limit)
+}
+
+func (r SqliteRepository) queryAuditLogs(ctx context.Context, query string, args ...interface{}) ([]AuditLogOutput, error) {
+
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	auditLogs := []AuditLogOutput{}
+
+	for rows.Next() {
+
+		auditLog := AuditLogOutput{}
+
+		var actorUserId sql.NullInt64
+		var subjectUserId sql.NullInt64
+
+		err = rows.Scan(&auditLog.Id, &actorUserId, &subjectUserId, &auditLog.Action, &auditLog.Changes, &auditLog.RequestId,
+			&auditLog.IpAddress, &auditLog.PersonalChanges, &auditLog.PersonalIpAddress, &auditLog.PreviousHash, &auditLog.Hash, &auditLog.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		auditLog.ActorUserId = nullInt64ToPointer(actorUserId)
+		auditLog.SubjectUserId = nullInt64ToPointer(subjectUserId)
+
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return auditLogs, nil
+}
@@ -0,0 +1,158 @@
+// This file contains the SQLite repository implementation of the personal data exports.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+func (r SqliteRepository) InsertDataExport(ctx context.Context, input InsertDataExportInput) (*InsertDataExportOutput, error) {
+
+	var lastInsertId int64
+
+	query := `INSERT INTO data_exports (user_id, is_encrypted, download_token_hash, expired_at) VALUES (?, ?, ?, ?) RETURNING id;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryStatement.QueryRowContext(ctx, input.UserId, input.IsEncrypted, input.DownloadTokenHash, input.ExpiredAt).Scan(&lastInsertId)
+
+	if err != nil {
+		return nil, translateSqliteUniqueViolation(err)
+	}
+
+	output := &InsertDataExportOutput{
+		Id: lastInsertId,
+	}
+
+	return output, nil
+}
+
+// GetDataExportById only returns the export when it belongs to the given user.
+func (r SqliteRepository) GetDataExportById(ctx context.Context, input GetDataExportByIdInput) (*DataExportOutput, error) {
+
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = ? AND user_id = ?;`
+
+	return r.queryDataExport(ctx, query, input.Id, input.UserId)
+}
+
+func (r SqliteRepository) GetDataExportByTokenHash(ctx context.Context, input GetDataExportByTokenHashInput) (*DataExportOutput, error) {
+
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE download_token_hash = ?;`
+
+	return r.queryDataExport(ctx, query, input.DownloadTokenHash)
+}
+
+func (r SqliteRepository) UpdateDataExportStatus(ctx context.Context, input UpdateDataExportStatusInput) (*UpdateDataExportOutput, error) {
+
+	query := `UPDATE data_exports SET status = ?, file_name = ?, failure_reason = ?,
+		completed_at = CASE WHEN ? THEN ` + sqliteNow + ` ELSE completed_at END
+		WHERE id = ? AND status = ? AND julianday(expired_at) > julianday('now');`
+
+	updateOutput, err := r.execUpdate(ctx, query, input.Status, stringToNullString(input.FileName),
+		stringToNullString(input.FailureReason), input.Status == "completed", input.Id, input.FromStatus)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateDataExportOutput{
+		IsSuccessUpdate: updateOutput.IsSuccessUpdate,
+	}
+
+	return output, nil
+}
+
+// GetExpiredDataExports works like Repository.GetExpiredDataExports. The timestamps are compared as julian days, the
+// expiry is written by the driver with a time zone offset.
+func (r SqliteRepository) GetExpiredDataExports(ctx context.Context, input GetExpiredDataExportsInput) (*GetDataExportsOutput, error) {
+
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE file_name IS NOT NULL
+		AND julianday(expired_at) <= julianday('now') ORDER BY julianday(expired_at) LIMIT ?;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queryStatement.QueryContext(ctx, input.Limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanDataExports(rows)
+}
+
+func (r SqliteRepository) ClearDataExportFile(ctx context.Context, input ClearDataExportFileInput) (*UpdateDataExportOutput, error) {
+
+	query := `UPDATE data_exports SET file_name = NULL WHERE id = ? AND file_name IS NOT NULL;`
+
+	updateOutput, err := r.execUpdate(ctx, query, input.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateDataExportOutput{
+		IsSuccessUpdate: updateOutput.IsSuccessUpdate,
+	}
+
+	return output, nil
+}
+
+func (r SqliteRepository) FailStaleDataExports(ctx context.Context, input FailStaleDataExportsInput) (*FailStaleDataExportsOutput, error) {
+
+	query := `UPDATE data_exports SET status = 'failed', failure_reason = ?
+		WHERE status IN ('pending', 'processing') AND julianday(created_at) < julianday(?);`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.FailureReason, input.CreatedBefore.UTC())
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &FailStaleDataExportsOutput{
+		FailedCount: rowsAffected,
+	}
+
+	return output, nil
+}
+
+func (r SqliteRepository) queryDataExport(ctx context.Context, query string, args ...interface{}) (*DataExportOutput, error) {
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := scanDataExport(queryStatement.QueryRowContext(ctx, args...).Scan)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return result, nil
+}
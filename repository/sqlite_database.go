@@ -0,0 +1,76 @@
+// This file contains the setup of the SQLite database.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type OpenSqliteDatabaseOptions struct {
+	Path string
+	// BusyTimeout is how long a write waits for the write lock held by another connection.
+	BusyTimeout  time.Duration
+	MaxOpenConns int
+}
+
+// sqliteNow is the SQLite counterpart of now(), the format every timestamp column of the SQLite migrations uses.
+const sqliteNow = `strftime('%Y-%m-%d %H:%M:%f', 'now')`
+
+// OpenSqliteDatabase opens the database file in WAL mode, so reads do not wait for a write.
+// Transactions start with BEGIN IMMEDIATE: writers queue up for the lock instead of failing halfway with SQLITE_BUSY.
+func OpenSqliteDatabase(ctx context.Context, opts OpenSqliteDatabaseOptions) (*sql.DB, error) {
+
+	params := url.Values{}
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", fmt.Sprint(opts.BusyTimeout.Milliseconds()))
+	params.Set("_foreign_keys", "on")
+	params.Set("_txlock", "immediate")
+	params.Set("_loc", "UTC")
+
+	db := sql.OpenDB(newTracedConnector(dsnConnector{
+		dsn:    "file:" + opts.Path + "?" + params.Encode(),
+		driver: &sqlite3.SQLiteDriver{},
+	}, semconv.DBSystemSqlite))
+
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+
+	err := db.PingContext(ctx)
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// translateSqliteUniqueViolation is the SQLite counterpart of translateUniqueViolation.
+func translateSqliteUniqueViolation(err error) error {
+
+	var sqliteErr sqlite3.Error
+
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+
+		// The message names the columns, e.g. "UNIQUE constraint failed: users.phone_number".
+		if strings.HasSuffix(sqliteErr.Error(), "users.phone_number") {
+			return &UniqueViolationError{Field: UniqueFieldPhoneNumber}
+		}
+
+		if strings.HasSuffix(sqliteErr.Error(), "users.email") {
+			return &UniqueViolationError{Field: UniqueFieldEmail}
+		}
+
+		if strings.HasSuffix(sqliteErr.Error(), "data_exports.user_id") {
+			return &UniqueViolationError{Field: UniqueFieldPendingDataExport}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,404 @@
+// This file contains the SQLite repository implementation, for a single box without a Postgres server.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SqliteRepository behaves like Repository on top of the SQLite migrations: the same unique constraints,
+// auto increment ids and updated_at maintained by a trigger.
+type SqliteRepository struct {
+	Db         *sql.DB
+	statements *statementCache
+	// tx is set on the copy of the repository handed to a WithinTransaction callback.
+	tx *sql.Tx
+}
+
+type NewSqliteRepositoryOptions struct {
+	Db *sql.DB
+}
+
+func NewSqliteRepository(opts NewSqliteRepositoryOptions) *SqliteRepository {
+
+	return &SqliteRepository{
+		Db:         opts.Db,
+		statements: newStatementCache(opts.Db),
+	}
+}
+
+// Close closes the prepared statements of the repository, call it before closing the database.
+func (r SqliteRepository) Close() error {
+	return r.statements.close()
+}
+
+func (r SqliteRepository) GetPoolStats() PoolStatsOutput {
+	return getPoolStats(r.Db)
+}
+
+func (r SqliteRepository) Ping(ctx context.Context) error {
+	return r.Db.PingContext(ctx)
+}
+
+func (r SqliteRepository) GetSchemaVersion(ctx context.Context) (int64, error) {
+	return getSchemaVersion(ctx, r.Db)
+}
+
+func (r SqliteRepository) executor() queryExecutor {
+
+	if r.tx != nil {
+		return r.tx
+	}
+
+	return r.Db
+}
+
+// prepare returns the cached statement of query, bound to the transaction when there is one.
+func (r SqliteRepository) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+
+	statement, err := r.statements.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.tx != nil {
+		return r.tx.StmtContext(ctx, statement), nil
+	}
+
+	return statement, nil
+}
+
+// WithinTransaction works like Repository.WithinTransaction. SQLite transactions are always serializable,
+// the isolation level of opts is ignored.
+func (r SqliteRepository) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+
+	return r.withinTransaction(ctx, func(txRepository SqliteRepository) error {
+		return fn(txRepository)
+	})
+}
+
+func (r SqliteRepository) withinTransaction(ctx context.Context, fn func(txRepository SqliteRepository) error) (err error) {
+
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.Db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+
+		if recovered := recover(); recovered != nil {
+			_ = tx.Rollback()
+			panic(recovered)
+		}
+
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	txRepository := r
+	txRepository.tx = tx
+
+	err = fn(txRepository)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// execUpdate runs an update of a single row and reports whether a row matched.
+func (r SqliteRepository) execUpdate(ctx context.Context, query string, args ...interface{}) (*UpdateUserOutput, error) {
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, args...)
+
+	if err != nil {
+		return nil, translateSqliteUniqueViolation(err)
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateUserOutput{
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+func (r SqliteRepository) GetById(ctx context.Context, input GetUserByIdInput) (*GetUserByIdOutput, error) {
+
+	query := `SELECT id, phone_number, full_name, email, email_verified_at, role, status, status_reason, status_changed_at, deletion_scheduled_at, login_success_count, created_at, updated_at FROM users WHERE id = ?;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := GetUserByIdOutput{}
+
+	var email sql.NullString
+	var emailVerifiedAt sql.NullTime
+	var statusReason sql.NullString
+	var statusChangedAt sql.NullTime
+	var deletionScheduledAt sql.NullTime
+
+	err = queryStatement.QueryRowContext(ctx, input.Id).
+		Scan(&result.Id, &result.PhoneNumber, &result.FullName, &email, &emailVerifiedAt, &result.Role, &result.Status, &statusReason, &statusChangedAt, &deletionScheduledAt, &result.LoginSuccessCount, &result.CreatedAt, &result.UpdatedAt)
+
+	if err != nil {
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	result.Email = email.String
+	result.EmailVerifiedAt = nullTimeToPointer(emailVerifiedAt)
+	result.StatusReason = statusReason.String
+	result.StatusChangedAt = nullTimeToPointer(statusChangedAt)
+	result.DeletionScheduledAt = nullTimeToPointer(deletionScheduledAt)
+
+	return &result, nil
+}
+
+func (r SqliteRepository) GetByPhoneNumberIncludePassword(ctx context.Context, input GetUserByPhoneNumberInput) (*GetUserByPhoneNumberOutput, error) {
+
+	query := `SELECT id, phone_number, full_name, email, email_verified_at, password, role, status, status_reason, status_changed_at, deletion_scheduled_at, login_success_count, created_at, updated_at FROM users WHERE phone_number = ?;`
+
+	return r.queryUserIncludePassword(ctx, query, input.PhoneNumber)
+}
+
+func (r SqliteRepository) GetByEmailIncludePassword(ctx context.Context, input GetUserByEmailInput) (*GetUserByEmailOutput, error) {
+
+	query := `SELECT id, phone_number, full_name, email, email_verified_at, password, role, status, status_reason, status_changed_at, deletion_scheduled_at, login_success_count, created_at, updated_at FROM users WHERE email = ?;`
+
+	user, err := r.queryUserIncludePassword(ctx, query, input.Email)
+
+	if user == nil {
+		return nil, err
+	}
+
+	result := GetUserByEmailOutput(*user)
+
+	return &result, nil
+}
+
+func (r SqliteRepository) queryUserIncludePassword(ctx context.Context, query string, args ...interface{}) (*GetUserByPhoneNumberOutput, error) {
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := GetUserByPhoneNumberOutput{}
+
+	var email sql.NullString
+	var emailVerifiedAt sql.NullTime
+	var statusReason sql.NullString
+	var statusChangedAt sql.NullTime
+	var deletionScheduledAt sql.NullTime
+
+	err = queryStatement.QueryRowContext(ctx, args...).
+		Scan(&result.Id, &result.PhoneNumber, &result.FullName, &email, &emailVerifiedAt, &result.Password, &result.Role, &result.Status, &statusReason, &statusChangedAt, &deletionScheduledAt, &result.LoginSuccessCount, &result.CreatedAt, &result.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	result.Email = email.String
+	result.EmailVerifiedAt = nullTimeToPointer(emailVerifiedAt)
+	result.StatusReason = statusReason.String
+	result.StatusChangedAt = nullTimeToPointer(statusChangedAt)
+	result.DeletionScheduledAt = nullTimeToPointer(deletionScheduledAt)
+
+	return &result, nil
+}
+
+func (r SqliteRepository) Insert(ctx context.Context, input InsertUserInput) (*InsertUserOutput, error) {
+
+	var lastInsertId int64
+
+	query := `INSERT INTO users (phone_number, full_name, email, password) VALUES (?, ?, ?, ?) RETURNING id;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryStatement.QueryRowContext(ctx, input.PhoneNumber, input.FullName, stringToNullString(input.Email), input.Password).Scan(&lastInsertId)
+
+	if err != nil {
+		return nil, translateSqliteUniqueViolation(err)
+	}
+
+	output := &InsertUserOutput{
+		Id: lastInsertId,
+	}
+
+	return output, nil
+}
+
+func (r SqliteRepository) Update(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error) {
+
+	fields, args, err := getUpdatedUserFields(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := []string{}
+
+	for _, field := range fields {
+		assignments = append(assignments, field+" = ?")
+	}
+
+	query := fmt.Sprintf(`UPDATE users SET %s WHERE id = ?;`, strings.Join(assignments, ", "))
+
+	return r.execUpdate(ctx, query, append(args, input.Id)...)
+}
+
+// IncrementLoginSuccessCount increments the counter in the database, so concurrent logins do not lose an increment.
+func (r SqliteRepository) IncrementLoginSuccessCount(ctx context.Context, input IncrementLoginSuccessCountInput) (*UpdateUserOutput, error) {
+	return r.execUpdate(ctx, `UPDATE users SET login_success_count = login_success_count + 1 WHERE id = ? AND status = 'active';`, input.Id)
+}
+
+func (r SqliteRepository) UpdateEmail(ctx context.Context, input UpdateUserEmailInput) (*UpdateUserOutput, error) {
+	return r.execUpdate(ctx, `UPDATE users SET email = ?, email_verified_at = NULL WHERE id = ?;`, input.Email, input.Id)
+}
+
+func (r SqliteRepository) UpdateRole(ctx context.Context, input UpdateUserRoleInput) (*UpdateUserOutput, error) {
+	return r.execUpdate(ctx, `UPDATE users SET role = ? WHERE id = ?;`, input.Role, input.Id)
+}
+
+// UpdateStatus works like Repository.UpdateStatus.
+func (r SqliteRepository) UpdateStatus(ctx context.Context, input UpdateUserStatusInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET status = ?, status_reason = ?, status_changed_at = ` + sqliteNow + `
+		WHERE id = ? AND status = ? AND status <> 'pending_deletion';`
+
+	return r.execUpdate(ctx, query, input.Status, stringToNullString(input.Reason), input.Id, input.FromStatus)
+}
+
+func (r SqliteRepository) InsertEmailVerification(ctx context.Context, input InsertEmailVerificationInput) (*InsertEmailVerificationOutput, error) {
+
+	var lastInsertId int64
+
+	query := `INSERT INTO email_verifications (user_id, email, token_hash, expired_at) VALUES (?, ?, ?, ?) RETURNING id;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryStatement.QueryRowContext(ctx, input.UserId, input.Email, input.TokenHash, input.ExpiredAt).Scan(&lastInsertId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &InsertEmailVerificationOutput{
+		Id: lastInsertId,
+	}
+
+	return output, nil
+}
+
+func (r SqliteRepository) GetEmailVerificationByTokenHash(ctx context.Context, input GetEmailVerificationByTokenHashInput) (*GetEmailVerificationOutput, error) {
+
+	query := `SELECT id, user_id, email, expired_at, verified_at, created_at FROM email_verifications WHERE token_hash = ?;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := GetEmailVerificationOutput{}
+
+	var verifiedAt sql.NullTime
+
+	err = queryStatement.QueryRowContext(ctx, input.TokenHash).
+		Scan(&result.Id, &result.UserId, &result.Email, &result.ExpiredAt, &verifiedAt, &result.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	result.VerifiedAt = nullTimeToPointer(verifiedAt)
+
+	return &result, nil
+}
+
+// VerifyEmail does in a transaction what Repository.VerifyEmail does in one statement,
+// SQLite has no UPDATE inside WITH.
+func (r SqliteRepository) VerifyEmail(ctx context.Context, input VerifyEmailInput) (*VerifyEmailOutput, error) {
+
+	output := &VerifyEmailOutput{}
+
+	err := r.withinTransaction(ctx, func(txRepository SqliteRepository) error {
+
+		var userId int64
+		var email string
+
+		err := txRepository.tx.QueryRowContext(ctx, `UPDATE email_verifications SET verified_at = `+sqliteNow+`
+			WHERE id = ? AND verified_at IS NULL RETURNING user_id, email;`, input.EmailVerificationId).Scan(&userId, &email)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		updateOutput, err := txRepository.execUpdate(ctx, `UPDATE users SET email_verified_at = `+sqliteNow+` WHERE id = ? AND email = ?;`, userId, email)
+
+		if err != nil {
+			return err
+		}
+
+		output.IsVerified = updateOutput.IsSuccessUpdate
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
@@ -0,0 +1,97 @@
+// This file contains the SQLite repository implementation of the login events.
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+func (r SqliteRepository) InsertLoginEvent(ctx context.Context, input InsertLoginEventInput) (*InsertLoginEventOutput, error) {
+
+	var lastInsertId int64
+
+	query := `INSERT INTO login_events (user_id, is_success, failure_reason, method, ip_address, user_agent) VALUES (?, ?, ?, ?, ?, ?) RETURNING id;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryStatement.QueryRowContext(ctx, optionalIdToNullInt64(input.UserId), input.IsSuccess, stringToNullString(input.FailureReason),
+		input.Method, input.IpAddress, input.UserAgent).Scan(&lastInsertId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &InsertLoginEventOutput{
+		Id: lastInsertId,
+	}
+
+	return output, nil
+}
+
+func (r SqliteRepository) GetLoginEventsByUserId(ctx context.Context, input GetLoginEventsByUserIdInput) (*GetLoginEventsByUserIdOutput, error) {
+
+	countStatement, err := r.prepare(ctx, `SELECT COUNT(*) FROM login_events WHERE user_id = ?;`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &GetLoginEventsByUserIdOutput{
+		LoginEvents: []LoginEventOutput{},
+	}
+
+	err = countStatement.QueryRowContext(ctx, input.UserId).Scan(&output.Total)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, user_id, is_success, failure_reason, method, ip_address, user_agent, created_at FROM login_events
+		WHERE user_id = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queryStatement.QueryContext(ctx, input.UserId, input.Limit, input.Offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+
+		loginEvent := LoginEventOutput{}
+
+		var userId sql.NullInt64
+		var failureReason sql.NullString
+
+		err = rows.Scan(&loginEvent.Id, &userId, &loginEvent.IsSuccess, &failureReason, &loginEvent.Method,
+			&loginEvent.IpAddress, &loginEvent.UserAgent, &loginEvent.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		loginEvent.UserId = nullInt64ToPointer(userId)
+		loginEvent.FailureReason = failureReason.String
+
+		output.LoginEvents = append(output.LoginEvents, loginEvent)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
@@ -0,0 +1,30 @@
+-- Timestamps are stored as UTC text with milliseconds, the format the SQLite repository writes and compares.
+CREATE TABLE users
+(
+    id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+    phone_number          VARCHAR(13)  NOT NULL UNIQUE,
+    full_name             VARCHAR(60)  NOT NULL,
+    email                 VARCHAR(255) NULL UNIQUE,
+    email_verified_at     TIMESTAMP    NULL,
+    password              VARCHAR(255) NOT NULL,
+    role                  VARCHAR(20)  NOT NULL DEFAULT 'user',
+    status                VARCHAR(20)  NOT NULL DEFAULT 'active'
+        CHECK (status IN ('pending', 'active', 'suspended', 'locked', 'pending_deletion', 'deleted')),
+    status_reason         VARCHAR(255) NULL,
+    status_changed_at     TIMESTAMP    NULL,
+    deletion_scheduled_at TIMESTAMP    NULL,
+    login_success_count   INTEGER      DEFAULT 0,
+    created_at            TIMESTAMP    DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at            TIMESTAMP    DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX users_pending_deletion_index ON users (deletion_scheduled_at) WHERE status = 'pending_deletion';
+
+-- Recursive triggers are off by default, so the update below does not fire the trigger again.
+CREATE TRIGGER update_users_updated_at
+AFTER UPDATE
+ON users
+FOR EACH ROW
+BEGIN
+    UPDATE users SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,12 @@
+CREATE TABLE email_verifications
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id     INTEGER      NOT NULL REFERENCES users (id),
+    email       VARCHAR(255) NOT NULL,
+    token_hash  VARCHAR(64)  NOT NULL UNIQUE,
+    expired_at  TIMESTAMP    NOT NULL,
+    verified_at TIMESTAMP    NULL,
+    created_at  TIMESTAMP    DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX email_verifications_user_id_index ON email_verifications (user_id);
@@ -0,0 +1,18 @@
+-- Personal data exports. The archive itself is stored outside the database (file_name),
+-- the download link token is only kept as a hash.
+CREATE TABLE data_exports
+(
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id             INTEGER      NOT NULL REFERENCES users (id),
+    status              VARCHAR(20)  NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    is_encrypted        BOOLEAN      NOT NULL DEFAULT FALSE,
+    download_token_hash VARCHAR(64)  NOT NULL UNIQUE,
+    file_name           VARCHAR(255) NULL,
+    failure_reason      VARCHAR(255) NULL,
+    expired_at          TIMESTAMP    NOT NULL,
+    completed_at        TIMESTAMP    NULL,
+    created_at          TIMESTAMP    DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX data_exports_user_id_index ON data_exports (user_id);
@@ -0,0 +1,33 @@
+-- Append-only audit log. Every entry stores the hash of the previous entry, see repository.ComputeAuditLogHash.
+-- changes is TEXT on purpose: the stored text must stay byte-identical to the hashed text.
+CREATE TABLE audit_logs
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_user_id   INTEGER      NULL REFERENCES users (id),
+    subject_user_id INTEGER      NULL REFERENCES users (id),
+    action          VARCHAR(40)  NOT NULL,
+    changes         TEXT         NOT NULL,
+    request_id      VARCHAR(64)  NOT NULL,
+    ip_address      VARCHAR(45)  NOT NULL,
+    previous_hash   VARCHAR(64)  NOT NULL,
+    hash            VARCHAR(64)  NOT NULL UNIQUE,
+    created_at      TIMESTAMP    NOT NULL
+);
+
+CREATE INDEX audit_logs_subject_user_id_index ON audit_logs (subject_user_id);
+CREATE INDEX audit_logs_actor_user_id_index ON audit_logs (actor_user_id);
+
+-- SQLite has no TRUNCATE, DELETE FROM without WHERE fires the delete trigger.
+CREATE TRIGGER prevent_audit_logs_update
+BEFORE UPDATE
+ON audit_logs
+BEGIN
+    SELECT RAISE(ABORT, 'audit_logs is append-only');
+END;
+
+CREATE TRIGGER prevent_audit_logs_delete
+BEFORE DELETE
+ON audit_logs
+BEGIN
+    SELECT RAISE(ABORT, 'audit_logs is append-only');
+END;
@@ -0,0 +1,3 @@
+-- SQLite serves a single app instance, its user cache is invalidated by the writes of that instance.
+-- The migration only keeps the versions in step with the Postgres migrations.
+SELECT 1;
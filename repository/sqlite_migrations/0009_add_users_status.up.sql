@@ -0,0 +1,3 @@
+-- No SQLite database was created from the former database.sql, 0001 already has the columns. The migration
+-- exists to keep the versions in step with the Postgres ones.
+SELECT 1;
@@ -0,0 +1,8 @@
+-- A user has at most one export being built. The older ones of users who already have several are given up first.
+UPDATE data_exports
+SET status         = 'failed',
+    failure_reason = 'The export was replaced by a newer one'
+WHERE status IN ('pending', 'processing')
+  AND id NOT IN (SELECT max(id) FROM data_exports WHERE status IN ('pending', 'processing') GROUP BY user_id);
+
+CREATE UNIQUE INDEX data_exports_user_id_pending_unique_index ON data_exports (user_id) WHERE status IN ('pending', 'processing');
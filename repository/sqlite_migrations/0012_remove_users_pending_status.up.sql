@@ -0,0 +1,3 @@
+-- SQLite cannot change the check of a column without rebuilding the table, the app never writes pending anyway.
+-- The migration only keeps the versions in step with the Postgres migrations.
+SELECT 1;
@@ -0,0 +1,143 @@
+// This file contains the SQLite repository implementation of the self-service account deletion.
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScheduleDeletion moves an active user into pending deletion until the grace period is over.
+// The deadline is computed by the database so it compares consistently with the current time later.
+func (r SqliteRepository) ScheduleDeletion(ctx context.Context, input ScheduleUserDeletionInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET status = 'pending_deletion', status_reason = ?, status_changed_at = ` + sqliteNow + `,
+		deletion_scheduled_at = strftime('%Y-%m-%d %H:%M:%f', 'now', ?) WHERE id = ? AND status = 'active';`
+
+	return r.execUpdate(ctx, query, stringToNullString(input.Reason), fmt.Sprintf("%+f seconds", input.GracePeriod.Seconds()), input.Id)
+}
+
+// CancelDeletion moves a user that is pending deletion back to active.
+func (r SqliteRepository) CancelDeletion(ctx context.Context, input CancelUserDeletionInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET status = 'active', status_reason = NULL, status_changed_at = ` + sqliteNow + `, deletion_scheduled_at = NULL
+		WHERE id = ? AND status = 'pending_deletion';`
+
+	return r.execUpdate(ctx, query, input.Id)
+}
+
+func (r SqliteRepository) GetUsersDueForDeletion(ctx context.Context, input GetUsersDueForDeletionInput) (*GetUsersDueForDeletionOutput, error) {
+
+	query := `SELECT id FROM users WHERE status = 'pending_deletion' AND deletion_scheduled_at <= ` + sqliteNow + ` ORDER BY deletion_scheduled_at LIMIT ?;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queryStatement.QueryContext(ctx, input.Limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	output := &GetUsersDueForDeletionOutput{
+		UserIds: []int64{},
+	}
+
+	for rows.Next() {
+
+		var userId int64
+
+		err = rows.Scan(&userId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		output.UserIds = append(output.UserIds, userId)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// AnonymizeUser works like Repository.AnonymizeUser.
+func (r SqliteRepository) AnonymizeUser(ctx context.Context, input AnonymizeUserInput) (*AnonymizeUserOutput, error) {
+
+	output := &AnonymizeUserOutput{}
+
+	err := r.withinTransaction(ctx, func(txRepository SqliteRepository) error {
+
+		execResult, err := txRepository.tx.ExecContext(ctx, `UPDATE users SET phone_number = '#' || id, full_name = ?, email = NULL, email_verified_at = NULL,
+		password = '', status = 'deleted', status_reason = ?, status_changed_at = `+sqliteNow+`, deletion_scheduled_at = NULL
+		WHERE id = ? AND status = ? AND (status <> 'pending_deletion' OR deletion_scheduled_at <= `+sqliteNow+`);`,
+			input.FullName, input.Reason, input.Id, input.FromStatus)
+
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := execResult.RowsAffected()
+
+		if err != nil {
+			return err
+		}
+
+		// Nothing was changed, so there is nothing else to clean up.
+		if rowsAffected == 0 {
+			return nil
+		}
+
+		_, err = txRepository.tx.ExecContext(ctx, `DELETE FROM email_verifications WHERE user_id = ?;`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = txRepository.tx.ExecContext(ctx, `UPDATE login_events SET ip_address = '', user_agent = '' WHERE user_id = ?;`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		// The entries about the user lose their personal data, the ones the user acted in only the user's ip address.
+		_, err = txRepository.tx.ExecContext(ctx, `DELETE FROM audit_log_personal_data
+		WHERE audit_log_id IN (SELECT id FROM audit_logs WHERE subject_user_id = ?);`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = txRepository.tx.ExecContext(ctx, `UPDATE audit_log_personal_data SET ip_address = ''
+		WHERE audit_log_id IN (SELECT id FROM audit_logs WHERE actor_user_id = ?);`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = txRepository.tx.ExecContext(ctx, `UPDATE data_exports SET expired_at = `+sqliteNow+`
+		WHERE user_id = ? AND julianday(expired_at) > julianday('now');`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		output.IsAnonymized = true
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
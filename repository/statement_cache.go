@@ -0,0 +1,76 @@
+// This file contains the cache of prepared statements shared by the repository methods.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// statementCache prepares every query once and reuses the statement. database/sql prepares a
+// statement again on each pool connection it runs on, so it stays valid when connections are replaced.
+type statementCache struct {
+	db         *sql.DB
+	mutex      sync.RWMutex
+	statements map[string]*sql.Stmt
+}
+
+func (s *statementCache) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+
+	s.mutex.RLock()
+	statement, ok := s.statements[query]
+	s.mutex.RUnlock()
+
+	if ok {
+		return statement, nil
+	}
+
+	// Preparing is a round trip to the database, the other queries are not held up while it runs.
+	statement, err := s.db.PrepareContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Another call prepared the query in the meantime, its statement is kept.
+	if cachedStatement, ok := s.statements[query]; ok {
+		_ = statement.Close()
+		return cachedStatement, nil
+	}
+
+	s.statements[query] = statement
+
+	return statement, nil
+}
+
+// close closes every cached statement. Statements prepared afterwards are cached again.
+func (s *statementCache) close() error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var firstErr error
+
+	for query, statement := range s.statements {
+
+		err := statement.Close()
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		delete(s.statements, query)
+	}
+
+	return firstErr
+}
+
+func newStatementCache(db *sql.DB) *statementCache {
+	return &statementCache{
+		db:         db,
+		statements: map[string]*sql.Stmt{},
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+)
+
+func TestStatementCache_prepare(t *testing.T) {
+
+	ctx := context.Background()
+	cache := newStatementCache(openTestSqliteRepository(t).Db)
+
+	t.Cleanup(func() {
+		cache.close()
+	})
+
+	statements := make([]*sql.Stmt, 10)
+
+	var wg sync.WaitGroup
+
+	for i := range statements {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			statement, err := cache.prepare(ctx, `SELECT count(*) FROM users;`)
+
+			if err != nil {
+				t.Errorf("prepare() error = %v", err)
+				return
+			}
+
+			statements[i] = statement
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, statement := range statements {
+		if statement != statements[0] {
+			t.Fatalf("prepare() statement %d differs from the cached one", i)
+		}
+	}
+
+	var count int
+
+	if err := statements[0].QueryRowContext(ctx).Scan(&count); err != nil {
+		t.Errorf("QueryRowContext() of the cached statement error = %v", err)
+	}
+}
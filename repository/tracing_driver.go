@@ -0,0 +1,265 @@
+// This file contains the database/sql driver wrapper that traces every statement the repository runs.
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// tracer uses the global tracer provider, so the spans go wherever cmd configures them to go, or nowhere.
+var tracer = otel.Tracer("github.com/SawitProRecruitment/UserService/repository")
+
+// dsnConnector is the driver.Connector of drivers that only implement driver.Driver.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (d dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return d.driver.Open(d.dsn)
+}
+
+func (d dsnConnector) Driver() driver.Driver {
+	return d.driver
+}
+
+// tracedConnector hands out connections that start a client span per statement. A span carries the statement
+// with its placeholders, never the arguments, which hold the phone numbers, emails and password hashes.
+type tracedConnector struct {
+	connector driver.Connector
+	system    attribute.KeyValue
+}
+
+func (t tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+
+	conn, err := t.connector.Connect(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracedConn{Conn: conn, system: t.system}, nil
+}
+
+func (t tracedConnector) Driver() driver.Driver {
+	return t.connector.Driver()
+}
+
+func newTracedConnector(connector driver.Connector, system attribute.KeyValue) driver.Connector {
+	return tracedConnector{connector: connector, system: system}
+}
+
+func startStatementSpan(ctx context.Context, system attribute.KeyValue, query string) (context.Context, trace.Span) {
+
+	operation := "SQL"
+
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	return tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		system,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(query),
+	))
+}
+
+func endStatementSpan(span trace.Span, err error) {
+
+	// driver.ErrSkip only tells database/sql to prepare the statement instead, the prepared statement gets its own span.
+	if err != nil && errors.Is(err, driver.ErrSkip) == false {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+type tracedConn struct {
+	driver.Conn
+	system attribute.KeyValue
+}
+
+func (t *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+
+	var statement driver.Stmt
+	var err error
+
+	if preparer, ok := t.Conn.(driver.ConnPrepareContext); ok {
+		statement, err = preparer.PrepareContext(ctx, query)
+	} else {
+		statement, err = t.Conn.Prepare(query)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracedStmt{Stmt: statement, system: t.system, query: query}, nil
+}
+
+func (t *tracedConn) Prepare(query string) (driver.Stmt, error) {
+	return t.PrepareContext(context.Background(), query)
+}
+
+func (t *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+
+	beginner, ok := t.Conn.(driver.ConnBeginTx)
+
+	if ok == false {
+		return nil, errors.New("The database driver does not support transaction options")
+	}
+
+	spanCtx, span := startStatementSpan(ctx, t.system, "BEGIN")
+	tx, err := beginner.BeginTx(spanCtx, opts)
+	endStatementSpan(span, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracedTx{Tx: tx, ctx: ctx, system: t.system}, nil
+}
+
+func (t *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+
+	execer, ok := t.Conn.(driver.ExecerContext)
+
+	if ok == false {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startStatementSpan(ctx, t.system, query)
+	result, err := execer.ExecContext(ctx, query, args)
+	endStatementSpan(span, err)
+
+	return result, err
+}
+
+// QueryContext ends the span when the query returns, reading the rows is not part of it.
+func (t *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+
+	queryer, ok := t.Conn.(driver.QueryerContext)
+
+	if ok == false {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startStatementSpan(ctx, t.system, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	endStatementSpan(span, err)
+
+	return rows, err
+}
+
+func (t *tracedConn) Ping(ctx context.Context) error {
+
+	if pinger, ok := t.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (t *tracedConn) ResetSession(ctx context.Context) error {
+
+	if resetter, ok := t.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (t *tracedConn) IsValid() bool {
+
+	if validator, ok := t.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+type tracedStmt struct {
+	driver.Stmt
+	system attribute.KeyValue
+	query  string
+}
+
+func (t *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+
+	ctx, span := startStatementSpan(ctx, t.system, t.query)
+
+	var result driver.Result
+	var err error
+
+	if execer, ok := t.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = t.Stmt.Exec(namedValuesToValues(args))
+	}
+
+	endStatementSpan(span, err)
+
+	return result, err
+}
+
+func (t *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+
+	ctx, span := startStatementSpan(ctx, t.system, t.query)
+
+	var rows driver.Rows
+	var err error
+
+	if queryer, ok := t.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = t.Stmt.Query(namedValuesToValues(args))
+	}
+
+	endStatementSpan(span, err)
+
+	return rows, err
+}
+
+func namedValuesToValues(args []driver.NamedValue) []driver.Value {
+
+	values := make([]driver.Value, len(args))
+
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	return values
+}
+
+// tracedTx parents the COMMIT and ROLLBACK spans to the context the transaction was started with.
+type tracedTx struct {
+	driver.Tx
+	ctx    context.Context
+	system attribute.KeyValue
+}
+
+func (t *tracedTx) Commit() error {
+
+	_, span := startStatementSpan(t.ctx, t.system, "COMMIT")
+	err := t.Tx.Commit()
+	endStatementSpan(span, err)
+
+	return err
+}
+
+func (t *tracedTx) Rollback() error {
+
+	_, span := startStatementSpan(t.ctx, t.system, "ROLLBACK")
+	err := t.Tx.Rollback()
+	endStatementSpan(span, err)
+
+	return err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"strings"
+	"testing"
+)
+
+func TestTracedConnector_Statements(t *testing.T) {
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	repo := openTestSqliteRepository(t)
+
+	ctx, parentSpan := otel.Tracer("test").Start(context.Background(), "test")
+
+	input := InsertUserInput{
+		PhoneNumber: "+628111222333",
+		FullName:    "Traced User",
+		Email:       "traced@example.com",
+		Password:    "hashed-password",
+	}
+
+	err := repo.WithinTransaction(ctx, TransactionOptions{}, func(txRepository TransactionRepositoryInterface) error {
+		_, err := txRepository.Insert(ctx, input)
+		return err
+	})
+
+	parentSpan.End()
+
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	var gotOperations []string
+
+	for _, span := range recorder.Ended() {
+
+		if span.Parent().SpanID() != parentSpan.SpanContext().SpanID() {
+			continue
+		}
+
+		gotOperations = append(gotOperations, span.Name())
+
+		for _, attribute := range span.Attributes() {
+
+			value := attribute.Value.Emit()
+
+			for _, secret := range []string{input.PhoneNumber, input.FullName, input.Email, input.Password} {
+				if strings.Contains(value, secret) {
+					t.Errorf("span %s attribute %s = %q, contains the argument %q", span.Name(), attribute.Key, value, secret)
+				}
+			}
+
+			if attribute.Key == semconv.DBQueryTextKey && span.Name() == "INSERT" && strings.Contains(value, "INSERT INTO users") == false {
+				t.Errorf("span %s statement = %q, want the INSERT INTO users statement", span.Name(), value)
+			}
+		}
+	}
+
+	if strings.Join(gotOperations, ",") != "BEGIN,INSERT,COMMIT" {
+		t.Errorf("statement spans = %v, want [BEGIN INSERT COMMIT]", gotOperations)
+	}
+}
@@ -0,0 +1,108 @@
+// This file contains the unit of work support of the repository.
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+const (
+	IsolationLevelDefault        = sql.LevelDefault
+	IsolationLevelReadCommitted  = sql.LevelReadCommitted
+	IsolationLevelRepeatableRead = sql.LevelRepeatableRead
+	IsolationLevelSerializable   = sql.LevelSerializable
+)
+
+// queryExecutor is what *sql.DB and *sql.Tx have in common, queries run on the transaction when there is one.
+type queryExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (r Repository) executor() queryExecutor {
+
+	if r.tx != nil {
+		return r.tx
+	}
+
+	return r.Db
+}
+
+// prepare returns the cached statement of query, bound to the transaction when there is one.
+func (r Repository) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+
+	statement, err := r.statements.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.tx != nil {
+		return r.tx.StmtContext(ctx, statement), nil
+	}
+
+	return statement, nil
+}
+
+// WithinTransaction runs fn with a repository whose calls all run in one transaction. The transaction is
+// committed when fn returns nil and rolled back when fn returns an error or panics. Calling it on a repository
+// that is already in a transaction runs fn in that transaction.
+func (r Repository) WithinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+
+	return r.withinTransaction(ctx, opts, func(txRepository Repository) error {
+		return fn(txRepository)
+	})
+}
+
+func (r Repository) withinTransaction(ctx context.Context, opts TransactionOptions, fn func(txRepository Repository) error) (err error) {
+
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	if opts.ReadOnly == false {
+		PinToPrimary(ctx)
+	}
+
+	tx, err := r.Db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: opts.IsolationLevel,
+		ReadOnly:  opts.ReadOnly,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+
+		if recovered := recover(); recovered != nil {
+			_ = tx.Rollback()
+			panic(recovered)
+		}
+
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	txRepository := r
+	txRepository.tx = tx
+
+	err = fn(txRepository)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RunTransactionDirectly lets a mocked WithinTransaction run fn against the mock itself, e.g.
+// mock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(RunTransactionDirectly(mock)).AnyTimes()
+func RunTransactionDirectly(repository TransactionRepositoryInterface) func(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+
+	return func(ctx context.Context, opts TransactionOptions, fn func(txRepository TransactionRepositoryInterface) error) error {
+		return fn(repository)
+	}
+}
@@ -1,10 +1,19 @@
 // This file contains types that are used in the repository layer.
 package repository
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 // Query Struct
 
+// TransactionOptions chooses the isolation level of a transaction, IsolationLevelDefault uses the database default.
+type TransactionOptions struct {
+	IsolationLevel sql.IsolationLevel
+	ReadOnly       bool
+}
+
 type GetUserByIdInput struct {
 	Id int64
 }
@@ -13,39 +22,224 @@ type GetUserByPhoneNumberInput struct {
 	PhoneNumber string
 }
 
+type GetUserByEmailInput struct {
+	Email string
+}
+
 type InsertUserInput struct {
 	PhoneNumber string
 	FullName    string
+	Email       string
 	Password    string
 }
 
+// UpdateUserInput only writes the fields listed in Fields, the other values are ignored.
 type UpdateUserInput struct {
+	Id     int64
+	Fields []string
+
+	PhoneNumber string
+	FullName    string
+}
+
+type IncrementLoginSuccessCountInput struct {
+	Id int64
+}
+
+type UpdateUserEmailInput struct {
+	Id    int64
+	Email string
+}
+
+type UpdateUserRoleInput struct {
+	Id   int64
+	Role string
+}
+
+// UpdateUserStatusInput moves the user from FromStatus to Status.
+type UpdateUserStatusInput struct {
+	Id         int64
+	FromStatus string
+	Status     string
+	Reason     string
+}
+
+type ScheduleUserDeletionInput struct {
+	Id          int64
+	Reason      string
+	GracePeriod time.Duration
+}
+
+type CancelUserDeletionInput struct {
+	Id int64
+}
+
+type GetUsersDueForDeletionInput struct {
+	Limit int
+}
+
+type AnonymizeUserInput struct {
+	Id int64
+	// FromStatus is the status the user must still have. A user pending deletion is only anonymized once its grace
+	// period is over.
+	FromStatus string
+	// FullName and Reason replace the user's name and status reason.
+	FullName string
+	Reason   string
+}
+
+type InsertEmailVerificationInput struct {
+	UserId    int64
+	Email     string
+	TokenHash string
+	ExpiredAt time.Time
+}
+
+type GetEmailVerificationByTokenHashInput struct {
+	TokenHash string
+}
+
+type VerifyEmailInput struct {
+	EmailVerificationId int64
+}
+
+type InsertLoginEventInput struct {
+	// UserId is nil when the login identifier did not match any user.
+	UserId        *int64
+	IsSuccess     bool
+	FailureReason string
+	Method        string
+	IpAddress     string
+	UserAgent     string
+}
+
+type GetLoginEventsByUserIdInput struct {
+	UserId int64
+	Limit  int
+	Offset int
+}
+
+type AppendAuditLogInput struct {
+	ActorUserId   *int64
+	SubjectUserId *int64
+	Action        string
+	// Changes is the JSON encoded before/after diff, stored and hashed as is. The chain can never be erased, so it
+	// and IpAddress hold no personal data, only the entries appended before PersonalChanges existed still do.
+	Changes   string
+	RequestId string
+	IpAddress string
+	// PersonalChanges and PersonalIpAddress are stored outside the chain, AnonymizeUser deletes them. Nothing is
+	// stored when PersonalChanges is empty.
+	PersonalChanges   string
+	PersonalIpAddress string
+	CreatedAt         time.Time
+}
+
+type GetAuditLogsInput struct {
+	ActorUserId   *int64
+	SubjectUserId *int64
+	Action        string
+	Limit         int
+	Offset        int
+}
+
+type GetAuditLogsAfterIdInput struct {
+	AfterId int64
+	Limit   int
+}
+
+type InsertDataExportInput struct {
+	UserId            int64
+	IsEncrypted       bool
+	DownloadTokenHash string
+	ExpiredAt         time.Time
+}
+
+type GetDataExportByIdInput struct {
+	Id     int64
+	UserId int64
+}
+
+type GetDataExportByTokenHashInput struct {
+	DownloadTokenHash string
+}
+
+type UpdateDataExportStatusInput struct {
 	Id int64
+	// FromStatus is the status the export must still have, an export failed or expired in the meantime is left alone.
+	FromStatus    string
+	Status        string
+	FileName      string
+	FailureReason string
+}
+
+type GetExpiredDataExportsInput struct {
+	Limit int
+}
 
-	PhoneNumber       string
-	FullName          string
-	LoginSuccessCount int64
+type ClearDataExportFileInput struct {
+	Id int64
+}
+
+type FailStaleDataExportsInput struct {
+	// CreatedBefore leaves the exports alone that may still be built by a running instance.
+	CreatedBefore time.Time
+	FailureReason string
 }
 
 // Output struct
 
 type GetUserByIdOutput struct {
-	Id                int64
-	PhoneNumber       string
-	FullName          string
-	LoginSuccessCount int64
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	Id              int64
+	PhoneNumber     string
+	FullName        string
+	Email           string
+	EmailVerifiedAt *time.Time
+	Role            string
+	Status          string
+	StatusReason    string
+	StatusChangedAt *time.Time
+	// DeletionScheduledAt is set while the account is pending deletion.
+	DeletionScheduledAt *time.Time
+	LoginSuccessCount   int64
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
 }
 
 type GetUserByPhoneNumberOutput struct {
-	Id                int64
-	PhoneNumber       string
-	FullName          string
-	LoginSuccessCount int64
-	Password          string
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	Id              int64
+	PhoneNumber     string
+	FullName        string
+	Email           string
+	EmailVerifiedAt *time.Time
+	Role            string
+	Status          string
+	StatusReason    string
+	StatusChangedAt *time.Time
+	// DeletionScheduledAt is set while the account is pending deletion.
+	DeletionScheduledAt *time.Time
+	LoginSuccessCount   int64
+	Password            string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+type GetUserByEmailOutput struct {
+	Id              int64
+	PhoneNumber     string
+	FullName        string
+	Email           string
+	EmailVerifiedAt *time.Time
+	Role            string
+	Status          string
+	StatusReason    string
+	StatusChangedAt *time.Time
+	// DeletionScheduledAt is set while the account is pending deletion.
+	DeletionScheduledAt *time.Time
+	LoginSuccessCount   int64
+	Password            string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
 }
 
 type InsertUserOutput struct {
@@ -55,3 +249,124 @@ type InsertUserOutput struct {
 type UpdateUserOutput struct {
 	IsSuccessUpdate bool
 }
+
+type GetUsersDueForDeletionOutput struct {
+	UserIds []int64
+}
+
+type AnonymizeUserOutput struct {
+	IsAnonymized bool
+}
+
+type InsertEmailVerificationOutput struct {
+	Id int64
+}
+
+type GetEmailVerificationOutput struct {
+	Id         int64
+	UserId     int64
+	Email      string
+	ExpiredAt  time.Time
+	VerifiedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type VerifyEmailOutput struct {
+	IsVerified bool
+}
+
+type InsertLoginEventOutput struct {
+	Id int64
+}
+
+type LoginEventOutput struct {
+	Id            int64
+	UserId        *int64
+	IsSuccess     bool
+	FailureReason string
+	Method        string
+	IpAddress     string
+	UserAgent     string
+	CreatedAt     time.Time
+}
+
+type GetLoginEventsByUserIdOutput struct {
+	LoginEvents []LoginEventOutput
+	Total       int64
+}
+
+type AppendAuditLogOutput struct {
+	Id   int64
+	Hash string
+}
+
+type AuditLogOutput struct {
+	Id            int64
+	ActorUserId   *int64
+	SubjectUserId *int64
+	Action        string
+	Changes       string
+	RequestId     string
+	IpAddress     string
+	// PersonalChanges and PersonalIpAddress are empty once AnonymizeUser deleted them.
+	PersonalChanges   string
+	PersonalIpAddress string
+	PreviousHash      string
+	Hash              string
+	CreatedAt         time.Time
+}
+
+type GetAuditLogsOutput struct {
+	AuditLogs []AuditLogOutput
+	Total     int64
+}
+
+type InsertDataExportOutput struct {
+	Id int64
+}
+
+type UpdateDataExportOutput struct {
+	IsSuccessUpdate bool
+}
+
+type GetDataExportsOutput struct {
+	DataExports []DataExportOutput
+}
+
+type FailStaleDataExportsOutput struct {
+	FailedCount int64
+}
+
+type DataExportOutput struct {
+	Id            int64
+	UserId        int64
+	Status        string
+	IsEncrypted   bool
+	FileName      string
+	FailureReason string
+	ExpiredAt     time.Time
+	CompletedAt   *time.Time
+	CreatedAt     time.Time
+}
+
+type PoolStatsOutput struct {
+	MaxOpenConnections int
+	OpenConnections    int
+	InUse              int
+	Idle               int
+	WaitCount          int64
+	WaitDuration       time.Duration
+	MaxIdleClosed      int64
+	MaxIdleTimeClosed  int64
+	MaxLifetimeClosed  int64
+	// CircuitBreakerState is one of CircuitBreakerClosed, CircuitBreakerOpen and CircuitBreakerHalfOpen.
+	CircuitBreakerState string
+}
+
+type UserCacheStatsOutput struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Size      int
+	Capacity  int
+}
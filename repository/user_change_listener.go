@@ -0,0 +1,102 @@
+// This file contains the listener that invalidates the cached users other instances changed.
+package repository
+
+import (
+	"github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+// UserChangesChannel is notified by the users table trigger with the id of every updated or deleted user.
+const UserChangesChannel = "user_changes"
+
+const (
+	userChangeListenerMinReconnectInterval = time.Second
+	userChangeListenerMaxReconnectInterval = time.Minute
+	// userChangeListenerPingInterval is how long the listener waits for a notification before checking its connection.
+	userChangeListenerPingInterval = 90 * time.Second
+)
+
+// UserChangeListener keeps a dedicated connection listening to UserChangesChannel and drops the notified users from
+// the cache. The notifications sent while it reconnects are lost, so it clears the whole cache after a reconnect.
+type UserChangeListener struct {
+	listener *pq.Listener
+	cache    *CachedUserRepository
+	done     chan struct{}
+}
+
+type NewUserChangeListenerOptions struct {
+	Dsn   string
+	Cache *CachedUserRepository
+	// OnError is called with the errors of the connection, the listener reconnects by itself.
+	OnError func(err error)
+}
+
+func (l *UserChangeListener) run() {
+
+	for {
+
+		select {
+		case <-l.done:
+			return
+		case notification, ok := <-l.listener.Notify:
+
+			if ok == false {
+				return
+			}
+
+			// A nil notification follows a reconnect.
+			if notification == nil {
+				l.cache.Purge()
+				continue
+			}
+
+			id, err := strconv.ParseInt(notification.Extra, 10, 64)
+
+			if err != nil {
+				l.cache.Purge()
+				continue
+			}
+
+			l.cache.Invalidate(id)
+		case <-time.After(userChangeListenerPingInterval):
+			// A connection that died silently is only noticed when it is used.
+			go l.listener.Ping()
+		}
+	}
+}
+
+// Close stops listening and closes the connection.
+func (l *UserChangeListener) Close() error {
+
+	close(l.done)
+
+	return l.listener.Close()
+}
+
+func NewUserChangeListener(opts NewUserChangeListenerOptions) (*UserChangeListener, error) {
+
+	listener := pq.NewListener(opts.Dsn, userChangeListenerMinReconnectInterval, userChangeListenerMaxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil && opts.OnError != nil {
+				opts.OnError(err)
+			}
+		})
+
+	err := listener.Listen(UserChangesChannel)
+
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	userChangeListener := &UserChangeListener{
+		listener: listener,
+		cache:    opts.Cache,
+		done:     make(chan struct{}),
+	}
+
+	go userChangeListener.run()
+
+	return userChangeListener, nil
+}
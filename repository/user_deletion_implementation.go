@@ -0,0 +1,190 @@
+// This file contains the repository implementation of the self-service account deletion.
+package repository
+
+import (
+	"context"
+)
+
+// ScheduleDeletion moves an active user into pending deletion until the grace period is over.
+// The deadline is computed by the database so it compares consistently with now() later.
+func (r Repository) ScheduleDeletion(ctx context.Context, input ScheduleUserDeletionInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET status = 'pending_deletion', status_reason = $1, status_changed_at = now(),
+		deletion_scheduled_at = now() + make_interval(secs => $2) WHERE id = $3 AND status = 'active';`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, stringToNullString(input.Reason), input.GracePeriod.Seconds(), input.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateUserOutput{
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+// CancelDeletion moves a user that is pending deletion back to active.
+func (r Repository) CancelDeletion(ctx context.Context, input CancelUserDeletionInput) (*UpdateUserOutput, error) {
+
+	query := `UPDATE users SET status = 'active', status_reason = NULL, status_changed_at = now(), deletion_scheduled_at = NULL
+		WHERE id = $1 AND status = 'pending_deletion';`
+
+	queryStatement, err := r.prepareWrite(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	execResult, err := queryStatement.ExecContext(ctx, input.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpdateUserOutput{
+		IsSuccessUpdate: rowsAffected > 0,
+	}
+
+	return output, nil
+}
+
+func (r Repository) GetUsersDueForDeletion(ctx context.Context, input GetUsersDueForDeletionInput) (*GetUsersDueForDeletionOutput, error) {
+
+	query := `SELECT id FROM users WHERE status = 'pending_deletion' AND deletion_scheduled_at <= now() ORDER BY deletion_scheduled_at LIMIT $1;`
+
+	queryStatement, err := r.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queryStatement.QueryContext(ctx, input.Limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	output := &GetUsersDueForDeletionOutput{
+		UserIds: []int64{},
+	}
+
+	for rows.Next() {
+
+		var userId int64
+
+		err = rows.Scan(&userId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		output.UserIds = append(output.UserIds, userId)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// AnonymizeUser replaces the personal fields of a user still in input.FromStatus and marks it deleted, a user pending
+// deletion only once its grace period is over.
+// The row itself is kept so login events and audit log entries still reference a user.
+// The phone number becomes '#<id>' to stay unique and within the column length.
+// The download links of the user's exports expire at once, the export cleanup then deletes the archives.
+func (r Repository) AnonymizeUser(ctx context.Context, input AnonymizeUserInput) (*AnonymizeUserOutput, error) {
+
+	output := &AnonymizeUserOutput{}
+
+	err := r.withinTransaction(ctx, TransactionOptions{}, func(txRepository Repository) error {
+
+		execResult, err := txRepository.tx.ExecContext(ctx, `UPDATE users SET phone_number = '#' || id, full_name = $1, email = NULL, email_verified_at = NULL,
+		password = '', status = 'deleted', status_reason = $2, status_changed_at = now(), deletion_scheduled_at = NULL
+		WHERE id = $3 AND status = $4 AND (status <> 'pending_deletion' OR deletion_scheduled_at <= now());`,
+			input.FullName, input.Reason, input.Id, input.FromStatus)
+
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := execResult.RowsAffected()
+
+		if err != nil {
+			return err
+		}
+
+		// Nothing was changed, so there is nothing else to clean up.
+		if rowsAffected == 0 {
+			return nil
+		}
+
+		_, err = txRepository.tx.ExecContext(ctx, `DELETE FROM email_verifications WHERE user_id = $1;`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = txRepository.tx.ExecContext(ctx, `UPDATE login_events SET ip_address = '', user_agent = '' WHERE user_id = $1;`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		// The entries about the user lose their personal data, the ones the user acted in only the user's ip address.
+		_, err = txRepository.tx.ExecContext(ctx, `DELETE FROM audit_log_personal_data
+		WHERE audit_log_id IN (SELECT id FROM audit_logs WHERE subject_user_id = $1);`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = txRepository.tx.ExecContext(ctx, `UPDATE audit_log_personal_data SET ip_address = ''
+		WHERE audit_log_id IN (SELECT id FROM audit_logs WHERE actor_user_id = $1);`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		// The archives are deleted by the export cleanup, until then they cannot be downloaded anymore.
+		_, err = txRepository.tx.ExecContext(ctx, `UPDATE data_exports SET expired_at = now() WHERE user_id = $1 AND expired_at > now();`, input.Id)
+
+		if err != nil {
+			return err
+		}
+
+		output.IsAnonymized = true
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
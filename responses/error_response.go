@@ -0,0 +1,43 @@
+package responses
+
+import (
+	"context"
+	"errors"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// RespondError answers a request whose service call failed, for the handlers and the middlewares alike. Requests the
+// client cancelled or that ran past their timeout are reported as such instead of as an internal error, and so are
+// requests refused while the database is unavailable.
+func RespondError(ctx echo.Context, err error) error {
+
+	var unavailableErr *repository.DatabaseUnavailableError
+
+	if errors.As(err, &unavailableErr) {
+		ctx.Response().Header().Set("Retry-After", strconv.Itoa(unavailableErr.RetryAfterSeconds()))
+		return ctx.JSON(http.StatusServiceUnavailable, "Service Unavailable")
+	}
+
+	requestErr := ctx.Request().Context().Err()
+
+	if errors.Is(err, context.Canceled) || errors.Is(requestErr, context.Canceled) {
+		return ctx.JSON(consts.StatusClientClosedRequest, "Client Closed Request")
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(requestErr, context.DeadlineExceeded) {
+		slog.WarnContext(ctx.Request().Context(), "request timed out", slog.Any("error", err))
+		return ctx.JSON(http.StatusServiceUnavailable, "Service Unavailable")
+	}
+
+	// The caller only learns that something failed, the log has what and, for the errors of the repository, the stack of
+	// the service call that failed.
+	slog.ErrorContext(ctx.Request().Context(), "request failed", slog.Any("error", err), logging.ErrorStack(err))
+
+	return ctx.JSON(http.StatusInternalServerError, "Internal Server Error")
+}
@@ -0,0 +1,5 @@
+package responses
+
+type VerifyEmailResponse struct {
+	IsVerified bool `json:"is_verified"`
+}
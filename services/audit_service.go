@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/forms"
+	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/utils"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+type AuditService struct {
+	auditLogRepository repository.AuditLogRepositoryInterface
+}
+
+// Record appends entry with auditLogRepository, the repository of the transaction making the audited change, so the
+// entry is committed or rolled back together with it.
+func (a AuditService) Record(ctx context.Context, auditLogRepository repository.AuditLogRepositoryInterface, entry AuditEntry) error {
+
+	ctx, span := tracer.Start(ctx, "AuditService.Record")
+	defer span.End()
+
+	// The chain can never be erased, so it only names the personal fields. Their values and the ip address are stored
+	// outside of it, where anonymizing the user deletes them.
+	changes := map[string]pojos.AuditChange{}
+	personalChanges := map[string]pojos.AuditChange{}
+
+	for field, change := range entry.Changes {
+
+		if isPersonalAuditField(field) {
+			changes[field] = pojos.AuditChange{Before: consts.AuditRedactedValue, After: consts.AuditRedactedValue}
+			personalChanges[field] = change
+
+			continue
+		}
+
+		changes[field] = change
+	}
+
+	// encoding/json sorts map keys, so the same changes always encode (and hash) the same way.
+	encodedChanges, err := json.Marshal(changes)
+
+	if err != nil {
+		return err
+	}
+
+	encodedPersonalChanges, err := json.Marshal(personalChanges)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = auditLogRepository.AppendAuditLog(ctx, repository.AppendAuditLogInput{
+		ActorUserId:       entry.ActorUserId,
+		SubjectUserId:     entry.SubjectUserId,
+		Action:            entry.Action,
+		Changes:           string(encodedChanges),
+		RequestId:         entry.Metadata.RequestId,
+		PersonalChanges:   string(encodedPersonalChanges),
+		PersonalIpAddress: entry.Metadata.IpAddress,
+		CreatedAt:         time.Now(),
+	})
+
+	return err
+}
+
+func (a AuditService) GetAuditLogs(ctx context.Context, form forms.AuditLogQueryForm) (*AuditLogsResult, error) {
+
+	ctx, span := tracer.Start(ctx, "AuditService.GetAuditLogs")
+	defer span.End()
+
+	result := AuditLogsResult{}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err := validate.Struct(form)
+
+	if err != nil {
+
+		var validationErrors validator.ValidationErrors
+
+		errors.As(err, &validationErrors)
+
+		validationErrorMessages := utils.CollectValidationErrorMessages(form, validationErrors)
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = validationErrorMessages
+
+		return &result, nil
+	}
+
+	output, err := a.auditLogRepository.GetAuditLogs(ctx, repository.GetAuditLogsInput{
+		ActorUserId:   form.ActorUserId,
+		SubjectUserId: form.SubjectUserId,
+		Action:        form.Action,
+		Limit:         form.PageSize,
+		Offset:        (form.Page - 1) * form.PageSize,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	auditLogs := make([]pojos.AuditLog, 0, len(output.AuditLogs))
+
+	for _, auditLogOutput := range output.AuditLogs {
+
+		auditLog, err := a.buildAuditLog(auditLogOutput)
+
+		if err != nil {
+			return nil, err
+		}
+
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	result.HasValidationErrors = false
+	result.ValidationErrors = map[string]string{}
+	result.AuditLogs = pojos.AuditLogs{
+		Data:     auditLogs,
+		Page:     form.Page,
+		PageSize: form.PageSize,
+		Total:    output.Total,
+	}
+
+	return &result, nil
+}
+
+// VerifyChain walks the whole audit log in id order and recomputes every hash.
+// It stops at the first entry that does not match its stored hash or its predecessor.
+func (a AuditService) VerifyChain(ctx context.Context) (*AuditChainVerificationResult, error) {
+
+	ctx, span := tracer.Start(ctx, "AuditService.VerifyChain")
+	defer span.End()
+
+	result := &AuditChainVerificationResult{
+		IsValid: true,
+	}
+
+	previousHash := repository.GenesisAuditLogHash
+	var lastId int64
+
+	for {
+
+		auditLogs, err := a.auditLogRepository.GetAuditLogsAfterId(ctx, repository.GetAuditLogsAfterIdInput{
+			AfterId: lastId,
+			Limit:   consts.AuditLogVerifyBatchSize,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(auditLogs) == 0 {
+			return result, nil
+		}
+
+		for _, auditLog := range auditLogs {
+
+			input := repository.AppendAuditLogInput{
+				ActorUserId:   auditLog.ActorUserId,
+				SubjectUserId: auditLog.SubjectUserId,
+				Action:        auditLog.Action,
+				Changes:       auditLog.Changes,
+				RequestId:     auditLog.RequestId,
+				IpAddress:     auditLog.IpAddress,
+				CreatedAt:     auditLog.CreatedAt,
+			}
+
+			if auditLog.PreviousHash != previousHash || auditLog.Hash != repository.ComputeAuditLogHash(previousHash, input) {
+
+				brokenAtId := auditLog.Id
+
+				result.IsValid = false
+				result.BrokenAtId = &brokenAtId
+
+				return result, nil
+			}
+
+			result.CheckedCount += 1
+			previousHash = auditLog.Hash
+			lastId = auditLog.Id
+		}
+	}
+}
+
+// buildAuditLog puts the personal data of the entry back in its changes, unless anonymizing the user deleted it.
+func (a AuditService) buildAuditLog(output repository.AuditLogOutput) (pojos.AuditLog, error) {
+
+	changes := map[string]pojos.AuditChange{}
+
+	err := json.Unmarshal([]byte(output.Changes), &changes)
+
+	if err != nil {
+		return pojos.AuditLog{}, err
+	}
+
+	if output.PersonalChanges != "" {
+
+		personalChanges := map[string]pojos.AuditChange{}
+
+		err = json.Unmarshal([]byte(output.PersonalChanges), &personalChanges)
+
+		if err != nil {
+			return pojos.AuditLog{}, err
+		}
+
+		for field, change := range personalChanges {
+			changes[field] = change
+		}
+	}
+
+	// The entries appended before the personal data was stored apart keep their ip address in the chain.
+	ipAddress := output.IpAddress
+
+	if ipAddress == "" {
+		ipAddress = output.PersonalIpAddress
+	}
+
+	return pojos.AuditLog{
+		Id:            output.Id,
+		ActorUserId:   output.ActorUserId,
+		SubjectUserId: output.SubjectUserId,
+		Action:        output.Action,
+		Changes:       changes,
+		RequestId:     output.RequestId,
+		IpAddress:     ipAddress,
+		PreviousHash:  output.PreviousHash,
+		Hash:          output.Hash,
+		CreatedAt:     output.CreatedAt,
+	}, nil
+}
+
+// isPersonalAuditField reports whether the values of field are personal data, see Record.
+func isPersonalAuditField(field string) bool {
+
+	switch field {
+	case "phone_number", "full_name", "email":
+		return true
+	}
+
+	return false
+}
+
+// buildUserChanges returns the audited user fields that differ between before and after.
+func buildUserChanges(before pojos.User, after pojos.User) map[string]pojos.AuditChange {
+
+	changes := map[string]pojos.AuditChange{}
+
+	addChange := func(field string, beforeValue string, afterValue string) {
+		if beforeValue != afterValue {
+			changes[field] = pojos.AuditChange{Before: beforeValue, After: afterValue}
+		}
+	}
+
+	addChange("phone_number", before.PhoneNumber, after.PhoneNumber)
+	addChange("full_name", before.FullName, after.FullName)
+	addChange("email", before.Email, after.Email)
+	addChange("role", before.Role, after.Role)
+	addChange("status", before.Status, after.Status)
+	addChange("status_reason", before.StatusReason, after.StatusReason)
+
+	return changes
+}
+
+func NewAuditService(auditLogRepository repository.AuditLogRepositoryInterface) AuditServiceInterface {
+	return AuditService{
+		auditLogRepository: auditLogRepository,
+	}
+}
@@ -0,0 +1,454 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/forms"
+	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type AuditServiceTestSuite struct {
+	suite.Suite
+
+	auditLogRepository *repository.MockAuditLogRepositoryInterface
+
+	MockController *gomock.Controller
+}
+
+func TestAuditServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditServiceTestSuite))
+}
+
+func (ts *AuditServiceTestSuite) SetupSuite() {
+
+	mockCtrl := gomock.NewController(ts.T())
+
+	ts.MockController = mockCtrl
+
+	defer mockCtrl.Finish()
+
+	ts.auditLogRepository = repository.NewMockAuditLogRepositoryInterface(mockCtrl)
+}
+
+func (ts *AuditServiceTestSuite) TestNewAuditService() {
+	want := AuditService{
+		auditLogRepository: ts.auditLogRepository,
+	}
+
+	if got := NewAuditService(ts.auditLogRepository); !reflect.DeepEqual(got, want) {
+		ts.T().Errorf("NewAuditService() = %v, want %v", got, want)
+	}
+}
+
+func (ts *AuditServiceTestSuite) TestAuditService_Record() {
+
+	userId := int64(123)
+
+	tests := []struct {
+		name    string
+		entry   AuditEntry
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the entry changes personal fields, then it appends their values and the ip address outside the chained changes",
+			entry: AuditEntry{
+				ActorUserId:   &userId,
+				SubjectUserId: &userId,
+				Action:        consts.AuditActionProfileUpdate,
+				Changes: map[string]pojos.AuditChange{
+					"full_name":    {Before: "Rizqy", After: "Rizqy Faishal"},
+					"phone_number": {Before: "+628111", After: "+628222"},
+					"role":         {Before: consts.RoleUser, After: consts.RoleAdmin},
+				},
+				Metadata: RequestMetadata{
+					IpAddress: "103.10.10.1",
+					RequestId: "request-id",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, input repository.AppendAuditLogInput) (*repository.AppendAuditLogOutput, error) {
+
+						wantChanges := `{"full_name":{"before":"[REDACTED]","after":"[REDACTED]"},"phone_number":{"before":"[REDACTED]","after":"[REDACTED]"},"role":{"before":"user","after":"admin"}}`
+
+						if input.Changes != wantChanges {
+							ts.T().Errorf("AppendAuditLog() changes = %v, want %v", input.Changes, wantChanges)
+						}
+
+						wantPersonalChanges := `{"full_name":{"before":"Rizqy","after":"Rizqy Faishal"},"phone_number":{"before":"+628111","after":"+628222"}}`
+
+						if input.PersonalChanges != wantPersonalChanges {
+							ts.T().Errorf("AppendAuditLog() personal changes = %v, want %v", input.PersonalChanges, wantPersonalChanges)
+						}
+
+						if input.RequestId != "request-id" || input.IpAddress != "" || input.PersonalIpAddress != "103.10.10.1" || input.CreatedAt.IsZero() {
+							ts.T().Errorf("AppendAuditLog() input = %v", input)
+						}
+
+						return &repository.AppendAuditLogOutput{Id: 1}, nil
+					})
+			},
+		},
+
+		{
+			name: "When the entry has no changes, then it appends an empty JSON object",
+			entry: AuditEntry{
+				Action: consts.AuditActionLoginFailure,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, input repository.AppendAuditLogInput) (*repository.AppendAuditLogOutput, error) {
+
+						if input.Changes != "{}" || input.PersonalChanges != "{}" {
+							ts.T().Errorf("AppendAuditLog() changes = %v, personal changes = %v, want {}", input.Changes, input.PersonalChanges)
+						}
+
+						return &repository.AppendAuditLogOutput{Id: 1}, nil
+					})
+			},
+		},
+
+		{
+			name: "When the repository return error, then it return error",
+			entry: AuditEntry{
+				Action: consts.AuditActionLoginFailure,
+			},
+			wantErr: true,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			a := AuditService{}
+			if err := a.Record(context.Background(), ts.auditLogRepository, tt.entry); (err != nil) != tt.wantErr {
+				t.Errorf("Record() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (ts *AuditServiceTestSuite) TestAuditService_GetAuditLogs() {
+
+	actorUserId := int64(1)
+	subjectUserId := int64(123)
+	createdAt := time.Date(2024, 4, 18, 9, 50, 16, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		form    forms.AuditLogQueryForm
+		want    *AuditLogsResult
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the page size is above the maximum, then it return validation errors",
+			form: forms.AuditLogQueryForm{
+				Page:     1,
+				PageSize: 101,
+			},
+			want: &AuditLogsResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"page_size": "Page size must be at most 100",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+			},
+		},
+
+		{
+			name: "When the form is valid, then it return the page of audit logs with decoded changes",
+			form: forms.AuditLogQueryForm{
+				SubjectUserId: &subjectUserId,
+				Action:        consts.AuditActionRoleChange,
+				Page:          2,
+				PageSize:      10,
+			},
+			want: &AuditLogsResult{
+				AuditLogs: pojos.AuditLogs{
+					Data: []pojos.AuditLog{
+						{
+							Id:            11,
+							ActorUserId:   &actorUserId,
+							SubjectUserId: &subjectUserId,
+							Action:        consts.AuditActionRoleChange,
+							Changes: map[string]pojos.AuditChange{
+								"role": {Before: consts.RoleUser, After: consts.RoleAdmin},
+							},
+							PreviousHash: "previous hash",
+							Hash:         "hash",
+							CreatedAt:    createdAt,
+						},
+					},
+					Page:     2,
+					PageSize: 10,
+					Total:    11,
+				},
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().GetAuditLogs(gomock.Any(), repository.GetAuditLogsInput{
+					SubjectUserId: &subjectUserId,
+					Action:        consts.AuditActionRoleChange,
+					Limit:         10,
+					Offset:        10,
+				}).Return(&repository.GetAuditLogsOutput{
+					AuditLogs: []repository.AuditLogOutput{
+						{
+							Id:            11,
+							ActorUserId:   &actorUserId,
+							SubjectUserId: &subjectUserId,
+							Action:        consts.AuditActionRoleChange,
+							Changes:       `{"role":{"before":"user","after":"admin"}}`,
+							PreviousHash:  "previous hash",
+							Hash:          "hash",
+							CreatedAt:     createdAt,
+						},
+					},
+					Total: 11,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When an entry has personal data, then its values replace the redacted ones until the user is anonymized",
+			form: forms.AuditLogQueryForm{
+				SubjectUserId: &subjectUserId,
+				Page:          1,
+				PageSize:      20,
+			},
+			want: &AuditLogsResult{
+				AuditLogs: pojos.AuditLogs{
+					Data: []pojos.AuditLog{
+						{
+							Id:            2,
+							ActorUserId:   &subjectUserId,
+							SubjectUserId: &subjectUserId,
+							Action:        consts.AuditActionProfileUpdate,
+							Changes: map[string]pojos.AuditChange{
+								"full_name": {Before: "Rizqy", After: "Rizqy Faishal"},
+							},
+							IpAddress:    "103.10.10.1",
+							PreviousHash: "previous hash",
+							Hash:         "hash",
+							CreatedAt:    createdAt,
+						},
+						{
+							Id:            1,
+							ActorUserId:   &subjectUserId,
+							SubjectUserId: &subjectUserId,
+							Action:        consts.AuditActionProfileUpdate,
+							Changes: map[string]pojos.AuditChange{
+								"full_name": {Before: consts.AuditRedactedValue, After: consts.AuditRedactedValue},
+							},
+							PreviousHash: repository.GenesisAuditLogHash,
+							Hash:         "previous hash",
+							CreatedAt:    createdAt,
+						},
+					},
+					Page:     1,
+					PageSize: 20,
+					Total:    2,
+				},
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().GetAuditLogs(gomock.Any(), repository.GetAuditLogsInput{
+					SubjectUserId: &subjectUserId,
+					Limit:         20,
+					Offset:        0,
+				}).Return(&repository.GetAuditLogsOutput{
+					AuditLogs: []repository.AuditLogOutput{
+						{
+							Id:                2,
+							ActorUserId:       &subjectUserId,
+							SubjectUserId:     &subjectUserId,
+							Action:            consts.AuditActionProfileUpdate,
+							Changes:           `{"full_name":{"before":"[REDACTED]","after":"[REDACTED]"}}`,
+							PersonalChanges:   `{"full_name":{"before":"Rizqy","after":"Rizqy Faishal"}}`,
+							PersonalIpAddress: "103.10.10.1",
+							PreviousHash:      "previous hash",
+							Hash:              "hash",
+							CreatedAt:         createdAt,
+						},
+						{
+							Id:            1,
+							ActorUserId:   &subjectUserId,
+							SubjectUserId: &subjectUserId,
+							Action:        consts.AuditActionProfileUpdate,
+							Changes:       `{"full_name":{"before":"[REDACTED]","after":"[REDACTED]"}}`,
+							PreviousHash:  repository.GenesisAuditLogHash,
+							Hash:          "previous hash",
+							CreatedAt:     createdAt,
+						},
+					},
+					Total: 2,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the repository return error, then it return error",
+			form: forms.AuditLogQueryForm{
+				Page:     1,
+				PageSize: 20,
+			},
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().GetAuditLogs(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			a := AuditService{
+				auditLogRepository: ts.auditLogRepository,
+			}
+			got, err := a.GetAuditLogs(context.Background(), tt.form)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAuditLogs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAuditLogs() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *AuditServiceTestSuite) TestAuditService_VerifyChain() {
+
+	userId := int64(123)
+	createdAt := time.Date(2024, 4, 18, 9, 50, 16, 123456000, time.UTC)
+
+	buildAuditLog := func(id int64, previousHash string, action string) repository.AuditLogOutput {
+
+		input := repository.AppendAuditLogInput{
+			ActorUserId:   &userId,
+			SubjectUserId: &userId,
+			Action:        action,
+			Changes:       "{}",
+			RequestId:     "request-id",
+			IpAddress:     "103.10.10.1",
+			CreatedAt:     createdAt,
+		}
+
+		return repository.AuditLogOutput{
+			Id:            id,
+			ActorUserId:   input.ActorUserId,
+			SubjectUserId: input.SubjectUserId,
+			Action:        input.Action,
+			Changes:       input.Changes,
+			RequestId:     input.RequestId,
+			IpAddress:     input.IpAddress,
+			PreviousHash:  previousHash,
+			Hash:          repository.ComputeAuditLogHash(previousHash, input),
+			CreatedAt:     input.CreatedAt,
+		}
+	}
+
+	first := buildAuditLog(1, repository.GenesisAuditLogHash, consts.AuditActionRegister)
+	second := buildAuditLog(2, first.Hash, consts.AuditActionLoginSuccess)
+
+	tampered := second
+	tampered.Action = consts.AuditActionLoginFailure
+
+	brokenAtId := int64(2)
+
+	tests := []struct {
+		name    string
+		want    *AuditChainVerificationResult
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When every entry matches its hash, then the chain is valid",
+			want: &AuditChainVerificationResult{
+				IsValid:      true,
+				CheckedCount: 2,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().GetAuditLogsAfterId(gomock.Any(), repository.GetAuditLogsAfterIdInput{
+					AfterId: 0,
+					Limit:   consts.AuditLogVerifyBatchSize,
+				}).Return([]repository.AuditLogOutput{first, second}, nil)
+				ts.auditLogRepository.EXPECT().GetAuditLogsAfterId(gomock.Any(), repository.GetAuditLogsAfterIdInput{
+					AfterId: 2,
+					Limit:   consts.AuditLogVerifyBatchSize,
+				}).Return([]repository.AuditLogOutput{}, nil)
+			},
+		},
+
+		{
+			name: "When an entry was modified after it was written, then the chain is broken at that entry",
+			want: &AuditChainVerificationResult{
+				IsValid:      false,
+				CheckedCount: 1,
+				BrokenAtId:   &brokenAtId,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().GetAuditLogsAfterId(gomock.Any(), gomock.Any()).Return([]repository.AuditLogOutput{first, tampered}, nil)
+			},
+		},
+
+		{
+			name: "When an entry was deleted, then the chain is broken at the entry after it",
+			want: &AuditChainVerificationResult{
+				IsValid:      false,
+				CheckedCount: 0,
+				BrokenAtId:   &brokenAtId,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().GetAuditLogsAfterId(gomock.Any(), gomock.Any()).Return([]repository.AuditLogOutput{second}, nil)
+			},
+		},
+
+		{
+			name:    "When the repository return error, then it return error",
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.auditLogRepository.EXPECT().GetAuditLogsAfterId(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			a := AuditService{
+				auditLogRepository: ts.auditLogRepository,
+			}
+			got, err := a.VerifyChain(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyChain() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VerifyChain() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,25 +3,35 @@ package services
 import (
 	"context"
 	"errors"
+	"github.com/SawitProRecruitment/UserService/consts"
 	"github.com/SawitProRecruitment/UserService/forms"
+	"github.com/SawitProRecruitment/UserService/metrics"
 	"github.com/SawitProRecruitment/UserService/modules"
+	"github.com/SawitProRecruitment/UserService/pojos"
 	"github.com/SawitProRecruitment/UserService/repository"
 	"github.com/SawitProRecruitment/UserService/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
-	"os"
+	"log/slog"
 	"time"
 )
 
 type AuthenticationService struct {
-	repository   repository.UserRepositoryInterface
-	passwordAuth modules.PasswordAuthInterface
-	jwtAuth      modules.JsonWebTokenUtilInterface
+	repository           repository.UserRepositoryInterface
+	loginEventRepository repository.LoginEventRepositoryInterface
+	passwordAuth         modules.PasswordAuthInterface
+	jwtAuth              modules.JsonWebTokenUtilInterface
+	auditService         AuditServiceInterface
+	// issuer is the iss claim of the tokens, the application name.
+	issuer string
+	// getLoginExpirationDuration is called for every token, the duration can be reloaded while the service runs.
+	getLoginExpirationDuration func() time.Duration
 }
 
-func (a AuthenticationService) Authenticate(form forms.UserLoginForm) (*AuthenticationResult, error) {
+func (a AuthenticationService) Authenticate(ctx context.Context, form forms.UserLoginForm, metadata RequestMetadata) (*AuthenticationResult, error) {
 
-	ctx := context.Background()
+	ctx, span := tracer.Start(ctx, "AuthenticationService.Authenticate")
+	defer span.End()
 
 	result := &AuthenticationResult{
 		ValidationErrors: nil,
@@ -43,75 +53,174 @@ func (a AuthenticationService) Authenticate(form forms.UserLoginForm) (*Authenti
 		result.HasValidationErrors = true
 		result.ValidationErrors = validationErrorMessages
 
+		metrics.LoginAttempts.WithLabelValues(metrics.ResultFailure, metrics.LoginFailureReasonValidationError).Inc()
+
 		return result, nil
 	}
 
-	getByPhoneNumberInput := repository.GetUserByPhoneNumberInput{
-		PhoneNumber: form.PhoneNumber,
+	// Read from the primary, a replica may not have the password changed or the user registered a moment ago.
+	user, err := a.getUserByIdentifier(repository.WithPrimary(ctx), a.repository, form)
+
+	if err != nil {
+		return nil, err
 	}
 
-	user, err := a.repository.GetByPhoneNumberIncludePassword(ctx, getByPhoneNumberInput)
+	// The password is compared before the transaction, which is only held for the updates the login makes.
+	isPasswordValid := false
+
+	if user != nil {
+		_, err = compareHashedPassword(ctx, a.passwordAuth, user.Password, form.Password)
+		isPasswordValid = err == nil
+	}
+
+	var attempt *loginAttempt
+
+	// Cancelling a pending deletion, counting the login and recording it happen in one transaction.
+	err = a.repository.WithinTransaction(ctx, repository.TransactionOptions{
+		IsolationLevel: repository.IsolationLevelReadCommitted,
+	}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+		result, attempt, err = a.authenticate(ctx, txRepository, form, user, isPasswordValid)
+
+		if err != nil {
+			return err
+		}
+
+		if attempt.isDeletionCancelled {
+
+			err = a.recordDeletionCancel(ctx, txRepository, *attempt.userId, metadata)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return a.recordLoginEvent(ctx, txRepository, attempt.userId, attempt.method, attempt.failureReason, metadata)
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	if utils.StringIsEmpty(attempt.failureReason) {
+		metrics.LoginAttempts.WithLabelValues(metrics.ResultSuccess, "").Inc()
+	} else {
+		metrics.LoginAttempts.WithLabelValues(metrics.ResultFailure, attempt.failureReason).Inc()
+	}
+
+	return result, nil
+}
+
+// loginAttempt is what authenticate decided, Authenticate records it in the same transaction.
+type loginAttempt struct {
+	userId              *int64
+	method              string
+	failureReason       string
+	isDeletionCancelled bool
+}
+
+// authenticate logs the user of a valid login form in when the password is valid, with the updates made through
+// userRepository. It does not change user, so it can run again when the transaction is retried.
+func (a AuthenticationService) authenticate(ctx context.Context, userRepository repository.UserRepositoryInterface,
+	form forms.UserLoginForm, user *pojos.UserWithPassword, isPasswordValid bool) (*AuthenticationResult, *loginAttempt, error) {
+
+	result := &AuthenticationResult{
+		ValidationErrors: nil,
+		IsSuccess:        false,
+	}
+
+	attempt := &loginAttempt{
+		method: consts.LoginMethodPhoneNumber,
+	}
+
+	if utils.StringIsEmpty(form.Email) == false {
+		attempt.method = consts.LoginMethodEmail
+	}
+
 	if user == nil {
+
+		attempt.failureReason = consts.LoginFailureReasonUserNotFound
+
 		result.IsSuccess = false
 		result.IsUserNotFound = true
 
-		return result, nil
+		return result, attempt, nil
 	}
 
-	_, err = a.passwordAuth.CompareHashedPassword(user.Password, form.Password)
+	if isPasswordValid == false {
 
-	if err != nil {
+		attempt.userId = &user.Id
+		attempt.failureReason = consts.LoginFailureReasonInvalidPassword
 
 		result.IsSuccess = false
 		result.IsUserNotFound = false
 
-		return result, nil
+		return result, attempt, nil
 	}
 
-	expiredDuration, err := time.ParseDuration(os.Getenv("LOGIN_EXPIRATION_DURATION"))
+	status := user.Status
 
-	if err != nil {
-		return nil, err
+	if status == consts.AccountStatusPendingDeletion {
+
+		var err error
+
+		attempt.isDeletionCancelled, err = a.cancelDeletion(ctx, userRepository, user.Id)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if attempt.isDeletionCancelled {
+			status = consts.AccountStatusActive
+		}
+	}
+
+	if status != consts.AccountStatusActive {
+		return a.refuseInactiveAccount(result, attempt, user.Id, status)
 	}
 
-	expiredTokenAt := time.Now().Add(expiredDuration)
+	expiredTokenAt := time.Now().Add(a.getLoginExpirationDuration())
 
 	jwtToken, err := a.jwtAuth.GenerateJwt(modules.CustomClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    os.Getenv("APPLICATION_NAME"),
+			Issuer:    a.issuer,
 			ExpiresAt: jwt.NewNumericDate(expiredTokenAt),
 		},
 		UserId: user.Id,
 	})
 
 	if err != nil {
-		return nil, err
-	}
-
-	user.LoginSuccessCount += 1
-
-	updateUserInput := repository.UpdateUserInput{
-		Id:                user.Id,
-		PhoneNumber:       user.PhoneNumber,
-		FullName:          user.FullName,
-		LoginSuccessCount: user.LoginSuccessCount,
+		return nil, nil, err
 	}
 
-	updateOutput, err := a.repository.Update(ctx, updateUserInput)
+	updateOutput, err := userRepository.IncrementLoginSuccessCount(ctx, repository.IncrementLoginSuccessCountInput{
+		Id: user.Id,
+	})
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	// The account was suspended, locked or deleted since it was read.
 	if updateOutput.IsSuccessUpdate == false {
-		return nil, err
+
+		currentUser, err := userRepository.GetById(ctx, repository.GetUserByIdInput{
+			Id: user.Id,
+		})
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if currentUser == nil {
+			return nil, nil, errors.New("User not found")
+		}
+
+		return a.refuseInactiveAccount(result, attempt, user.Id, currentUser.Status)
 	}
 
+	attempt.userId = &user.Id
+
 	result.IsSuccess = true
 	result.IsUserNotFound = false
 	result.HasValidationErrors = false
@@ -121,31 +230,259 @@ func (a AuthenticationService) Authenticate(form forms.UserLoginForm) (*Authenti
 		UserId: user.Id,
 	}
 
-	return result, nil
+	return result, attempt, nil
+}
+
+func (a AuthenticationService) refuseInactiveAccount(result *AuthenticationResult, attempt *loginAttempt, userId int64,
+	status string) (*AuthenticationResult, *loginAttempt, error) {
+
+	attempt.userId = &userId
+	attempt.failureReason = consts.LoginFailureReasonAccountInactive
+
+	result.IsSuccess = false
+	result.IsUserNotFound = false
+	result.IsAccountInactive = true
+	result.AccountStatus = status
+
+	return result, attempt, nil
 }
 
-func (a AuthenticationService) Authorize(tokenString string) (*AuthorizationResult, error) {
+// Authorize verifies the token and that its user still exists with an active account,
+// so a suspended, locked or deleted user cannot keep using a token issued before.
+func (a AuthenticationService) Authorize(ctx context.Context, tokenString string) (*AuthorizationResult, error) {
+
+	ctx, span := tracer.Start(ctx, "AuthenticationService.Authorize")
+	defer span.End()
 
 	claims, err := a.jwtAuth.VerifyJwt(tokenString)
 
+	// A bad token is the client's fault, the error is only returned when the user cannot be checked.
+	if err != nil {
+		slog.DebugContext(ctx, "invalid token", slog.Any("error", err))
+		return &AuthorizationResult{IsAuthorized: false, IsInvalidToken: true}, nil
+	}
+
+	user, err := a.repository.GetById(ctx, repository.GetUserByIdInput{
+		Id: claims.UserId,
+	})
+
 	if err != nil {
 		return nil, err
 	}
 
 	result := &AuthorizationResult{
-		IsAuthorized: true,
+		IsAuthorized: user != nil && user.Status == consts.AccountStatusActive,
 		UserId:       claims.UserId,
 	}
 
 	return result, nil
 }
 
-func NewAuthenticationService(repository repository.UserRepositoryInterface,
-	passwordAuth modules.PasswordAuthInterface, jwtAuth modules.JsonWebTokenUtilInterface) AuthenticationServiceInterface {
+func (a AuthenticationService) GetLoginHistory(ctx context.Context, userId int64, form forms.LoginHistoryForm) (*LoginHistoryResult, error) {
+
+	ctx, span := tracer.Start(ctx, "AuthenticationService.GetLoginHistory")
+	defer span.End()
+
+	result := LoginHistoryResult{}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err := validate.Struct(form)
+
+	if err != nil {
+
+		var validationErrors validator.ValidationErrors
+
+		errors.As(err, &validationErrors)
+
+		validationErrorMessages := utils.CollectValidationErrorMessages(form, validationErrors)
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = validationErrorMessages
+
+		return &result, nil
+	}
+
+	output, err := a.loginEventRepository.GetLoginEventsByUserId(ctx, repository.GetLoginEventsByUserIdInput{
+		UserId: userId,
+		Limit:  form.PageSize,
+		Offset: (form.Page - 1) * form.PageSize,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	loginEvents := make([]pojos.LoginEvent, 0, len(output.LoginEvents))
+
+	for _, loginEvent := range output.LoginEvents {
+		loginEvents = append(loginEvents, pojos.LoginEvent{
+			Id:            loginEvent.Id,
+			IsSuccess:     loginEvent.IsSuccess,
+			FailureReason: loginEvent.FailureReason,
+			Method:        loginEvent.Method,
+			IpAddress:     loginEvent.IpAddress,
+			UserAgent:     loginEvent.UserAgent,
+			CreatedAt:     loginEvent.CreatedAt,
+		})
+	}
+
+	result.HasValidationErrors = false
+	result.ValidationErrors = map[string]string{}
+	result.LoginHistory = pojos.LoginHistory{
+		Data:     loginEvents,
+		Page:     form.Page,
+		PageSize: form.PageSize,
+		Total:    output.Total,
+	}
+
+	return &result, nil
+}
+
+// recordLoginEvent stores the outcome of a login attempt in the login history and the audit log.
+// An empty failure reason means the login succeeded.
+func (a AuthenticationService) recordLoginEvent(ctx context.Context, txRepository repository.TransactionRepositoryInterface,
+	userId *int64, method string, failureReason string, metadata RequestMetadata) error {
+
+	isSuccess := utils.StringIsEmpty(failureReason)
+
+	_, err := txRepository.InsertLoginEvent(ctx, repository.InsertLoginEventInput{
+		UserId:        userId,
+		IsSuccess:     isSuccess,
+		FailureReason: failureReason,
+		Method:        method,
+		IpAddress:     metadata.IpAddress,
+		UserAgent:     metadata.UserAgent,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	auditAction := consts.AuditActionLoginSuccess
+
+	if isSuccess == false {
+		auditAction = consts.AuditActionLoginFailure
+	}
+
+	return a.auditService.Record(ctx, txRepository, AuditEntry{
+		ActorUserId:   userId,
+		SubjectUserId: userId,
+		Action:        auditAction,
+		Metadata:      metadata,
+	})
+}
+
+// cancelDeletion moves a user that is pending deletion back to active, logging in during the grace period cancels the deletion.
+// It reports whether the deletion was cancelled.
+func (a AuthenticationService) cancelDeletion(ctx context.Context, userRepository repository.UserRepositoryInterface,
+	userId int64) (bool, error) {
+
+	updateOutput, err := userRepository.CancelDeletion(ctx, repository.CancelUserDeletionInput{
+		Id: userId,
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	// Already anonymized or cancelled in the meantime, the status check after this refuses the login.
+	return updateOutput.IsSuccessUpdate, nil
+}
+
+func (a AuthenticationService) recordDeletionCancel(ctx context.Context, txRepository repository.TransactionRepositoryInterface,
+	userId int64, metadata RequestMetadata) error {
+
+	return a.auditService.Record(ctx, txRepository, AuditEntry{
+		ActorUserId:   &userId,
+		SubjectUserId: &userId,
+		Action:        consts.AuditActionDeletionCancel,
+		Changes: map[string]pojos.AuditChange{
+			"status": {Before: consts.AccountStatusPendingDeletion, After: consts.AccountStatusActive},
+		},
+		Metadata: metadata,
+	})
+}
+
+// getUserByIdentifier looks the user up by email when it is given, otherwise by phone number.
+// An email only identifies the user once it has been verified.
+func (a AuthenticationService) getUserByIdentifier(ctx context.Context, userRepository repository.UserRepositoryInterface,
+	form forms.UserLoginForm) (*pojos.UserWithPassword, error) {
+
+	if utils.StringIsEmpty(form.Email) == false {
+
+		output, err := userRepository.GetByEmailIncludePassword(ctx, repository.GetUserByEmailInput{
+			Email: form.Email,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if output == nil || output.EmailVerifiedAt == nil {
+			return nil, nil
+		}
+
+		return &pojos.UserWithPassword{
+			Id:                  output.Id,
+			PhoneNumber:         output.PhoneNumber,
+			FullName:            output.FullName,
+			Email:               output.Email,
+			EmailVerifiedAt:     output.EmailVerifiedAt,
+			Role:                output.Role,
+			Status:              output.Status,
+			StatusReason:        output.StatusReason,
+			StatusChangedAt:     output.StatusChangedAt,
+			DeletionScheduledAt: output.DeletionScheduledAt,
+			Password:            output.Password,
+			LoginSuccessCount:   output.LoginSuccessCount,
+			CreatedAt:           output.CreatedAt,
+			UpdatedAt:           output.UpdatedAt,
+		}, nil
+	}
+
+	getByPhoneNumberInput := repository.GetUserByPhoneNumberInput{
+		PhoneNumber: form.PhoneNumber,
+	}
+
+	output, err := userRepository.GetByPhoneNumberIncludePassword(ctx, getByPhoneNumberInput)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if output == nil {
+		return nil, nil
+	}
+
+	return &pojos.UserWithPassword{
+		Id:                  output.Id,
+		PhoneNumber:         output.PhoneNumber,
+		FullName:            output.FullName,
+		Email:               output.Email,
+		EmailVerifiedAt:     output.EmailVerifiedAt,
+		Role:                output.Role,
+		Status:              output.Status,
+		StatusReason:        output.StatusReason,
+		StatusChangedAt:     output.StatusChangedAt,
+		DeletionScheduledAt: output.DeletionScheduledAt,
+		Password:            output.Password,
+		LoginSuccessCount:   output.LoginSuccessCount,
+		CreatedAt:           output.CreatedAt,
+		UpdatedAt:           output.UpdatedAt,
+	}, nil
+}
+
+func NewAuthenticationService(repository repository.UserRepositoryInterface, loginEventRepository repository.LoginEventRepositoryInterface,
+	passwordAuth modules.PasswordAuthInterface, jwtAuth modules.JsonWebTokenUtilInterface, auditService AuditServiceInterface,
+	issuer string, getLoginExpirationDuration func() time.Duration) AuthenticationServiceInterface {
 
 	return AuthenticationService{
-		repository:   repository,
-		passwordAuth: passwordAuth,
-		jwtAuth:      jwtAuth,
+		repository:                 repository,
+		loginEventRepository:       loginEventRepository,
+		passwordAuth:               passwordAuth,
+		jwtAuth:                    jwtAuth,
+		auditService:               auditService,
+		issuer:                     issuer,
+		getLoginExpirationDuration: getLoginExpirationDuration,
 	}
 }
@@ -1,14 +1,16 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"github.com/SawitProRecruitment/UserService/consts"
 	"github.com/SawitProRecruitment/UserService/forms"
 	"github.com/SawitProRecruitment/UserService/modules"
+	"github.com/SawitProRecruitment/UserService/pojos"
 	"github.com/SawitProRecruitment/UserService/repository"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
-	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -17,9 +19,11 @@ import (
 type AuthenticationServiceTestSuite struct {
 	suite.Suite
 
-	repository   *repository.MockUserRepositoryInterface
-	passwordAuth *modules.MockPasswordAuthInterface
-	jwtAuth      *modules.MockJsonWebTokenUtilInterface
+	repository           *repository.MockTransactionRepositoryInterface
+	loginEventRepository *repository.MockLoginEventRepositoryInterface
+	passwordAuth         *modules.MockPasswordAuthInterface
+	jwtAuth              *modules.MockJsonWebTokenUtilInterface
+	auditService         *MockAuditServiceInterface
 
 	MockController *gomock.Controller
 }
@@ -36,22 +40,27 @@ func (ts *AuthenticationServiceTestSuite) SetupSuite() {
 
 	defer mockCtrl.Finish()
 
-	ts.repository = repository.NewMockUserRepositoryInterface(mockCtrl)
+	ts.repository = repository.NewMockTransactionRepositoryInterface(mockCtrl)
+	ts.repository.EXPECT().WithinTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(repository.RunTransactionDirectly(ts.repository)).AnyTimes()
+	ts.loginEventRepository = repository.NewMockLoginEventRepositoryInterface(mockCtrl)
 	ts.passwordAuth = modules.NewMockPasswordAuthInterface(mockCtrl)
 	ts.jwtAuth = modules.NewMockJsonWebTokenUtilInterface(mockCtrl)
+	ts.auditService = NewMockAuditServiceInterface(mockCtrl)
 
 }
 
 func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate() {
-	os.Setenv("LOGIN_EXPIRATION_DURATION", "24h")
-
 	type fields struct {
-		repository   repository.UserRepositoryInterface
-		passwordAuth modules.PasswordAuthInterface
-		jwtAuth      modules.JsonWebTokenUtilInterface
+		repository           repository.UserRepositoryInterface
+		loginEventRepository repository.LoginEventRepositoryInterface
+		passwordAuth         modules.PasswordAuthInterface
+		jwtAuth              modules.JsonWebTokenUtilInterface
+		auditService         AuditServiceInterface
 	}
 	type args struct {
-		form forms.UserLoginForm
+		form     forms.UserLoginForm
+		metadata RequestMetadata
 	}
 	tests := []struct {
 		name    string
@@ -64,9 +73,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 		{
 			name: "When the form is invalid, the phone number and password is not found, then return validation errors",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{},
@@ -77,7 +88,7 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 				HasValidationErrors: true,
 				ValidationErrors: map[string]string{
 					"password":     "Password is required",
-					"phone_number": "Phone number is required",
+					"phone_number": "Phone number is required when email is empty",
 				},
 				Credential: nil,
 			},
@@ -90,9 +101,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 		{
 			name: "When the form is invalid, the password is empty, then return validation errors",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{
@@ -117,9 +130,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 		{
 			name: "When the form is invalid, the phone number is empty, then return validation errors",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{
@@ -131,7 +146,7 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 				IsUserNotFound:      false,
 				HasValidationErrors: true,
 				ValidationErrors: map[string]string{
-					"phone_number": "Phone number is required",
+					"phone_number": "Phone number is required when email is empty",
 				},
 				Credential: nil,
 			},
@@ -144,9 +159,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 		{
 			name: "When the form is valid, the phone number and password are not empty, but the user is not found, then return validation errors",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{
@@ -160,16 +177,51 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 			},
 			mock: func() {
 				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(nil, nil)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), repository.InsertLoginEventInput{
+					UserId:        nil,
+					IsSuccess:     false,
+					FailureReason: consts.LoginFailureReasonUserNotFound,
+					Method:        consts.LoginMethodPhoneNumber,
+				}).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					Action: consts.AuditActionLoginFailure,
+				}).Return(nil)
 			},
 			wantErr: false,
 		},
 
+		{
+			name: "When the user is not found and the audit service return error, then return errors",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				form: forms.UserLoginForm{
+					Password:    "asdasd123",
+					PhoneNumber: "+628329328932",
+				},
+			},
+			want: nil,
+			mock: func() {
+				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(nil, nil)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), gomock.Any()).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("unexpected error"))
+			},
+			wantErr: true,
+		},
+
 		{
 			name: "When the form is valid, the phone number and password are not empty, but the repository return error, then return errors",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{
@@ -187,9 +239,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 		{
 			name: "When the form is valid, the phone number and password are not empty, but the password is invalid by the bcrypt, then return is not authenticated",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{
@@ -214,6 +268,167 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 				}, nil)
 
 				ts.passwordAuth.EXPECT().CompareHashedPassword(gomock.Any(), gomock.Any()).Return(false, errors.New("password not match"))
+				userId := int64(123)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), repository.InsertLoginEventInput{
+					UserId:        &userId,
+					IsSuccess:     false,
+					FailureReason: consts.LoginFailureReasonInvalidPassword,
+					Method:        consts.LoginMethodPhoneNumber,
+				}).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
+
+		{
+			name: "When the password is valid, but the account is suspended, then return account inactive",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				form: forms.UserLoginForm{
+					Password:    "asdasd123",
+					PhoneNumber: "+628329328932",
+				},
+			},
+			want: &AuthenticationResult{
+				IsSuccess:         false,
+				IsUserNotFound:    false,
+				IsAccountInactive: true,
+				AccountStatus:     consts.AccountStatusSuspended,
+			},
+			mock: func() {
+				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByPhoneNumberOutput{
+					Id:          123,
+					PhoneNumber: "+628329328932",
+					Password:    "asdasd123",
+					Status:      consts.AccountStatusSuspended,
+				}, nil)
+				ts.passwordAuth.EXPECT().CompareHashedPassword(gomock.Any(), gomock.Any()).Return(true, nil)
+				userId := int64(123)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), repository.InsertLoginEventInput{
+					UserId:        &userId,
+					IsSuccess:     false,
+					FailureReason: consts.LoginFailureReasonAccountInactive,
+					Method:        consts.LoginMethodPhoneNumber,
+				}).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
+
+		{
+			name: "When the account is suspended after it was read, then refuse the login without counting it",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				form: forms.UserLoginForm{
+					Password:    "asdasd123",
+					PhoneNumber: "+628329328932",
+				},
+			},
+			want: &AuthenticationResult{
+				IsSuccess:         false,
+				IsUserNotFound:    false,
+				IsAccountInactive: true,
+				AccountStatus:     consts.AccountStatusSuspended,
+			},
+			mock: func() {
+				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByPhoneNumberOutput{
+					Id:          123,
+					PhoneNumber: "+628329328932",
+					Password:    "asdasd123",
+					Status:      consts.AccountStatusActive,
+				}, nil)
+				ts.passwordAuth.EXPECT().CompareHashedPassword(gomock.Any(), gomock.Any()).Return(true, nil)
+				token := "jwt token"
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.repository.EXPECT().IncrementLoginSuccessCount(gomock.Any(), gomock.Any()).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: false,
+				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 123}).Return(&repository.GetUserByIdOutput{
+					Id:     123,
+					Status: consts.AccountStatusSuspended,
+				}, nil)
+				userId := int64(123)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), repository.InsertLoginEventInput{
+					UserId:        &userId,
+					IsSuccess:     false,
+					FailureReason: consts.LoginFailureReasonAccountInactive,
+					Method:        consts.LoginMethodPhoneNumber,
+				}).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					ActorUserId:   &userId,
+					SubjectUserId: &userId,
+					Action:        consts.AuditActionLoginFailure,
+				}).Return(nil)
+			},
+			wantErr: false,
+		},
+
+		{
+			name: "When the password is valid and the account is pending deletion, then cancel the deletion and login",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				form: forms.UserLoginForm{
+					Password:    "asdasd123",
+					PhoneNumber: "+628329328932",
+				},
+			},
+			want: &AuthenticationResult{
+				IsSuccess:           true,
+				IsUserNotFound:      false,
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+				Credential: &AuthenticationCredential{
+					Token:  "jwt token",
+					UserId: 123,
+				},
+			},
+			mock: func() {
+				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByPhoneNumberOutput{
+					Id:          123,
+					PhoneNumber: "+628329328932",
+					Password:    "asdasd123",
+					Status:      consts.AccountStatusPendingDeletion,
+				}, nil)
+				ts.passwordAuth.EXPECT().CompareHashedPassword(gomock.Any(), gomock.Any()).Return(true, nil)
+				ts.repository.EXPECT().CancelDeletion(gomock.Any(), repository.CancelUserDeletionInput{
+					Id: 123,
+				}).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: true,
+				}, nil)
+				userId := int64(123)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					ActorUserId:   &userId,
+					SubjectUserId: &userId,
+					Action:        consts.AuditActionDeletionCancel,
+					Changes: map[string]pojos.AuditChange{
+						"status": {Before: consts.AccountStatusPendingDeletion, After: consts.AccountStatusActive},
+					},
+				}).Return(nil)
+				token := "jwt token"
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.repository.EXPECT().IncrementLoginSuccessCount(gomock.Any(), gomock.Any()).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: true,
+				}, nil)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), gomock.Any()).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -221,9 +436,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 		{
 			name: "When the form is valid, the phone number and password are not empty, but the password is valid, but jwt generator is failed, then return errors",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{
@@ -239,6 +456,7 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 					FullName:          "Rizqy Faishal",
 					LoginSuccessCount: 0,
 					Password:          "asdasd123",
+					Status:            consts.AccountStatusActive,
 					CreatedAt:         time.Time{},
 					UpdatedAt:         time.Time{},
 				}, nil)
@@ -253,9 +471,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 		{
 			name: "When the form is valid, the phone number and password are not empty, but the password is valid, but has repo error, then return errors",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{
@@ -271,6 +491,7 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 					FullName:          "Rizqy Faishal",
 					LoginSuccessCount: 0,
 					Password:          "asdasd123",
+					Status:            consts.AccountStatusActive,
 					CreatedAt:         time.Time{},
 					UpdatedAt:         time.Time{},
 				}, nil)
@@ -278,7 +499,7 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 				ts.passwordAuth.EXPECT().CompareHashedPassword(gomock.Any(), gomock.Any()).Return(true, nil)
 				token := "jwt token"
 				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
-				ts.repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, errors.New("Error at update"))
+				ts.repository.EXPECT().IncrementLoginSuccessCount(gomock.Any(), gomock.Any()).Return(nil, errors.New("Error at update"))
 			},
 			wantErr: true,
 		},
@@ -286,15 +507,21 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 		{
 			name: "Positive case, When the form is valid, the phone number and password are not empty, but the password is valid, return success authentication result",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				form: forms.UserLoginForm{
 					Password:    "asdasd123",
 					PhoneNumber: "+628329328932",
 				},
+				metadata: RequestMetadata{
+					IpAddress: "103.10.10.1",
+					UserAgent: "Mozilla/5.0",
+				},
 			},
 			want: &AuthenticationResult{
 				IsSuccess:           true,
@@ -313,6 +540,7 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 					FullName:          "Rizqy Faishal",
 					LoginSuccessCount: 0,
 					Password:          "asdasd123",
+					Status:            consts.AccountStatusActive,
 					CreatedAt:         time.Time{},
 					UpdatedAt:         time.Time{},
 				}, nil)
@@ -320,22 +548,182 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 				ts.passwordAuth.EXPECT().CompareHashedPassword(gomock.Any(), gomock.Any()).Return(true, nil)
 				token := "jwt token"
 				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
-				ts.repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&repository.UpdateUserOutput{
+				ts.repository.EXPECT().IncrementLoginSuccessCount(gomock.Any(), gomock.Any()).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: true,
+				}, nil)
+				userId := int64(123)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), repository.InsertLoginEventInput{
+					UserId:    &userId,
+					IsSuccess: true,
+					Method:    consts.LoginMethodPhoneNumber,
+					IpAddress: "103.10.10.1",
+					UserAgent: "Mozilla/5.0",
+				}).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "When the form is valid with email, but the email is not verified, then return user not found",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				form: forms.UserLoginForm{
+					Password: "asdasd123",
+					Email:    "rizqy@example.com",
+				},
+			},
+			want: &AuthenticationResult{
+				IsSuccess:      false,
+				IsUserNotFound: true,
+			},
+			mock: func() {
+				ts.repository.EXPECT().GetByEmailIncludePassword(gomock.Any(), repository.GetUserByEmailInput{
+					Email: "rizqy@example.com",
+				}).Return(&repository.GetUserByEmailOutput{
+					Id:       123,
+					Email:    "rizqy@example.com",
+					Password: "asdasd123",
+				}, nil)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), repository.InsertLoginEventInput{
+					UserId:        nil,
+					IsSuccess:     false,
+					FailureReason: consts.LoginFailureReasonUserNotFound,
+					Method:        consts.LoginMethodEmail,
+				}).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "When the form is invalid, the email is not a valid email, then return validation errors",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				form: forms.UserLoginForm{
+					Password: "asdasd123",
+					Email:    "rizqy",
+				},
+			},
+			want: &AuthenticationResult{
+				IsSuccess:           false,
+				IsUserNotFound:      false,
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"email": "Email must be a valid email address",
+				},
+				Credential: nil,
+			},
+			mock: func() {
+			},
+			wantErr: false,
+		},
+		{
+			name: "Positive case, When the form is valid with verified email and the password is valid, return success authentication result",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				form: forms.UserLoginForm{
+					Password: "asdasd123",
+					Email:    "rizqy@example.com",
+				},
+			},
+			want: &AuthenticationResult{
+				IsSuccess:           true,
+				IsUserNotFound:      false,
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+				Credential: &AuthenticationCredential{
+					Token:  "jwt token",
+					UserId: 123,
+				},
+			},
+			mock: func() {
+				verifiedAt := time.Now()
+				ts.repository.EXPECT().GetByEmailIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByEmailOutput{
+					Id:              123,
+					PhoneNumber:     "+628329328932",
+					FullName:        "Rizqy Faishal",
+					Email:           "rizqy@example.com",
+					EmailVerifiedAt: &verifiedAt,
+					Password:        "asdasd123",
+					Status:          consts.AccountStatusActive,
+				}, nil)
+				ts.passwordAuth.EXPECT().CompareHashedPassword(gomock.Any(), gomock.Any()).Return(true, nil)
+				token := "jwt token"
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.repository.EXPECT().IncrementLoginSuccessCount(gomock.Any(), gomock.Any()).Return(&repository.UpdateUserOutput{
 					IsSuccessUpdate: true,
 				}, nil)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), gomock.Any()).Return(&repository.InsertLoginEventOutput{Id: 1}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 			wantErr: false,
 		},
+		{
+			name: "When the password is valid, but the login event is failed to be recorded, then return errors",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				form: forms.UserLoginForm{
+					Password:    "asdasd123",
+					PhoneNumber: "+628329328932",
+				},
+			},
+			want: nil,
+			mock: func() {
+				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByPhoneNumberOutput{
+					Id:          123,
+					PhoneNumber: "+628329328932",
+					Password:    "asdasd123",
+					Status:      consts.AccountStatusActive,
+				}, nil)
+				ts.passwordAuth.EXPECT().CompareHashedPassword(gomock.Any(), gomock.Any()).Return(true, nil)
+				token := "jwt token"
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.repository.EXPECT().IncrementLoginSuccessCount(gomock.Any(), gomock.Any()).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: true,
+				}, nil)
+				ts.repository.EXPECT().InsertLoginEvent(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		ts.T().Run(tt.name, func(t *testing.T) {
 			tt.mock()
 			a := AuthenticationService{
-				repository:   tt.fields.repository,
-				passwordAuth: tt.fields.passwordAuth,
-				jwtAuth:      tt.fields.jwtAuth,
+				repository:           tt.fields.repository,
+				loginEventRepository: tt.fields.loginEventRepository,
+				passwordAuth:         tt.fields.passwordAuth,
+				jwtAuth:              tt.fields.jwtAuth,
+				auditService:         tt.fields.auditService,
+				issuer:               "simple-user-service",
+				getLoginExpirationDuration: func() time.Duration {
+					return 24 * time.Hour
+				},
 			}
-			got, err := a.Authenticate(tt.args.form)
+			got, err := a.Authenticate(context.Background(), tt.args.form, tt.args.metadata)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -349,9 +737,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authenticate
 
 func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authorize() {
 	type fields struct {
-		repository   repository.UserRepositoryInterface
-		passwordAuth modules.PasswordAuthInterface
-		jwtAuth      modules.JsonWebTokenUtilInterface
+		repository           repository.UserRepositoryInterface
+		loginEventRepository repository.LoginEventRepositoryInterface
+		passwordAuth         modules.PasswordAuthInterface
+		jwtAuth              modules.JsonWebTokenUtilInterface
+		auditService         AuditServiceInterface
 	}
 	type args struct {
 		tokenString string
@@ -365,17 +755,22 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authorize()
 		wantErr bool
 	}{
 		{
-			name: "When the token is invalid and should be unauthorized, then return error",
+			name: "When the token is invalid and should be unauthorized, then return invalid token result",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				tokenString: "a json web token",
 			},
-			want:    nil,
-			wantErr: true,
+			want: &AuthorizationResult{
+				IsAuthorized:   false,
+				IsInvalidToken: true,
+			},
+			wantErr: false,
 			mock: func() {
 				ts.jwtAuth.EXPECT().VerifyJwt(gomock.Any()).Return(nil, errors.New("invalid token"))
 			},
@@ -384,9 +779,11 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authorize()
 		{
 			name: "When the token is valid and should be authorized, then return authorize result",
 			fields: fields{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
 			},
 			args: args{
 				tokenString: "a json web token",
@@ -401,6 +798,88 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authorize()
 					RegisteredClaims: jwt.RegisteredClaims{},
 					UserId:           123,
 				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 123}).Return(&repository.GetUserByIdOutput{
+					Id:     123,
+					Status: consts.AccountStatusActive,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the token is valid but the user is suspended, then return unauthorized result",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				tokenString: "a json web token",
+			},
+			want: &AuthorizationResult{
+				IsAuthorized: false,
+				UserId:       123,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.jwtAuth.EXPECT().VerifyJwt(gomock.Any()).Return(&modules.CustomClaims{
+					RegisteredClaims: jwt.RegisteredClaims{},
+					UserId:           123,
+				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 123}).Return(&repository.GetUserByIdOutput{
+					Id:     123,
+					Status: consts.AccountStatusSuspended,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the token is valid but the user no longer exists, then return unauthorized result",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				tokenString: "a json web token",
+			},
+			want: &AuthorizationResult{
+				IsAuthorized: false,
+				UserId:       123,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.jwtAuth.EXPECT().VerifyJwt(gomock.Any()).Return(&modules.CustomClaims{
+					RegisteredClaims: jwt.RegisteredClaims{},
+					UserId:           123,
+				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 123}).Return(nil, nil)
+			},
+		},
+
+		{
+			name: "When the token is valid but the user cannot be read, then return error",
+			fields: fields{
+				repository:           ts.repository,
+				loginEventRepository: ts.loginEventRepository,
+				passwordAuth:         ts.passwordAuth,
+				jwtAuth:              ts.jwtAuth,
+				auditService:         ts.auditService,
+			},
+			args: args{
+				tokenString: "a json web token",
+			},
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.jwtAuth.EXPECT().VerifyJwt(gomock.Any()).Return(&modules.CustomClaims{
+					RegisteredClaims: jwt.RegisteredClaims{},
+					UserId:           123,
+				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 123}).Return(nil, errors.New("connection reset"))
 			},
 		},
 	}
@@ -409,11 +888,13 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authorize()
 			tt.mock()
 
 			a := AuthenticationService{
-				repository:   tt.fields.repository,
-				passwordAuth: tt.fields.passwordAuth,
-				jwtAuth:      tt.fields.jwtAuth,
+				repository:           tt.fields.repository,
+				loginEventRepository: tt.fields.loginEventRepository,
+				passwordAuth:         tt.fields.passwordAuth,
+				jwtAuth:              tt.fields.jwtAuth,
+				auditService:         tt.fields.auditService,
 			}
-			got, err := a.Authorize(tt.args.tokenString)
+			got, err := a.Authorize(context.Background(), tt.args.tokenString)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -425,36 +906,177 @@ func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_Authorize()
 	}
 }
 
-func (ts *AuthenticationServiceTestSuite) TestNewAuthenticationService() {
+func (ts *AuthenticationServiceTestSuite) TestAuthenticationService_GetLoginHistory() {
+	type fields struct {
+		repository           repository.UserRepositoryInterface
+		loginEventRepository repository.LoginEventRepositoryInterface
+		passwordAuth         modules.PasswordAuthInterface
+		jwtAuth              modules.JsonWebTokenUtilInterface
+		auditService         AuditServiceInterface
+	}
 	type args struct {
-		repository   repository.UserRepositoryInterface
-		passwordAuth modules.PasswordAuthInterface
-		jwtAuth      modules.JsonWebTokenUtilInterface
+		userId int64
+		form   forms.LoginHistoryForm
 	}
+	createdAt := time.Date(2024, 4, 18, 16, 50, 16, 0, time.UTC)
+	userId := int64(123)
 	tests := []struct {
-		name string
-		args args
-		want AuthenticationServiceInterface
+		name    string
+		fields  fields
+		args    args
+		want    *LoginHistoryResult
+		mock    func()
+		wantErr bool
 	}{
 		{
-			name: "When given valid dependencies module, it will return authentication service",
+			name: "When the page size is more than allowed, then return validation errors",
+			fields: fields{
+				loginEventRepository: ts.loginEventRepository,
+			},
+			args: args{
+				userId: 123,
+				form: forms.LoginHistoryForm{
+					Page:     0,
+					PageSize: 101,
+				},
+			},
+			want: &LoginHistoryResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"page":      "Page must be at least 1",
+					"page_size": "Page size must be at most 100",
+				},
+			},
+			mock: func() {
+			},
+			wantErr: false,
+		},
+		{
+			name: "When the repository return error, then return error",
+			fields: fields{
+				loginEventRepository: ts.loginEventRepository,
+			},
+			args: args{
+				userId: 123,
+				form: forms.LoginHistoryForm{
+					Page:     1,
+					PageSize: 20,
+				},
+			},
+			want: nil,
+			mock: func() {
+				ts.loginEventRepository.EXPECT().GetLoginEventsByUserId(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "When the pagination is valid, then return the page of login events",
+			fields: fields{
+				loginEventRepository: ts.loginEventRepository,
+			},
 			args: args{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+				userId: 123,
+				form: forms.LoginHistoryForm{
+					Page:     2,
+					PageSize: 1,
+				},
+			},
+			want: &LoginHistoryResult{
+				LoginHistory: pojos.LoginHistory{
+					Data: []pojos.LoginEvent{
+						{
+							Id:            9,
+							IsSuccess:     false,
+							FailureReason: consts.LoginFailureReasonInvalidPassword,
+							Method:        consts.LoginMethodEmail,
+							IpAddress:     "103.10.10.1",
+							UserAgent:     "Mozilla/5.0",
+							CreatedAt:     createdAt,
+						},
+					},
+					Page:     2,
+					PageSize: 1,
+					Total:    2,
+				},
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
 			},
-			want: AuthenticationService{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
-				jwtAuth:      ts.jwtAuth,
+			mock: func() {
+				ts.loginEventRepository.EXPECT().GetLoginEventsByUserId(gomock.Any(), repository.GetLoginEventsByUserIdInput{
+					UserId: 123,
+					Limit:  1,
+					Offset: 1,
+				}).Return(&repository.GetLoginEventsByUserIdOutput{
+					LoginEvents: []repository.LoginEventOutput{
+						{
+							Id:            9,
+							UserId:        &userId,
+							IsSuccess:     false,
+							FailureReason: consts.LoginFailureReasonInvalidPassword,
+							Method:        consts.LoginMethodEmail,
+							IpAddress:     "103.10.10.1",
+							UserAgent:     "Mozilla/5.0",
+							CreatedAt:     createdAt,
+						},
+					},
+					Total: 2,
+				}, nil)
 			},
+			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		ts.T().Run(tt.name, func(t *testing.T) {
-			if got := NewAuthenticationService(tt.args.repository, tt.args.passwordAuth, tt.args.jwtAuth); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewAuthenticationService() = %v, want %v", got, tt.want)
+			tt.mock()
+			a := AuthenticationService{
+				repository:           tt.fields.repository,
+				loginEventRepository: tt.fields.loginEventRepository,
+				passwordAuth:         tt.fields.passwordAuth,
+				jwtAuth:              tt.fields.jwtAuth,
+				auditService:         tt.fields.auditService,
+			}
+			got, err := a.GetLoginHistory(context.Background(), tt.args.userId, tt.args.form)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetLoginHistory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetLoginHistory() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func (ts *AuthenticationServiceTestSuite) TestNewAuthenticationService() {
+
+	loginExpirationDuration := 24 * time.Hour
+
+	got := NewAuthenticationService(ts.repository, ts.loginEventRepository, ts.passwordAuth, ts.jwtAuth, ts.auditService,
+		"simple-user-service", func() time.Duration { return loginExpirationDuration }).(AuthenticationService)
+
+	want := AuthenticationService{
+		repository:           ts.repository,
+		loginEventRepository: ts.loginEventRepository,
+		passwordAuth:         ts.passwordAuth,
+		jwtAuth:              ts.jwtAuth,
+		auditService:         ts.auditService,
+		issuer:               "simple-user-service",
+	}
+
+	if got.getLoginExpirationDuration == nil {
+		ts.T().Fatalf("NewAuthenticationService() getLoginExpirationDuration is nil")
+	}
+
+	// A reloaded duration is used for the following tokens.
+	loginExpirationDuration = time.Hour
+
+	if duration := got.getLoginExpirationDuration(); duration != time.Hour {
+		ts.T().Errorf("NewAuthenticationService() login expiration duration = %v, want %v", duration, time.Hour)
+	}
+
+	got.getLoginExpirationDuration = nil
+
+	if !reflect.DeepEqual(got, want) {
+		ts.T().Errorf("NewAuthenticationService() = %v, want %v", got, want)
+	}
+}
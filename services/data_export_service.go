@@ -0,0 +1,557 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/forms"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/modules"
+	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/utils"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type DataExportService struct {
+	userService           UserServiceInterface
+	authenticationService AuthenticationServiceInterface
+	auditService          AuditServiceInterface
+	repository            repository.DataExportRepositoryInterface
+	fileStorage           modules.FileStorageInterface
+	// downloadUrl is the url of GET /users/export/download, the token of the export is appended.
+	downloadUrl string
+	// runAsync runs the archive build after the request has been answered.
+	runAsync func(task func())
+	// builds bounds the archives built at once, a build holds a slot while it runs.
+	builds chan struct{}
+}
+
+type dataExportManifest struct {
+	UserId      int64             `json:"user_id"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Files       map[string]string `json:"files"`
+}
+
+// RequestExport creates a pending export and builds its archive in the background.
+// The returned download url is the only place the download token is ever shown. A user has at most one export being
+// built, another request is refused until it is done.
+func (d DataExportService) RequestExport(ctx context.Context, userId int64, form forms.DataExportForm, metadata RequestMetadata) (*DataExportResult, error) {
+
+	ctx, span := tracer.Start(ctx, "DataExportService.RequestExport")
+	defer span.End()
+
+	result := DataExportResult{}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err := validate.Struct(form)
+
+	if err != nil {
+
+		var validationErrors validator.ValidationErrors
+
+		errors.As(err, &validationErrors)
+
+		validationErrorMessages := utils.CollectValidationErrorMessages(form, validationErrors)
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = validationErrorMessages
+
+		return &result, nil
+	}
+
+	token, err := utils.GenerateRandomToken(consts.DataExportTokenByteLength)
+
+	if err != nil {
+		return nil, err
+	}
+
+	isEncrypted := utils.StringIsEmpty(form.Password) == false
+	expiredAt := time.Now().Add(consts.DataExportExpiration)
+
+	var exportId int64
+
+	err = d.repository.WithinTransaction(ctx, repository.TransactionOptions{}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+		output, err := txRepository.InsertDataExport(ctx, repository.InsertDataExportInput{
+			UserId:            userId,
+			IsEncrypted:       isEncrypted,
+			DownloadTokenHash: utils.HashToken(token),
+			ExpiredAt:         expiredAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		exportId = output.Id
+
+		return d.auditService.Record(ctx, txRepository, AuditEntry{
+			ActorUserId:   &userId,
+			SubjectUserId: &userId,
+			Action:        consts.AuditActionDataExport,
+			Metadata:      metadata,
+		})
+	})
+
+	if repository.IsUniqueViolation(err, repository.UniqueFieldPendingDataExport) {
+		result.IsExportInProgress = true
+
+		return &result, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	password := form.Password
+
+	// The archive is built after the response is sent, so it must not use the request context.
+	d.runAsync(func() {
+
+		d.builds <- struct{}{}
+		defer func() { <-d.builds }()
+
+		err := d.ProcessExport(context.Background(), exportId, userId, password)
+
+		if err != nil {
+			// The request context is only used for the request id and the user id of the log record.
+			slog.ErrorContext(ctx, "data export failed", slog.Int64("export_id", exportId), slog.Any("error", err), logging.Stack(0))
+		}
+	})
+
+	result.HasValidationErrors = false
+	result.ValidationErrors = map[string]string{}
+	result.DataExport = pojos.DataExport{
+		Id:          exportId,
+		Status:      consts.DataExportStatusPending,
+		IsEncrypted: isEncrypted,
+		CreatedAt:   time.Now(),
+		ExpiredAt:   expiredAt,
+		DownloadUrl: fmt.Sprintf("%s?token=%s", d.downloadUrl, token),
+	}
+
+	return &result, nil
+}
+
+// GetExport returns nil when the export does not exist or belongs to another user.
+func (d DataExportService) GetExport(ctx context.Context, userId int64, exportId int64) (*pojos.DataExport, error) {
+
+	ctx, span := tracer.Start(ctx, "DataExportService.GetExport")
+	defer span.End()
+
+	output, err := d.repository.GetDataExportById(ctx, repository.GetDataExportByIdInput{
+		Id:     exportId,
+		UserId: userId,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if output == nil {
+		return nil, nil
+	}
+
+	return &pojos.DataExport{
+		Id:            output.Id,
+		Status:        output.Status,
+		IsEncrypted:   output.IsEncrypted,
+		FailureReason: output.FailureReason,
+		CreatedAt:     output.CreatedAt,
+		CompletedAt:   output.CompletedAt,
+		ExpiredAt:     output.ExpiredAt,
+	}, nil
+}
+
+func (d DataExportService) GetExportArchive(ctx context.Context, token string) (*DataExportArchiveResult, error) {
+
+	ctx, span := tracer.Start(ctx, "DataExportService.GetExportArchive")
+	defer span.End()
+
+	result := DataExportArchiveResult{}
+
+	output, err := d.repository.GetDataExportByTokenHash(ctx, repository.GetDataExportByTokenHashInput{
+		DownloadTokenHash: utils.HashToken(token),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if output == nil {
+		result.IsNotFound = true
+
+		return &result, nil
+	}
+
+	if output.ExpiredAt.Before(time.Now()) {
+		result.IsExpired = true
+
+		return &result, nil
+	}
+
+	if output.Status != consts.DataExportStatusCompleted {
+		result.IsNotReady = true
+
+		return &result, nil
+	}
+
+	content, err := d.fileStorage.Read(output.FileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.IsEncrypted = output.IsEncrypted
+	result.FileName = output.FileName
+	result.Content = content
+
+	return &result, nil
+}
+
+// ProcessExport builds the archive of an export, stores it and marks the export completed.
+// A failed build marks the export failed, so the user is not left waiting on it. An export that is no longer pending,
+// e.g. because it was marked failed as stale or expired with the anonymized account, is not built.
+func (d DataExportService) ProcessExport(ctx context.Context, exportId int64, userId int64, password string) error {
+
+	ctx, span := tracer.Start(ctx, "DataExportService.ProcessExport")
+	defer span.End()
+
+	processingOutput, err := d.repository.UpdateDataExportStatus(ctx, repository.UpdateDataExportStatusInput{
+		Id:         exportId,
+		FromStatus: consts.DataExportStatusPending,
+		Status:     consts.DataExportStatusProcessing,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if processingOutput.IsSuccessUpdate == false {
+		return nil
+	}
+
+	fileName, err := d.buildAndStoreArchive(ctx, exportId, userId, password)
+
+	if err != nil {
+
+		_, updateErr := d.repository.UpdateDataExportStatus(ctx, repository.UpdateDataExportStatusInput{
+			Id:            exportId,
+			FromStatus:    consts.DataExportStatusProcessing,
+			Status:        consts.DataExportStatusFailed,
+			FailureReason: "The export could not be generated, please request a new one",
+		})
+
+		if updateErr != nil {
+			return updateErr
+		}
+
+		return err
+	}
+
+	completedOutput, err := d.repository.UpdateDataExportStatus(ctx, repository.UpdateDataExportStatusInput{
+		Id:         exportId,
+		FromStatus: consts.DataExportStatusProcessing,
+		Status:     consts.DataExportStatusCompleted,
+		FileName:   fileName,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// The export failed or expired while it was built, nothing refers to the archive anymore.
+	if completedOutput.IsSuccessUpdate == false {
+		return d.fileStorage.Delete(fileName)
+	}
+
+	return nil
+}
+
+// DeleteExpiredArchives deletes the archives of the expired exports and returns how many were deleted.
+// It is run periodically by a background job and after accounts are anonymized.
+func (d DataExportService) DeleteExpiredArchives(ctx context.Context) (int, error) {
+
+	ctx, span := tracer.Start(ctx, "DataExportService.DeleteExpiredArchives")
+	defer span.End()
+
+	deletedCount := 0
+
+	for {
+
+		expiredOutput, err := d.repository.GetExpiredDataExports(ctx, repository.GetExpiredDataExportsInput{
+			Limit: consts.DataExportCleanupBatchSize,
+		})
+
+		if err != nil {
+			return deletedCount, err
+		}
+
+		batchDeletedCount := 0
+
+		for _, dataExport := range expiredOutput.DataExports {
+
+			// The file is deleted first, a failure leaves the export to be cleaned up by the next run.
+			err = d.fileStorage.Delete(dataExport.FileName)
+
+			if err != nil {
+				return deletedCount, err
+			}
+
+			clearOutput, err := d.repository.ClearDataExportFile(ctx, repository.ClearDataExportFileInput{
+				Id: dataExport.Id,
+			})
+
+			if err != nil {
+				return deletedCount, err
+			}
+
+			// Cleared by another instance in the meantime.
+			if clearOutput.IsSuccessUpdate == false {
+				continue
+			}
+
+			deletedCount += 1
+			batchDeletedCount += 1
+		}
+
+		if len(expiredOutput.DataExports) < consts.DataExportCleanupBatchSize || batchDeletedCount == 0 {
+			return deletedCount, nil
+		}
+	}
+}
+
+// FailStaleExports marks the exports failed whose build was interrupted, e.g. by a restart, and returns how many.
+// The password of an encrypted export is not stored, so the build cannot be resumed.
+func (d DataExportService) FailStaleExports(ctx context.Context) (int, error) {
+
+	ctx, span := tracer.Start(ctx, "DataExportService.FailStaleExports")
+	defer span.End()
+
+	output, err := d.repository.FailStaleDataExports(ctx, repository.FailStaleDataExportsInput{
+		CreatedBefore: time.Now().Add(-consts.DataExportBuildTimeout),
+		FailureReason: "The export was interrupted, please request a new one",
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(output.FailedCount), nil
+}
+
+func (d DataExportService) buildAndStoreArchive(ctx context.Context, exportId int64, userId int64, password string) (string, error) {
+
+	content, err := d.buildArchive(ctx, userId)
+
+	if err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf("personal-data-export-%d.zip", exportId)
+
+	if utils.StringIsEmpty(password) == false {
+
+		content, err = modules.EncryptWithPassword(content, password)
+
+		if err != nil {
+			return "", err
+		}
+
+		fileName += ".age"
+	}
+
+	err = d.fileStorage.Write(fileName, content)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fileName, nil
+}
+
+// buildArchive zips one JSON file per category of personal data held about the user.
+func (d DataExportService) buildArchive(ctx context.Context, userId int64) ([]byte, error) {
+
+	user, err := d.userService.GetById(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, errors.New("User not found")
+	}
+
+	loginHistory, err := d.collectLoginHistory(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	auditLogs, err := d.collectAuditLogs(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := dataExportManifest{
+		UserId:      userId,
+		GeneratedAt: time.Now(),
+		Files: map[string]string{
+			"profile.json":       "Your account profile",
+			"login_history.json": "Every login attempt made on your account",
+			"sessions.json":      "Always empty, login tokens are stateless and no sessions are stored",
+			"consents.json":      "Always empty, no consents are collected",
+			"audit_log.json":     "Every recorded change to your account",
+		},
+	}
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{name: "manifest.json", content: manifest},
+		{name: "profile.json", content: user},
+		{name: "login_history.json", content: loginHistory},
+		{name: "sessions.json", content: []interface{}{}},
+		{name: "consents.json", content: []interface{}{}},
+		{name: "audit_log.json", content: auditLogs},
+	}
+
+	buffer := bytes.Buffer{}
+	archive := zip.NewWriter(&buffer)
+
+	for _, file := range files {
+
+		encoded, err := json.MarshalIndent(file.content, "", "  ")
+
+		if err != nil {
+			return nil, err
+		}
+
+		writer, err := archive.Create(file.name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = writer.Write(encoded)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = archive.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (d DataExportService) collectLoginHistory(ctx context.Context, userId int64) ([]pojos.LoginEvent, error) {
+
+	loginEvents := []pojos.LoginEvent{}
+
+	for page := 1; ; page++ {
+
+		result, err := d.authenticationService.GetLoginHistory(ctx, userId, forms.LoginHistoryForm{
+			Page:     page,
+			PageSize: consts.DataExportPageSize,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		loginEvents = append(loginEvents, result.LoginHistory.Data...)
+
+		if len(result.LoginHistory.Data) < consts.DataExportPageSize || int64(len(loginEvents)) >= result.LoginHistory.Total {
+			return loginEvents, nil
+		}
+	}
+}
+
+func (d DataExportService) collectAuditLogs(ctx context.Context, userId int64) ([]pojos.AuditLog, error) {
+
+	auditLogs := []pojos.AuditLog{}
+
+	for page := 1; ; page++ {
+
+		result, err := d.auditService.GetAuditLogs(ctx, forms.AuditLogQueryForm{
+			SubjectUserId: &userId,
+			Page:          page,
+			PageSize:      consts.DataExportPageSize,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		auditLogs = append(auditLogs, result.AuditLogs.Data...)
+
+		if len(result.AuditLogs.Data) < consts.DataExportPageSize || int64(len(auditLogs)) >= result.AuditLogs.Total {
+			return auditLogs, nil
+		}
+	}
+}
+
+// backgroundTasks are the archive builds and the mails still being sent, the shutdown waits for them before closing
+// the database.
+var backgroundTasks sync.WaitGroup
+
+func runInBackground(task func()) {
+
+	backgroundTasks.Add(1)
+
+	go func() {
+		defer backgroundTasks.Done()
+		task()
+	}()
+}
+
+// WaitForBackgroundTasks waits until the running archive builds and mails are done, or returns the error of ctx once
+// it is.
+func WaitForBackgroundTasks(ctx context.Context) error {
+
+	done := make(chan struct{})
+
+	go func() {
+		backgroundTasks.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func NewDataExportService(userService UserServiceInterface, authenticationService AuthenticationServiceInterface,
+	auditService AuditServiceInterface, repository repository.DataExportRepositoryInterface,
+	fileStorage modules.FileStorageInterface, downloadUrl string) DataExportServiceInterface {
+
+	return DataExportService{
+		userService:           userService,
+		authenticationService: authenticationService,
+		auditService:          auditService,
+		repository:            repository,
+		fileStorage:           fileStorage,
+		downloadUrl:           downloadUrl,
+		runAsync:              runInBackground,
+		builds:                make(chan struct{}, consts.DataExportMaxConcurrentBuilds),
+	}
+}
@@ -0,0 +1,731 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/forms"
+	"github.com/SawitProRecruitment/UserService/modules"
+	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type DataExportServiceTestSuite struct {
+	suite.Suite
+
+	userService           *MockUserServiceInterface
+	authenticationService *MockAuthenticationServiceInterface
+	auditService          *MockAuditServiceInterface
+	repository            *repository.MockTransactionRepositoryInterface
+	fileStorage           *modules.MockFileStorageInterface
+
+	MockController *gomock.Controller
+}
+
+func TestDataExportServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(DataExportServiceTestSuite))
+}
+
+func (ts *DataExportServiceTestSuite) SetupSuite() {
+
+	mockCtrl := gomock.NewController(ts.T())
+
+	ts.MockController = mockCtrl
+
+	defer mockCtrl.Finish()
+
+	ts.userService = NewMockUserServiceInterface(mockCtrl)
+	ts.authenticationService = NewMockAuthenticationServiceInterface(mockCtrl)
+	ts.auditService = NewMockAuditServiceInterface(mockCtrl)
+	ts.repository = repository.NewMockTransactionRepositoryInterface(mockCtrl)
+	ts.repository.EXPECT().WithinTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(repository.RunTransactionDirectly(ts.repository)).AnyTimes()
+	ts.fileStorage = modules.NewMockFileStorageInterface(mockCtrl)
+}
+
+func (ts *DataExportServiceTestSuite) newDataExportService(runAsync func(task func())) DataExportService {
+	return DataExportService{
+		userService:           ts.userService,
+		authenticationService: ts.authenticationService,
+		auditService:          ts.auditService,
+		repository:            ts.repository,
+		fileStorage:           ts.fileStorage,
+		downloadUrl:           consts.DefaultDataExportDownloadUrl,
+		runAsync:              runAsync,
+		builds:                make(chan struct{}, 1),
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestNewDataExportService() {
+
+	got := NewDataExportService(ts.userService, ts.authenticationService, ts.auditService, ts.repository, ts.fileStorage,
+		consts.DefaultDataExportDownloadUrl).(DataExportService)
+
+	if got.userService != ts.userService || got.authenticationService != ts.authenticationService ||
+		got.auditService != ts.auditService || got.repository != ts.repository || got.fileStorage != ts.fileStorage ||
+		got.downloadUrl != consts.DefaultDataExportDownloadUrl {
+		ts.T().Errorf("NewDataExportService() = %v", got)
+	}
+
+	if got.runAsync == nil {
+		ts.T().Errorf("NewDataExportService() runAsync is nil")
+	}
+
+	if cap(got.builds) != consts.DataExportMaxConcurrentBuilds {
+		ts.T().Errorf("NewDataExportService() builds %d archives at once, want %d", cap(got.builds), consts.DataExportMaxConcurrentBuilds)
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestWaitForBackgroundTasks() {
+
+	release := make(chan struct{})
+
+	runInBackground(func() {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := WaitForBackgroundTasks(ctx); errors.Is(err, context.DeadlineExceeded) == false {
+		ts.T().Errorf("WaitForBackgroundTasks() error = %v, want %v while a task runs", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+
+	if err := WaitForBackgroundTasks(context.Background()); err != nil {
+		ts.T().Errorf("WaitForBackgroundTasks() error = %v, want nil once the tasks are done", err)
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestDataExportService_RequestExport() {
+
+	userId := int64(123)
+	metadata := RequestMetadata{RequestId: "request-id", IpAddress: "103.10.10.1"}
+
+	tests := []struct {
+		name            string
+		form            forms.DataExportForm
+		want            *DataExportResult
+		wantErr         bool
+		wantAsyncTask   bool
+		wantIsEncrypted bool
+		mock            func()
+	}{
+		{
+			name: "When the password is too short, then it return validation errors",
+			form: forms.DataExportForm{
+				Password: "short",
+			},
+			want: &DataExportResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"password": "Password must have minimum 8 characters long",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+			},
+		},
+
+		{
+			name: "When a password is given, then it creates an encrypted export, audits it and builds it in the background",
+			form: forms.DataExportForm{
+				Password: "export-password",
+			},
+			wantErr:         false,
+			wantAsyncTask:   true,
+			wantIsEncrypted: true,
+			mock: func() {
+				ts.repository.EXPECT().InsertDataExport(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ interface{}, input repository.InsertDataExportInput) (*repository.InsertDataExportOutput, error) {
+
+						if input.UserId != userId || input.IsEncrypted == false || len(input.DownloadTokenHash) != 64 {
+							ts.T().Errorf("InsertDataExport() input = %v", input)
+						}
+
+						return &repository.InsertDataExportOutput{Id: 1}, nil
+					})
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					ActorUserId:   &userId,
+					SubjectUserId: &userId,
+					Action:        consts.AuditActionDataExport,
+					Metadata:      metadata,
+				}).Return(nil)
+			},
+		},
+
+		{
+			name:            "When no password is given, then it creates an unencrypted export",
+			form:            forms.DataExportForm{},
+			wantErr:         false,
+			wantAsyncTask:   true,
+			wantIsEncrypted: false,
+			mock: func() {
+				ts.repository.EXPECT().InsertDataExport(gomock.Any(), gomock.Any()).Return(&repository.InsertDataExportOutput{Id: 2}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+
+		{
+			name: "When the previous export of the user is still being built, then it return that it is in progress and builds nothing",
+			form: forms.DataExportForm{},
+			want: &DataExportResult{
+				IsExportInProgress: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().InsertDataExport(gomock.Any(), gomock.Any()).Return(nil,
+					&repository.UniqueViolationError{Field: repository.UniqueFieldPendingDataExport})
+			},
+		},
+
+		{
+			name:    "When the repository return error, then it return error and builds nothing",
+			form:    forms.DataExportForm{},
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().InsertDataExport(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			asyncTaskCount := 0
+			d := ts.newDataExportService(func(task func()) {
+				asyncTaskCount += 1
+			})
+			got, err := d.RequestExport(context.Background(), userId, tt.form, metadata)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RequestExport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (asyncTaskCount == 1) != tt.wantAsyncTask {
+				t.Errorf("RequestExport() ran %d background tasks, want %v", asyncTaskCount, tt.wantAsyncTask)
+			}
+			if tt.want != nil {
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("RequestExport() got = %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if got == nil {
+				return
+			}
+			if got.DataExport.Status != consts.DataExportStatusPending || got.DataExport.IsEncrypted != tt.wantIsEncrypted {
+				t.Errorf("RequestExport() data export = %v", got.DataExport)
+			}
+			if strings.HasPrefix(got.DataExport.DownloadUrl, consts.DefaultDataExportDownloadUrl+"?token=") == false {
+				t.Errorf("RequestExport() download url = %v", got.DataExport.DownloadUrl)
+			}
+		})
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestDataExportService_RequestExport_BoundsBuilds() {
+
+	ts.repository.EXPECT().InsertDataExport(gomock.Any(), gomock.Any()).Return(&repository.InsertDataExportOutput{Id: 1}, nil)
+	ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	tasks := make(chan func(), 1)
+	d := ts.newDataExportService(func(task func()) {
+		tasks <- task
+	})
+
+	// Another build holds the only slot.
+	d.builds <- struct{}{}
+
+	_, err := d.RequestExport(context.Background(), 123, forms.DataExportForm{}, RequestMetadata{})
+
+	if err != nil {
+		ts.T().Fatalf("RequestExport() error = %v", err)
+	}
+
+	isBuilt := make(chan struct{})
+
+	go func() {
+		(<-tasks)()
+		close(isBuilt)
+	}()
+
+	select {
+	case <-isBuilt:
+		ts.T().Fatalf("RequestExport() built the archive while no build slot was free")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	ts.repository.EXPECT().UpdateDataExportStatus(gomock.Any(), gomock.Any()).Return(&repository.UpdateDataExportOutput{}, nil)
+
+	<-d.builds
+	<-isBuilt
+
+	if len(d.builds) != 0 {
+		ts.T().Errorf("RequestExport() build still holds its slot once done")
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestDataExportService_GetExport() {
+
+	createdAt := time.Date(2024, 4, 18, 9, 50, 16, 0, time.UTC)
+	completedAt := createdAt.Add(time.Minute)
+	expiredAt := createdAt.Add(consts.DataExportExpiration)
+
+	tests := []struct {
+		name    string
+		want    *pojos.DataExport
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the export belongs to the user, then it return the export",
+			want: &pojos.DataExport{
+				Id:          1,
+				Status:      consts.DataExportStatusCompleted,
+				IsEncrypted: true,
+				CreatedAt:   createdAt,
+				CompletedAt: &completedAt,
+				ExpiredAt:   expiredAt,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetDataExportById(gomock.Any(), repository.GetDataExportByIdInput{
+					Id:     1,
+					UserId: 123,
+				}).Return(&repository.DataExportOutput{
+					Id:          1,
+					UserId:      123,
+					Status:      consts.DataExportStatusCompleted,
+					IsEncrypted: true,
+					FileName:    "personal-data-export-1.zip.age",
+					CreatedAt:   createdAt,
+					CompletedAt: &completedAt,
+					ExpiredAt:   expiredAt,
+				}, nil)
+			},
+		},
+
+		{
+			name:    "When the export does not exist or belongs to another user, then it return nil",
+			want:    nil,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetDataExportById(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+		},
+
+		{
+			name:    "When the repository return error, then it return error",
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().GetDataExportById(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			d := ts.newDataExportService(runInBackground)
+			got, err := d.GetExport(context.Background(), 123, 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetExport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetExport() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestDataExportService_GetExportArchive() {
+
+	token := "download-token"
+
+	tests := []struct {
+		name    string
+		want    *DataExportArchiveResult
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the export is completed and not expired, then it return the stored archive",
+			want: &DataExportArchiveResult{
+				IsEncrypted: true,
+				FileName:    "personal-data-export-1.zip.age",
+				Content:     []byte("encrypted archive"),
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetDataExportByTokenHash(gomock.Any(), repository.GetDataExportByTokenHashInput{
+					DownloadTokenHash: utils.HashToken(token),
+				}).Return(&repository.DataExportOutput{
+					Id:          1,
+					Status:      consts.DataExportStatusCompleted,
+					IsEncrypted: true,
+					FileName:    "personal-data-export-1.zip.age",
+					ExpiredAt:   time.Now().Add(time.Hour),
+				}, nil)
+				ts.fileStorage.EXPECT().Read("personal-data-export-1.zip.age").Return([]byte("encrypted archive"), nil)
+			},
+		},
+
+		{
+			name:    "When the token is unknown, then it return not found",
+			want:    &DataExportArchiveResult{IsNotFound: true},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetDataExportByTokenHash(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+		},
+
+		{
+			name:    "When the download url has expired, then it return expired",
+			want:    &DataExportArchiveResult{IsExpired: true},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetDataExportByTokenHash(gomock.Any(), gomock.Any()).Return(&repository.DataExportOutput{
+					Status:    consts.DataExportStatusCompleted,
+					ExpiredAt: time.Now().Add(-time.Hour),
+				}, nil)
+			},
+		},
+
+		{
+			name:    "When the export is still processing, then it return not ready",
+			want:    &DataExportArchiveResult{IsNotReady: true},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetDataExportByTokenHash(gomock.Any(), gomock.Any()).Return(&repository.DataExportOutput{
+					Status:    consts.DataExportStatusProcessing,
+					ExpiredAt: time.Now().Add(time.Hour),
+				}, nil)
+			},
+		},
+
+		{
+			name:    "When the repository return error, then it return error",
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().GetDataExportByTokenHash(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			d := ts.newDataExportService(runInBackground)
+			got, err := d.GetExportArchive(context.Background(), token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetExportArchive() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetExportArchive() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestDataExportService_ProcessExport() {
+
+	userId := int64(123)
+	user := &pojos.User{Id: userId, PhoneNumber: "+628111", FullName: "Rizqy"}
+
+	mockCollectedData := func() {
+		ts.userService.EXPECT().GetById(gomock.Any(), userId).Return(user, nil)
+		ts.authenticationService.EXPECT().GetLoginHistory(gomock.Any(), userId, forms.LoginHistoryForm{
+			Page:     1,
+			PageSize: consts.DataExportPageSize,
+		}).Return(&LoginHistoryResult{
+			LoginHistory: pojos.LoginHistory{
+				Data:  []pojos.LoginEvent{{Id: 1, IsSuccess: true}},
+				Total: 1,
+			},
+		}, nil)
+		ts.auditService.EXPECT().GetAuditLogs(gomock.Any(), forms.AuditLogQueryForm{
+			SubjectUserId: &userId,
+			Page:          1,
+			PageSize:      consts.DataExportPageSize,
+		}).Return(&AuditLogsResult{
+			AuditLogs: pojos.AuditLogs{
+				Data:  []pojos.AuditLog{{Id: 1, Action: consts.AuditActionRegister}},
+				Total: 1,
+			},
+		}, nil)
+	}
+
+	expectUpdatedStatus := func(fromStatus string, status string, isSuccessUpdate bool) *gomock.Call {
+		return ts.repository.EXPECT().UpdateDataExportStatus(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, input repository.UpdateDataExportStatusInput) (*repository.UpdateDataExportOutput, error) {
+
+				if input.Id != 1 || input.FromStatus != fromStatus || input.Status != status {
+					ts.T().Errorf("UpdateDataExportStatus() input = %v, want status %v from %v", input, status, fromStatus)
+				}
+
+				return &repository.UpdateDataExportOutput{IsSuccessUpdate: isSuccessUpdate}, nil
+			})
+	}
+
+	expectStatus := func(status string) *gomock.Call {
+
+		fromStatus := consts.DataExportStatusProcessing
+
+		if status == consts.DataExportStatusProcessing {
+			fromStatus = consts.DataExportStatusPending
+		}
+
+		return expectUpdatedStatus(fromStatus, status, true)
+	}
+
+	readArchive := func(t *testing.T, content []byte) []string {
+
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+
+		if err != nil {
+			t.Errorf("ProcessExport() wrote an invalid zip: %v", err)
+			return nil
+		}
+
+		names := []string{}
+
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+		}
+
+		sort.Strings(names)
+
+		return names
+	}
+
+	wantFiles := []string{"audit_log.json", "consents.json", "login_history.json", "manifest.json", "profile.json", "sessions.json"}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+		mock     func(t *testing.T)
+	}{
+		{
+			name:     "When no password is given, then it stores a zip with one JSON file per category and completes the export",
+			password: "",
+			wantErr:  false,
+			mock: func(t *testing.T) {
+				gomock.InOrder(
+					expectStatus(consts.DataExportStatusProcessing),
+					expectStatus(consts.DataExportStatusCompleted),
+				)
+				mockCollectedData()
+				ts.fileStorage.EXPECT().Write("personal-data-export-1.zip", gomock.Any()).DoAndReturn(
+					func(name string, content []byte) error {
+						if got := readArchive(t, content); !reflect.DeepEqual(got, wantFiles) {
+							t.Errorf("ProcessExport() archive files = %v, want %v", got, wantFiles)
+						}
+						return nil
+					})
+			},
+		},
+
+		{
+			name:     "When a password is given, then it stores the zip encrypted with the password",
+			password: "export-password",
+			wantErr:  false,
+			mock: func(t *testing.T) {
+				gomock.InOrder(
+					expectStatus(consts.DataExportStatusProcessing),
+					expectStatus(consts.DataExportStatusCompleted),
+				)
+				mockCollectedData()
+				ts.fileStorage.EXPECT().Write("personal-data-export-1.zip.age", gomock.Any()).DoAndReturn(
+					func(name string, content []byte) error {
+						decrypted, err := modules.DecryptWithPassword(content, "export-password")
+						if err != nil {
+							t.Errorf("ProcessExport() archive cannot be decrypted: %v", err)
+							return nil
+						}
+						if got := readArchive(t, decrypted); !reflect.DeepEqual(got, wantFiles) {
+							t.Errorf("ProcessExport() archive files = %v, want %v", got, wantFiles)
+						}
+						return nil
+					})
+			},
+		},
+
+		{
+			name:     "When the archive cannot be stored, then it marks the export failed and return error",
+			password: "",
+			wantErr:  true,
+			mock: func(t *testing.T) {
+				gomock.InOrder(
+					expectStatus(consts.DataExportStatusProcessing),
+					expectStatus(consts.DataExportStatusFailed),
+				)
+				mockCollectedData()
+				ts.fileStorage.EXPECT().Write(gomock.Any(), gomock.Any()).Return(errors.New("disk full"))
+			},
+		},
+
+		{
+			name:     "When the export is no longer pending, then it does not build the archive",
+			password: "",
+			wantErr:  false,
+			mock: func(t *testing.T) {
+				expectUpdatedStatus(consts.DataExportStatusPending, consts.DataExportStatusProcessing, false)
+			},
+		},
+
+		{
+			name:     "When the export failed or expired while it was built, then it deletes the stored archive",
+			password: "",
+			wantErr:  false,
+			mock: func(t *testing.T) {
+				gomock.InOrder(
+					expectStatus(consts.DataExportStatusProcessing),
+					expectUpdatedStatus(consts.DataExportStatusProcessing, consts.DataExportStatusCompleted, false),
+				)
+				mockCollectedData()
+				gomock.InOrder(
+					ts.fileStorage.EXPECT().Write("personal-data-export-1.zip", gomock.Any()).Return(nil),
+					ts.fileStorage.EXPECT().Delete("personal-data-export-1.zip").Return(nil),
+				)
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock(t)
+			d := ts.newDataExportService(runInBackground)
+			if err := d.ProcessExport(context.Background(), 1, userId, tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("ProcessExport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestDataExportService_DeleteExpiredArchives() {
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "When exports expired, then it delete their archives and clear their file names",
+			want:    1,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetExpiredDataExports(gomock.Any(), repository.GetExpiredDataExportsInput{
+					Limit: consts.DataExportCleanupBatchSize,
+				}).Return(&repository.GetDataExportsOutput{
+					DataExports: []repository.DataExportOutput{
+						{Id: 1, FileName: "personal-data-export-1.zip"},
+						{Id: 2, FileName: "personal-data-export-2.zip.age"},
+					},
+				}, nil)
+				ts.fileStorage.EXPECT().Delete("personal-data-export-1.zip").Return(nil)
+				ts.repository.EXPECT().ClearDataExportFile(gomock.Any(), repository.ClearDataExportFileInput{Id: 1}).
+					Return(&repository.UpdateDataExportOutput{IsSuccessUpdate: true}, nil)
+				ts.fileStorage.EXPECT().Delete("personal-data-export-2.zip.age").Return(nil)
+				ts.repository.EXPECT().ClearDataExportFile(gomock.Any(), repository.ClearDataExportFileInput{Id: 2}).
+					Return(&repository.UpdateDataExportOutput{IsSuccessUpdate: false}, nil)
+			},
+		},
+
+		{
+			name:    "When an archive cannot be deleted, then it keep its file name and return error",
+			want:    0,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().GetExpiredDataExports(gomock.Any(), gomock.Any()).Return(&repository.GetDataExportsOutput{
+					DataExports: []repository.DataExportOutput{{Id: 1, FileName: "personal-data-export-1.zip"}},
+				}, nil)
+				ts.fileStorage.EXPECT().Delete("personal-data-export-1.zip").Return(errors.New("permission denied"))
+			},
+		},
+
+		{
+			name:    "When the repository return error, then it return error",
+			want:    0,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().GetExpiredDataExports(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			d := ts.newDataExportService(runInBackground)
+			got, err := d.DeleteExpiredArchives(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteExpiredArchives() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DeleteExpiredArchives() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *DataExportServiceTestSuite) TestDataExportService_FailStaleExports() {
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "When exports were interrupted, then it mark those created before the build timeout failed",
+			want:    2,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().FailStaleDataExports(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, input repository.FailStaleDataExportsInput) (*repository.FailStaleDataExportsOutput, error) {
+						if time.Since(input.CreatedBefore) < consts.DataExportBuildTimeout || input.FailureReason == "" {
+							ts.T().Errorf("FailStaleDataExports() input = %+v", input)
+						}
+						return &repository.FailStaleDataExportsOutput{FailedCount: 2}, nil
+					})
+			},
+		},
+
+		{
+			name:    "When the repository return error, then it return error",
+			want:    0,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().FailStaleDataExports(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			d := ts.newDataExportService(runInBackground)
+			got, err := d.FailStaleExports(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FailStaleExports() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("FailStaleExports() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/modules"
+	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthCheck is a dependency the service needs to answer requests. The error of Check is shown by GET /readyz,
+// so it must not contain hosts, credentials or other details of the failure, those belong in the logs.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthService struct {
+	checks  []HealthCheck
+	timeout time.Duration
+	// isShuttingDown is shared by the copies of the service, StartShutdown flips it for all of them.
+	isShuttingDown *atomic.Bool
+}
+
+func (h HealthService) GetLiveness(ctx context.Context) pojos.Health {
+	return pojos.Health{Status: consts.HealthStatusOk}
+}
+
+// GetReadiness runs the checks concurrently, each cancelled after the timeout of the service.
+func (h HealthService) GetReadiness(ctx context.Context) pojos.Health {
+
+	health := pojos.Health{
+		Status: consts.HealthStatusOk,
+		Checks: make([]pojos.HealthCheck, len(h.checks)+1),
+	}
+
+	// Load balancers stop sending requests once the shutdown check fails, while the running ones are finished.
+	health.Checks[0] = pojos.HealthCheck{Name: consts.HealthCheckShutdown, Status: consts.HealthStatusOk}
+
+	if h.isShuttingDown.Load() {
+		health.Checks[0].Status = consts.HealthStatusFail
+		health.Checks[0].Error = "The service is shutting down"
+	}
+
+	var wg sync.WaitGroup
+
+	for i, check := range h.checks {
+
+		wg.Add(1)
+
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			health.Checks[i+1] = h.runCheck(ctx, check)
+		}(i, check)
+	}
+
+	wg.Wait()
+
+	for _, check := range health.Checks {
+		if check.Status != consts.HealthStatusOk {
+			health.Status = consts.HealthStatusFail
+		}
+	}
+
+	return health
+}
+
+func (h HealthService) runCheck(ctx context.Context, check HealthCheck) pojos.HealthCheck {
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	startedAt := time.Now()
+
+	err := check.Check(checkCtx)
+
+	result := pojos.HealthCheck{
+		Name:       check.Name,
+		Status:     consts.HealthStatusOk,
+		DurationMs: float64(time.Since(startedAt).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = consts.HealthStatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (h HealthService) StartShutdown() {
+	h.isShuttingDown.Store(true)
+}
+
+// NewDatabaseHealthCheck checks that the database accepts connections.
+func NewDatabaseHealthCheck(databaseRepository repository.DatabaseRepositoryInterface) HealthCheck {
+
+	return HealthCheck{
+		Name: consts.HealthCheckDatabase,
+		Check: func(ctx context.Context) error {
+
+			err := databaseRepository.Ping(ctx)
+
+			if err != nil {
+				slog.WarnContext(ctx, "database health check failed", slog.Any("error", err))
+				return errors.New("The database is unreachable")
+			}
+
+			return nil
+		},
+	}
+}
+
+// NewMigrationHealthCheck checks that the migrations up to expectedVersion, the latest one of the binary, are applied.
+// A newer schema passes, during a rolling update the instances of the previous release keep serving on it.
+func NewMigrationHealthCheck(databaseRepository repository.DatabaseRepositoryInterface, expectedVersion int64) HealthCheck {
+
+	return HealthCheck{
+		Name: consts.HealthCheckMigrations,
+		Check: func(ctx context.Context) error {
+
+			version, err := databaseRepository.GetSchemaVersion(ctx)
+
+			if err != nil {
+				slog.WarnContext(ctx, "migration health check failed", slog.Any("error", err))
+				return errors.New("The schema version cannot be read")
+			}
+
+			if version < expectedVersion {
+				return fmt.Errorf("The schema is at version %d, expected %d", version, expectedVersion)
+			}
+
+			return nil
+		},
+	}
+}
+
+// NewKeyMaterialHealthCheck checks that the keys can sign a token and verify it again.
+func NewKeyMaterialHealthCheck(jwtAuth modules.JsonWebTokenUtilInterface) HealthCheck {
+
+	return HealthCheck{
+		Name: consts.HealthCheckKeyMaterial,
+		Check: func(ctx context.Context) error {
+
+			token, err := jwtAuth.GenerateJwt(modules.CustomClaims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				},
+			})
+
+			if err == nil {
+				_, err = jwtAuth.VerifyJwt(*token)
+			}
+
+			if err != nil {
+				slog.WarnContext(ctx, "key material health check failed", slog.Any("error", err))
+				return errors.New("The keys cannot sign and verify a token")
+			}
+
+			return nil
+		},
+	}
+}
+
+func NewHealthService(checks []HealthCheck, timeout time.Duration) HealthServiceInterface {
+	return HealthService{
+		checks:         checks,
+		timeout:        timeout,
+		isShuttingDown: &atomic.Bool{},
+	}
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/SawitProRecruitment/UserService/consts"
+	"github.com/SawitProRecruitment/UserService/modules"
+	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type HealthServiceTestSuite struct {
+	suite.Suite
+
+	databaseRepository *repository.MockDatabaseRepositoryInterface
+	jwtAuth            *modules.MockJsonWebTokenUtilInterface
+
+	MockController *gomock.Controller
+}
+
+func TestHealthServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthServiceTestSuite))
+}
+
+func (ts *HealthServiceTestSuite) SetupSuite() {
+
+	mockCtrl := gomock.NewController(ts.T())
+
+	ts.MockController = mockCtrl
+
+	defer mockCtrl.Finish()
+
+	ts.databaseRepository = repository.NewMockDatabaseRepositoryInterface(mockCtrl)
+	ts.jwtAuth = modules.NewMockJsonWebTokenUtilInterface(mockCtrl)
+}
+
+func (ts *HealthServiceTestSuite) TestNewHealthService() {
+
+	checks := []HealthCheck{NewDatabaseHealthCheck(ts.databaseRepository)}
+
+	got := NewHealthService(checks, time.Second).(HealthService)
+
+	if len(got.checks) != 1 || got.checks[0].Name != consts.HealthCheckDatabase {
+		ts.T().Errorf("NewHealthService() checks = %v, want the database check", got.checks)
+	}
+
+	if got.timeout != time.Second {
+		ts.T().Errorf("NewHealthService() timeout = %v, want %v", got.timeout, time.Second)
+	}
+
+	if got.isShuttingDown.Load() {
+		ts.T().Errorf("NewHealthService() is shutting down")
+	}
+}
+
+func (ts *HealthServiceTestSuite) TestHealthService_GetLiveness() {
+
+	h := NewHealthService(nil, time.Second)
+	h.StartShutdown()
+
+	want := pojos.Health{Status: consts.HealthStatusOk}
+
+	if got := h.GetLiveness(context.Background()); !reflect.DeepEqual(got, want) {
+		ts.T().Errorf("GetLiveness() = %v, want %v", got, want)
+	}
+}
+
+func (ts *HealthServiceTestSuite) TestHealthService_GetReadiness() {
+
+	token := "token"
+	shutdownOk := pojos.HealthCheck{Name: consts.HealthCheckShutdown, Status: consts.HealthStatusOk}
+	databaseOk := pojos.HealthCheck{Name: consts.HealthCheckDatabase, Status: consts.HealthStatusOk}
+	migrationsOk := pojos.HealthCheck{Name: consts.HealthCheckMigrations, Status: consts.HealthStatusOk}
+	keyMaterialOk := pojos.HealthCheck{Name: consts.HealthCheckKeyMaterial, Status: consts.HealthStatusOk}
+
+	tests := []struct {
+		name           string
+		isShuttingDown bool
+		want           pojos.Health
+		mock           func()
+	}{
+		{
+			name: "When every check passes, then it return ok",
+			want: pojos.Health{
+				Status: consts.HealthStatusOk,
+				Checks: []pojos.HealthCheck{shutdownOk, databaseOk, migrationsOk, keyMaterialOk},
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().Ping(gomock.Any()).Return(nil)
+				ts.databaseRepository.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(5), nil)
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.jwtAuth.EXPECT().VerifyJwt(token).Return(&modules.CustomClaims{}, nil)
+			},
+		},
+		{
+			name: "When the database is unreachable, then it return fail without the database error",
+			want: pojos.Health{
+				Status: consts.HealthStatusFail,
+				Checks: []pojos.HealthCheck{
+					shutdownOk,
+					{Name: consts.HealthCheckDatabase, Status: consts.HealthStatusFail, Error: "The database is unreachable"},
+					{Name: consts.HealthCheckMigrations, Status: consts.HealthStatusFail, Error: "The schema version cannot be read"},
+					keyMaterialOk,
+				},
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().Ping(gomock.Any()).Return(errors.New("dial tcp 10.0.0.5:5432: connect: connection refused"))
+				ts.databaseRepository.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(0), errors.New("dial tcp 10.0.0.5:5432: connect: connection refused"))
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.jwtAuth.EXPECT().VerifyJwt(token).Return(&modules.CustomClaims{}, nil)
+			},
+		},
+		{
+			name: "When a migration is pending, then it return fail with the versions",
+			want: pojos.Health{
+				Status: consts.HealthStatusFail,
+				Checks: []pojos.HealthCheck{
+					shutdownOk,
+					databaseOk,
+					{Name: consts.HealthCheckMigrations, Status: consts.HealthStatusFail, Error: "The schema is at version 4, expected 5"},
+					keyMaterialOk,
+				},
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().Ping(gomock.Any()).Return(nil)
+				ts.databaseRepository.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(4), nil)
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.jwtAuth.EXPECT().VerifyJwt(token).Return(&modules.CustomClaims{}, nil)
+			},
+		},
+		{
+			name: "When the schema is newer than the migrations, then it return ok",
+			want: pojos.Health{
+				Status: consts.HealthStatusOk,
+				Checks: []pojos.HealthCheck{shutdownOk, databaseOk, migrationsOk, keyMaterialOk},
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().Ping(gomock.Any()).Return(nil)
+				ts.databaseRepository.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(6), nil)
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.jwtAuth.EXPECT().VerifyJwt(token).Return(&modules.CustomClaims{}, nil)
+			},
+		},
+		{
+			name: "When the keys cannot verify the token they signed, then it return fail",
+			want: pojos.Health{
+				Status: consts.HealthStatusFail,
+				Checks: []pojos.HealthCheck{
+					shutdownOk,
+					databaseOk,
+					migrationsOk,
+					{Name: consts.HealthCheckKeyMaterial, Status: consts.HealthStatusFail, Error: "The keys cannot sign and verify a token"},
+				},
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().Ping(gomock.Any()).Return(nil)
+				ts.databaseRepository.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(5), nil)
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.jwtAuth.EXPECT().VerifyJwt(token).Return(nil, errors.New("crypto/rsa: verification error"))
+			},
+		},
+		{
+			name:           "When the service is shutting down, then it return fail",
+			isShuttingDown: true,
+			want: pojos.Health{
+				Status: consts.HealthStatusFail,
+				Checks: []pojos.HealthCheck{
+					{Name: consts.HealthCheckShutdown, Status: consts.HealthStatusFail, Error: "The service is shutting down"},
+					databaseOk,
+					migrationsOk,
+					keyMaterialOk,
+				},
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().Ping(gomock.Any()).Return(nil)
+				ts.databaseRepository.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(5), nil)
+				ts.jwtAuth.EXPECT().GenerateJwt(gomock.Any()).Return(&token, nil)
+				ts.jwtAuth.EXPECT().VerifyJwt(token).Return(&modules.CustomClaims{}, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			h := HealthService{
+				checks: []HealthCheck{
+					NewDatabaseHealthCheck(ts.databaseRepository),
+					NewMigrationHealthCheck(ts.databaseRepository, 5),
+					NewKeyMaterialHealthCheck(ts.jwtAuth),
+				},
+				timeout:        time.Second,
+				isShuttingDown: &atomic.Bool{},
+			}
+			if tt.isShuttingDown {
+				h.StartShutdown()
+			}
+			got := h.GetReadiness(context.Background())
+			// The durations differ on every run.
+			for i := range got.Checks {
+				got.Checks[i].DurationMs = 0
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetReadiness() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
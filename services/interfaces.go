@@ -1,18 +1,52 @@
 package services
 
 import (
+	"context"
 	"github.com/SawitProRecruitment/UserService/forms"
 	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
 )
 
 type UserServiceInterface interface {
-	Register(form forms.UserRegisterForm) (*RegisterResult, error)
-	Update(userId int64, form forms.UserUpdateForm) (*UpdateResult, error)
-	GetById(userId int64) (*pojos.User, error)
-	GetByPhoneNumber(phoneNumber string) (*pojos.User, error)
+	Register(ctx context.Context, form forms.UserRegisterForm, metadata RequestMetadata) (*RegisterResult, error)
+	Update(ctx context.Context, userId int64, form forms.UserUpdateForm, metadata RequestMetadata) (*UpdateResult, error)
+	GetById(ctx context.Context, userId int64) (*pojos.User, error)
+	GetByPhoneNumber(ctx context.Context, phoneNumber string) (*pojos.User, error)
+	ChangeEmail(ctx context.Context, userId int64, form forms.UserChangeEmailForm, metadata RequestMetadata) (*ChangeEmailResult, error)
+	VerifyEmail(ctx context.Context, form forms.VerifyEmailForm, metadata RequestMetadata) (*VerifyEmailResult, error)
+	ChangeRole(ctx context.Context, actorUserId int64, userId int64, form forms.UserChangeRoleForm, metadata RequestMetadata) (*ChangeRoleResult, error)
+	ChangeStatus(ctx context.Context, actorUserId int64, userId int64, form forms.UserChangeStatusForm, metadata RequestMetadata) (*ChangeStatusResult, error)
+	RequestDeletion(ctx context.Context, userId int64, form forms.UserDeleteAccountForm, metadata RequestMetadata) (*DeleteAccountResult, error)
+	AnonymizeDueAccounts(ctx context.Context) (int, error)
 }
 
 type AuthenticationServiceInterface interface {
-	Authenticate(form forms.UserLoginForm) (*AuthenticationResult, error)
-	Authorize(token string) (*AuthorizationResult, error)
+	Authenticate(ctx context.Context, form forms.UserLoginForm, metadata RequestMetadata) (*AuthenticationResult, error)
+	Authorize(ctx context.Context, token string) (*AuthorizationResult, error)
+	GetLoginHistory(ctx context.Context, userId int64, form forms.LoginHistoryForm) (*LoginHistoryResult, error)
+}
+
+type AuditServiceInterface interface {
+	Record(ctx context.Context, auditLogRepository repository.AuditLogRepositoryInterface, entry AuditEntry) error
+	GetAuditLogs(ctx context.Context, form forms.AuditLogQueryForm) (*AuditLogsResult, error)
+	VerifyChain(ctx context.Context) (*AuditChainVerificationResult, error)
+}
+
+type DataExportServiceInterface interface {
+	RequestExport(ctx context.Context, userId int64, form forms.DataExportForm, metadata RequestMetadata) (*DataExportResult, error)
+	GetExport(ctx context.Context, userId int64, exportId int64) (*pojos.DataExport, error)
+	GetExportArchive(ctx context.Context, token string) (*DataExportArchiveResult, error)
+	DeleteExpiredArchives(ctx context.Context) (int, error)
+	FailStaleExports(ctx context.Context) (int, error)
+}
+
+type SystemServiceInterface interface {
+	GetDatabasePoolStats(ctx context.Context) pojos.DatabasePoolStats
+}
+
+type HealthServiceInterface interface {
+	GetLiveness(ctx context.Context) pojos.Health
+	GetReadiness(ctx context.Context) pojos.Health
+	// StartShutdown makes the readiness fail from now on, the liveness stays ok.
+	StartShutdown()
 }
@@ -5,10 +5,12 @@
 package services
 
 import (
+	context "context"
 	reflect "reflect"
 
 	forms "github.com/SawitProRecruitment/UserService/forms"
 	pojos "github.com/SawitProRecruitment/UserService/pojos"
+	repository "github.com/SawitProRecruitment/UserService/repository"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,64 +37,154 @@ func (m *MockUserServiceInterface) EXPECT() *MockUserServiceInterfaceMockRecorde
 	return m.recorder
 }
 
+// AnonymizeDueAccounts mocks base method.
+func (m *MockUserServiceInterface) AnonymizeDueAccounts(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeDueAccounts", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeDueAccounts indicates an expected call of AnonymizeDueAccounts.
+func (mr *MockUserServiceInterfaceMockRecorder) AnonymizeDueAccounts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeDueAccounts", reflect.TypeOf((*MockUserServiceInterface)(nil).AnonymizeDueAccounts), ctx)
+}
+
+// ChangeEmail mocks base method.
+func (m *MockUserServiceInterface) ChangeEmail(ctx context.Context, userId int64, form forms.UserChangeEmailForm, metadata RequestMetadata) (*ChangeEmailResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeEmail", ctx, userId, form, metadata)
+	ret0, _ := ret[0].(*ChangeEmailResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeEmail indicates an expected call of ChangeEmail.
+func (mr *MockUserServiceInterfaceMockRecorder) ChangeEmail(ctx, userId, form, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeEmail", reflect.TypeOf((*MockUserServiceInterface)(nil).ChangeEmail), ctx, userId, form, metadata)
+}
+
+// ChangeRole mocks base method.
+func (m *MockUserServiceInterface) ChangeRole(ctx context.Context, actorUserId, userId int64, form forms.UserChangeRoleForm, metadata RequestMetadata) (*ChangeRoleResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeRole", ctx, actorUserId, userId, form, metadata)
+	ret0, _ := ret[0].(*ChangeRoleResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeRole indicates an expected call of ChangeRole.
+func (mr *MockUserServiceInterfaceMockRecorder) ChangeRole(ctx, actorUserId, userId, form, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeRole", reflect.TypeOf((*MockUserServiceInterface)(nil).ChangeRole), ctx, actorUserId, userId, form, metadata)
+}
+
+// ChangeStatus mocks base method.
+func (m *MockUserServiceInterface) ChangeStatus(ctx context.Context, actorUserId, userId int64, form forms.UserChangeStatusForm, metadata RequestMetadata) (*ChangeStatusResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, actorUserId, userId, form, metadata)
+	ret0, _ := ret[0].(*ChangeStatusResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockUserServiceInterfaceMockRecorder) ChangeStatus(ctx, actorUserId, userId, form, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockUserServiceInterface)(nil).ChangeStatus), ctx, actorUserId, userId, form, metadata)
+}
+
 // GetById mocks base method.
-func (m *MockUserServiceInterface) GetById(userId int64) (*pojos.User, error) {
+func (m *MockUserServiceInterface) GetById(ctx context.Context, userId int64) (*pojos.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", userId)
+	ret := m.ctrl.Call(m, "GetById", ctx, userId)
 	ret0, _ := ret[0].(*pojos.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockUserServiceInterfaceMockRecorder) GetById(userId interface{}) *gomock.Call {
+func (mr *MockUserServiceInterfaceMockRecorder) GetById(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockUserServiceInterface)(nil).GetById), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockUserServiceInterface)(nil).GetById), ctx, userId)
 }
 
 // GetByPhoneNumber mocks base method.
-func (m *MockUserServiceInterface) GetByPhoneNumber(phoneNumber string) (*pojos.User, error) {
+func (m *MockUserServiceInterface) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*pojos.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByPhoneNumber", phoneNumber)
+	ret := m.ctrl.Call(m, "GetByPhoneNumber", ctx, phoneNumber)
 	ret0, _ := ret[0].(*pojos.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByPhoneNumber indicates an expected call of GetByPhoneNumber.
-func (mr *MockUserServiceInterfaceMockRecorder) GetByPhoneNumber(phoneNumber interface{}) *gomock.Call {
+func (mr *MockUserServiceInterfaceMockRecorder) GetByPhoneNumber(ctx, phoneNumber interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPhoneNumber", reflect.TypeOf((*MockUserServiceInterface)(nil).GetByPhoneNumber), phoneNumber)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPhoneNumber", reflect.TypeOf((*MockUserServiceInterface)(nil).GetByPhoneNumber), ctx, phoneNumber)
 }
 
 // Register mocks base method.
-func (m *MockUserServiceInterface) Register(form forms.UserRegisterForm) (*RegisterResult, error) {
+func (m *MockUserServiceInterface) Register(ctx context.Context, form forms.UserRegisterForm, metadata RequestMetadata) (*RegisterResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", form)
+	ret := m.ctrl.Call(m, "Register", ctx, form, metadata)
 	ret0, _ := ret[0].(*RegisterResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Register indicates an expected call of Register.
-func (mr *MockUserServiceInterfaceMockRecorder) Register(form interface{}) *gomock.Call {
+func (mr *MockUserServiceInterfaceMockRecorder) Register(ctx, form, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserServiceInterface)(nil).Register), ctx, form, metadata)
+}
+
+// RequestDeletion mocks base method.
+func (m *MockUserServiceInterface) RequestDeletion(ctx context.Context, userId int64, form forms.UserDeleteAccountForm, metadata RequestMetadata) (*DeleteAccountResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestDeletion", ctx, userId, form, metadata)
+	ret0, _ := ret[0].(*DeleteAccountResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestDeletion indicates an expected call of RequestDeletion.
+func (mr *MockUserServiceInterfaceMockRecorder) RequestDeletion(ctx, userId, form, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserServiceInterface)(nil).Register), form)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestDeletion", reflect.TypeOf((*MockUserServiceInterface)(nil).RequestDeletion), ctx, userId, form, metadata)
 }
 
 // Update mocks base method.
-func (m *MockUserServiceInterface) Update(userId int64, form forms.UserUpdateForm) (*UpdateResult, error) {
+func (m *MockUserServiceInterface) Update(ctx context.Context, userId int64, form forms.UserUpdateForm, metadata RequestMetadata) (*UpdateResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", userId, form)
+	ret := m.ctrl.Call(m, "Update", ctx, userId, form, metadata)
 	ret0, _ := ret[0].(*UpdateResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockUserServiceInterfaceMockRecorder) Update(userId, form interface{}) *gomock.Call {
+func (mr *MockUserServiceInterfaceMockRecorder) Update(ctx, userId, form, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserServiceInterface)(nil).Update), ctx, userId, form, metadata)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserServiceInterface) VerifyEmail(ctx context.Context, form forms.VerifyEmailForm, metadata RequestMetadata) (*VerifyEmailResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, form, metadata)
+	ret0, _ := ret[0].(*VerifyEmailResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserServiceInterfaceMockRecorder) VerifyEmail(ctx, form, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserServiceInterface)(nil).Update), userId, form)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserServiceInterface)(nil).VerifyEmail), ctx, form, metadata)
 }
 
 // MockAuthenticationServiceInterface is a mock of AuthenticationServiceInterface interface.
@@ -119,31 +211,311 @@ func (m *MockAuthenticationServiceInterface) EXPECT() *MockAuthenticationService
 }
 
 // Authenticate mocks base method.
-func (m *MockAuthenticationServiceInterface) Authenticate(form forms.UserLoginForm) (*AuthenticationResult, error) {
+func (m *MockAuthenticationServiceInterface) Authenticate(ctx context.Context, form forms.UserLoginForm, metadata RequestMetadata) (*AuthenticationResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Authenticate", form)
+	ret := m.ctrl.Call(m, "Authenticate", ctx, form, metadata)
 	ret0, _ := ret[0].(*AuthenticationResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Authenticate indicates an expected call of Authenticate.
-func (mr *MockAuthenticationServiceInterfaceMockRecorder) Authenticate(form interface{}) *gomock.Call {
+func (mr *MockAuthenticationServiceInterfaceMockRecorder) Authenticate(ctx, form, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthenticationServiceInterface)(nil).Authenticate), form)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthenticationServiceInterface)(nil).Authenticate), ctx, form, metadata)
 }
 
 // Authorize mocks base method.
-func (m *MockAuthenticationServiceInterface) Authorize(token string) (*AuthorizationResult, error) {
+func (m *MockAuthenticationServiceInterface) Authorize(ctx context.Context, token string) (*AuthorizationResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Authorize", token)
+	ret := m.ctrl.Call(m, "Authorize", ctx, token)
 	ret0, _ := ret[0].(*AuthorizationResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Authorize indicates an expected call of Authorize.
-func (mr *MockAuthenticationServiceInterfaceMockRecorder) Authorize(token interface{}) *gomock.Call {
+func (mr *MockAuthenticationServiceInterfaceMockRecorder) Authorize(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAuthenticationServiceInterface)(nil).Authorize), ctx, token)
+}
+
+// GetLoginHistory mocks base method.
+func (m *MockAuthenticationServiceInterface) GetLoginHistory(ctx context.Context, userId int64, form forms.LoginHistoryForm) (*LoginHistoryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginHistory", ctx, userId, form)
+	ret0, _ := ret[0].(*LoginHistoryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginHistory indicates an expected call of GetLoginHistory.
+func (mr *MockAuthenticationServiceInterfaceMockRecorder) GetLoginHistory(ctx, userId, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginHistory", reflect.TypeOf((*MockAuthenticationServiceInterface)(nil).GetLoginHistory), ctx, userId, form)
+}
+
+// MockAuditServiceInterface is a mock of AuditServiceInterface interface.
+type MockAuditServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceInterfaceMockRecorder
+}
+
+// MockAuditServiceInterfaceMockRecorder is the mock recorder for MockAuditServiceInterface.
+type MockAuditServiceInterfaceMockRecorder struct {
+	mock *MockAuditServiceInterface
+}
+
+// NewMockAuditServiceInterface creates a new mock instance.
+func NewMockAuditServiceInterface(ctrl *gomock.Controller) *MockAuditServiceInterface {
+	mock := &MockAuditServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditServiceInterface) EXPECT() *MockAuditServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetAuditLogs mocks base method.
+func (m *MockAuditServiceInterface) GetAuditLogs(ctx context.Context, form forms.AuditLogQueryForm) (*AuditLogsResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogs", ctx, form)
+	ret0, _ := ret[0].(*AuditLogsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogs indicates an expected call of GetAuditLogs.
+func (mr *MockAuditServiceInterfaceMockRecorder) GetAuditLogs(ctx, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogs", reflect.TypeOf((*MockAuditServiceInterface)(nil).GetAuditLogs), ctx, form)
+}
+
+// Record mocks base method.
+func (m *MockAuditServiceInterface) Record(ctx context.Context, auditLogRepository repository.AuditLogRepositoryInterface, entry AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, auditLogRepository, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditServiceInterfaceMockRecorder) Record(ctx, auditLogRepository, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditServiceInterface)(nil).Record), ctx, auditLogRepository, entry)
+}
+
+// VerifyChain mocks base method.
+func (m *MockAuditServiceInterface) VerifyChain(ctx context.Context) (*AuditChainVerificationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChain", ctx)
+	ret0, _ := ret[0].(*AuditChainVerificationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyChain indicates an expected call of VerifyChain.
+func (mr *MockAuditServiceInterfaceMockRecorder) VerifyChain(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChain", reflect.TypeOf((*MockAuditServiceInterface)(nil).VerifyChain), ctx)
+}
+
+// MockDataExportServiceInterface is a mock of DataExportServiceInterface interface.
+type MockDataExportServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataExportServiceInterfaceMockRecorder
+}
+
+// MockDataExportServiceInterfaceMockRecorder is the mock recorder for MockDataExportServiceInterface.
+type MockDataExportServiceInterfaceMockRecorder struct {
+	mock *MockDataExportServiceInterface
+}
+
+// NewMockDataExportServiceInterface creates a new mock instance.
+func NewMockDataExportServiceInterface(ctrl *gomock.Controller) *MockDataExportServiceInterface {
+	mock := &MockDataExportServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockDataExportServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataExportServiceInterface) EXPECT() *MockDataExportServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredArchives mocks base method.
+func (m *MockDataExportServiceInterface) DeleteExpiredArchives(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredArchives", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredArchives indicates an expected call of DeleteExpiredArchives.
+func (mr *MockDataExportServiceInterfaceMockRecorder) DeleteExpiredArchives(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredArchives", reflect.TypeOf((*MockDataExportServiceInterface)(nil).DeleteExpiredArchives), ctx)
+}
+
+// FailStaleExports mocks base method.
+func (m *MockDataExportServiceInterface) FailStaleExports(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailStaleExports", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailStaleExports indicates an expected call of FailStaleExports.
+func (mr *MockDataExportServiceInterfaceMockRecorder) FailStaleExports(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailStaleExports", reflect.TypeOf((*MockDataExportServiceInterface)(nil).FailStaleExports), ctx)
+}
+
+// GetExport mocks base method.
+func (m *MockDataExportServiceInterface) GetExport(ctx context.Context, userId, exportId int64) (*pojos.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", ctx, userId, exportId)
+	ret0, _ := ret[0].(*pojos.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockDataExportServiceInterfaceMockRecorder) GetExport(ctx, userId, exportId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockDataExportServiceInterface)(nil).GetExport), ctx, userId, exportId)
+}
+
+// GetExportArchive mocks base method.
+func (m *MockDataExportServiceInterface) GetExportArchive(ctx context.Context, token string) (*DataExportArchiveResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportArchive", ctx, token)
+	ret0, _ := ret[0].(*DataExportArchiveResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExportArchive indicates an expected call of GetExportArchive.
+func (mr *MockDataExportServiceInterfaceMockRecorder) GetExportArchive(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportArchive", reflect.TypeOf((*MockDataExportServiceInterface)(nil).GetExportArchive), ctx, token)
+}
+
+// RequestExport mocks base method.
+func (m *MockDataExportServiceInterface) RequestExport(ctx context.Context, userId int64, form forms.DataExportForm, metadata RequestMetadata) (*DataExportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestExport", ctx, userId, form, metadata)
+	ret0, _ := ret[0].(*DataExportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestExport indicates an expected call of RequestExport.
+func (mr *MockDataExportServiceInterfaceMockRecorder) RequestExport(ctx, userId, form, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestExport", reflect.TypeOf((*MockDataExportServiceInterface)(nil).RequestExport), ctx, userId, form, metadata)
+}
+
+// MockSystemServiceInterface is a mock of SystemServiceInterface interface.
+type MockSystemServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSystemServiceInterfaceMockRecorder
+}
+
+// MockSystemServiceInterfaceMockRecorder is the mock recorder for MockSystemServiceInterface.
+type MockSystemServiceInterfaceMockRecorder struct {
+	mock *MockSystemServiceInterface
+}
+
+// NewMockSystemServiceInterface creates a new mock instance.
+func NewMockSystemServiceInterface(ctrl *gomock.Controller) *MockSystemServiceInterface {
+	mock := &MockSystemServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockSystemServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSystemServiceInterface) EXPECT() *MockSystemServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetDatabasePoolStats mocks base method.
+func (m *MockSystemServiceInterface) GetDatabasePoolStats(ctx context.Context) pojos.DatabasePoolStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDatabasePoolStats", ctx)
+	ret0, _ := ret[0].(pojos.DatabasePoolStats)
+	return ret0
+}
+
+// GetDatabasePoolStats indicates an expected call of GetDatabasePoolStats.
+func (mr *MockSystemServiceInterfaceMockRecorder) GetDatabasePoolStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDatabasePoolStats", reflect.TypeOf((*MockSystemServiceInterface)(nil).GetDatabasePoolStats), ctx)
+}
+
+// MockHealthServiceInterface is a mock of HealthServiceInterface interface.
+type MockHealthServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthServiceInterfaceMockRecorder
+}
+
+// MockHealthServiceInterfaceMockRecorder is the mock recorder for MockHealthServiceInterface.
+type MockHealthServiceInterfaceMockRecorder struct {
+	mock *MockHealthServiceInterface
+}
+
+// NewMockHealthServiceInterface creates a new mock instance.
+func NewMockHealthServiceInterface(ctrl *gomock.Controller) *MockHealthServiceInterface {
+	mock := &MockHealthServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockHealthServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthServiceInterface) EXPECT() *MockHealthServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetLiveness mocks base method.
+func (m *MockHealthServiceInterface) GetLiveness(ctx context.Context) pojos.Health {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLiveness", ctx)
+	ret0, _ := ret[0].(pojos.Health)
+	return ret0
+}
+
+// GetLiveness indicates an expected call of GetLiveness.
+func (mr *MockHealthServiceInterfaceMockRecorder) GetLiveness(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLiveness", reflect.TypeOf((*MockHealthServiceInterface)(nil).GetLiveness), ctx)
+}
+
+// GetReadiness mocks base method.
+func (m *MockHealthServiceInterface) GetReadiness(ctx context.Context) pojos.Health {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReadiness", ctx)
+	ret0, _ := ret[0].(pojos.Health)
+	return ret0
+}
+
+// GetReadiness indicates an expected call of GetReadiness.
+func (mr *MockHealthServiceInterfaceMockRecorder) GetReadiness(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReadiness", reflect.TypeOf((*MockHealthServiceInterface)(nil).GetReadiness), ctx)
+}
+
+// StartShutdown mocks base method.
+func (m *MockHealthServiceInterface) StartShutdown() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StartShutdown")
+}
+
+// StartShutdown indicates an expected call of StartShutdown.
+func (mr *MockHealthServiceInterfaceMockRecorder) StartShutdown() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAuthenticationServiceInterface)(nil).Authorize), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartShutdown", reflect.TypeOf((*MockHealthServiceInterface)(nil).StartShutdown))
 }
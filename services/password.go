@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/SawitProRecruitment/UserService/modules"
+	"time"
+)
+
+// generateHashedPassword hashes in its own span and is timed, bcrypt takes a large part of registering.
+func generateHashedPassword(ctx context.Context, passwordAuth modules.PasswordAuthInterface, password string) (string, error) {
+
+	_, span := tracer.Start(ctx, "PasswordAuth.GenerateHashedPassword")
+	defer span.End()
+
+	defer observePasswordHashDuration(metrics.PasswordOperationGenerate, time.Now())
+
+	return passwordAuth.GenerateHashedPassword(password)
+}
+
+// compareHashedPassword compares in its own span and is timed, a mismatch is an expected outcome and is not marked as an error.
+func compareHashedPassword(ctx context.Context, passwordAuth modules.PasswordAuthInterface, hashedPassword, password string) (bool, error) {
+
+	_, span := tracer.Start(ctx, "PasswordAuth.CompareHashedPassword")
+	defer span.End()
+
+	defer observePasswordHashDuration(metrics.PasswordOperationCompare, time.Now())
+
+	return passwordAuth.CompareHashedPassword(hashedPassword, password)
+}
+
+func observePasswordHashDuration(operation string, start time.Time) {
+	metrics.PasswordHashDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -3,4 +3,8 @@ package services
 type Services struct {
 	Authentication AuthenticationServiceInterface
 	User           UserServiceInterface
+	Audit          AuditServiceInterface
+	DataExport     DataExportServiceInterface
+	System         SystemServiceInterface
+	Health         HealthServiceInterface
 }
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
+)
+
+type SystemService struct {
+	databaseRepository repository.DatabaseRepositoryInterface
+	// userCache is nil when the users are not cached.
+	userCache repository.UserCacheInterface
+}
+
+func (s SystemService) GetDatabasePoolStats(ctx context.Context) pojos.DatabasePoolStats {
+
+	output := s.databaseRepository.GetPoolStats()
+
+	stats := pojos.DatabasePoolStats{
+		MaxOpenConnections:  output.MaxOpenConnections,
+		OpenConnections:     output.OpenConnections,
+		InUse:               output.InUse,
+		Idle:                output.Idle,
+		WaitCount:           output.WaitCount,
+		WaitDurationMs:      output.WaitDuration.Milliseconds(),
+		MaxIdleClosed:       output.MaxIdleClosed,
+		MaxIdleTimeClosed:   output.MaxIdleTimeClosed,
+		MaxLifetimeClosed:   output.MaxLifetimeClosed,
+		CircuitBreakerState: output.CircuitBreakerState,
+	}
+
+	if s.userCache != nil {
+
+		cacheOutput := s.userCache.GetUserCacheStats()
+
+		stats.UserCache = &pojos.UserCacheStats{
+			Hits:      cacheOutput.Hits,
+			Misses:    cacheOutput.Misses,
+			Evictions: cacheOutput.Evictions,
+			Size:      cacheOutput.Size,
+			Capacity:  cacheOutput.Capacity,
+		}
+	}
+
+	return stats
+}
+
+func NewSystemService(databaseRepository repository.DatabaseRepositoryInterface, userCache repository.UserCacheInterface) SystemServiceInterface {
+	return SystemService{
+		databaseRepository: databaseRepository,
+		userCache:          userCache,
+	}
+}
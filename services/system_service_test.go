@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"github.com/SawitProRecruitment/UserService/pojos"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type SystemServiceTestSuite struct {
+	suite.Suite
+
+	databaseRepository *repository.MockDatabaseRepositoryInterface
+	userCache          *repository.MockUserCacheInterface
+
+	MockController *gomock.Controller
+}
+
+func TestSystemServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SystemServiceTestSuite))
+}
+
+func (ts *SystemServiceTestSuite) SetupSuite() {
+
+	mockCtrl := gomock.NewController(ts.T())
+
+	ts.MockController = mockCtrl
+
+	defer mockCtrl.Finish()
+
+	ts.databaseRepository = repository.NewMockDatabaseRepositoryInterface(mockCtrl)
+	ts.userCache = repository.NewMockUserCacheInterface(mockCtrl)
+}
+
+func (ts *SystemServiceTestSuite) TestNewSystemService() {
+	want := SystemService{
+		databaseRepository: ts.databaseRepository,
+		userCache:          ts.userCache,
+	}
+
+	if got := NewSystemService(ts.databaseRepository, ts.userCache); !reflect.DeepEqual(got, want) {
+		ts.T().Errorf("NewSystemService() = %v, want %v", got, want)
+	}
+}
+
+func (ts *SystemServiceTestSuite) TestSystemService_GetDatabasePoolStats() {
+
+	tests := []struct {
+		name      string
+		userCache repository.UserCacheInterface
+		want      pojos.DatabasePoolStats
+		mock      func()
+	}{
+		{
+			name: "When the pool has waited for connections, then it return the stats with the wait duration in milliseconds",
+			want: pojos.DatabasePoolStats{
+				MaxOpenConnections:  25,
+				OpenConnections:     10,
+				InUse:               4,
+				Idle:                6,
+				WaitCount:           3,
+				WaitDurationMs:      1500,
+				MaxIdleClosed:       1,
+				MaxIdleTimeClosed:   2,
+				MaxLifetimeClosed:   5,
+				CircuitBreakerState: repository.CircuitBreakerClosed,
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().GetPoolStats().Return(repository.PoolStatsOutput{
+					MaxOpenConnections:  25,
+					OpenConnections:     10,
+					InUse:               4,
+					Idle:                6,
+					WaitCount:           3,
+					WaitDuration:        1500 * time.Millisecond,
+					MaxIdleClosed:       1,
+					MaxIdleTimeClosed:   2,
+					MaxLifetimeClosed:   5,
+					CircuitBreakerState: repository.CircuitBreakerClosed,
+				})
+			},
+		},
+		{
+			name:      "When the users are cached, then it return the stats with the cache stats",
+			userCache: ts.userCache,
+			want: pojos.DatabasePoolStats{
+				MaxOpenConnections:  25,
+				CircuitBreakerState: repository.CircuitBreakerClosed,
+				UserCache: &pojos.UserCacheStats{
+					Hits:      90,
+					Misses:    10,
+					Evictions: 2,
+					Size:      8,
+					Capacity:  8,
+				},
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().GetPoolStats().Return(repository.PoolStatsOutput{
+					MaxOpenConnections:  25,
+					CircuitBreakerState: repository.CircuitBreakerClosed,
+				})
+				ts.userCache.EXPECT().GetUserCacheStats().Return(repository.UserCacheStatsOutput{
+					Hits:      90,
+					Misses:    10,
+					Evictions: 2,
+					Size:      8,
+					Capacity:  8,
+				})
+			},
+		},
+		{
+			name: "When the circuit breaker is open, then it return the stats with the open state",
+			want: pojos.DatabasePoolStats{
+				MaxOpenConnections:  25,
+				OpenConnections:     0,
+				CircuitBreakerState: repository.CircuitBreakerOpen,
+			},
+			mock: func() {
+				ts.databaseRepository.EXPECT().GetPoolStats().Return(repository.PoolStatsOutput{
+					MaxOpenConnections:  25,
+					OpenConnections:     0,
+					CircuitBreakerState: repository.CircuitBreakerOpen,
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			s := SystemService{
+				databaseRepository: ts.databaseRepository,
+				userCache:          tt.userCache,
+			}
+			if got := s.GetDatabasePoolStats(context.Background()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetDatabasePoolStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
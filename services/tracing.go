@@ -0,0 +1,8 @@
+package services
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+// tracer uses the global tracer provider, so the spans go wherever cmd configures them to go, or nowhere.
+var tracer = otel.Tracer("github.com/SawitProRecruitment/UserService/services")
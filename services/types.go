@@ -4,16 +4,49 @@ import (
 	"github.com/SawitProRecruitment/UserService/pojos"
 )
 
+// RequestMetadata describes the client a request came from.
+type RequestMetadata struct {
+	RequestId string
+	IpAddress string
+	UserAgent string
+}
+
+// AuditEntry is a single change to be appended to the audit log.
+// ActorUserId is who made the change, SubjectUserId is whose account changed.
+type AuditEntry struct {
+	ActorUserId   *int64
+	SubjectUserId *int64
+	Action        string
+	Changes       map[string]pojos.AuditChange
+	Metadata      RequestMetadata
+}
+
 type UpdateResult struct {
-	User                pojos.User
-	HasValidationErrors bool
-	ValidationErrors    map[string]string
+	User pojos.User
+	// IsPhoneNumberConflict is set when the new phone number is already used by another user.
+	IsPhoneNumberConflict bool
+	HasValidationErrors   bool
+	ValidationErrors      map[string]string
 }
 
 type RegisterResult struct {
+	User pojos.User
+	// IsPhoneNumberConflict is set when the phone number is already used by another user.
+	IsPhoneNumberConflict bool
+	ValidationErrors      map[string]string
+	HasValidationErrors   bool
+}
+
+type ChangeEmailResult struct {
 	User                pojos.User
+	HasValidationErrors bool
 	ValidationErrors    map[string]string
+}
+
+type VerifyEmailResult struct {
+	IsVerified          bool
 	HasValidationErrors bool
+	ValidationErrors    map[string]string
 }
 
 type AuthenticationCredential struct {
@@ -24,6 +57,9 @@ type AuthenticationCredential struct {
 type AuthenticationResult struct {
 	IsSuccess      bool
 	IsUserNotFound bool
+	// IsAccountInactive is set when the password is correct but the account status does not allow logging in.
+	IsAccountInactive bool
+	AccountStatus     string
 
 	HasValidationErrors bool
 	ValidationErrors    map[string]string
@@ -32,5 +68,66 @@ type AuthenticationResult struct {
 
 type AuthorizationResult struct {
 	IsAuthorized bool
-	UserId       int64
+	// IsInvalidToken is set when the token is malformed, expired or not signed by our keys.
+	IsInvalidToken bool
+	UserId         int64
+}
+
+type LoginHistoryResult struct {
+	LoginHistory        pojos.LoginHistory
+	HasValidationErrors bool
+	ValidationErrors    map[string]string
+}
+
+type AuditLogsResult struct {
+	AuditLogs           pojos.AuditLogs
+	HasValidationErrors bool
+	ValidationErrors    map[string]string
+}
+
+type AuditChainVerificationResult struct {
+	IsValid      bool
+	CheckedCount int64
+	BrokenAtId   *int64
+}
+
+type ChangeStatusResult struct {
+	User                   pojos.User
+	IsUserNotFound         bool
+	IsTransitionNotAllowed bool
+	HasValidationErrors    bool
+	ValidationErrors       map[string]string
+}
+
+type DeleteAccountResult struct {
+	User                pojos.User
+	IsInvalidPassword   bool
+	HasValidationErrors bool
+	ValidationErrors    map[string]string
+}
+
+type ChangeRoleResult struct {
+	User                pojos.User
+	IsUserNotFound      bool
+	HasValidationErrors bool
+	ValidationErrors    map[string]string
+}
+
+type DataExportResult struct {
+	DataExport          pojos.DataExport
+	HasValidationErrors bool
+	ValidationErrors    map[string]string
+	// IsExportInProgress is set when the user's previous export is still being built.
+	IsExportInProgress bool
+}
+
+// DataExportArchiveResult is the downloadable archive of a data export.
+// Content is only set when none of the Is* flags is set.
+type DataExportArchiveResult struct {
+	IsNotFound  bool
+	IsExpired   bool
+	IsNotReady  bool
+	IsEncrypted bool
+	FileName    string
+	Content     []byte
 }
@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"github.com/SawitProRecruitment/UserService/consts"
 	"github.com/SawitProRecruitment/UserService/forms"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/metrics"
 	"github.com/SawitProRecruitment/UserService/modules"
 	"github.com/SawitProRecruitment/UserService/pojos"
 	"github.com/SawitProRecruitment/UserService/repository"
 	"github.com/SawitProRecruitment/UserService/utils"
 	"github.com/SawitProRecruitment/UserService/validators"
 	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"time"
 )
 
 const GenerateHashedPasswordCost int = 10
@@ -19,11 +23,19 @@ const GenerateHashedPasswordCost int = 10
 type UserService struct {
 	repository   repository.UserRepositoryInterface
 	passwordAuth modules.PasswordAuthInterface
+	mailSender   modules.MailSenderInterface
+	auditService AuditServiceInterface
+	// deletionGracePeriod is how long a deleted account can still be restored by logging in.
+	deletionGracePeriod  time.Duration
+	emailVerificationUrl string
+	// runAsync sends the verification mails after the request has been answered.
+	runAsync func(task func())
 }
 
-func (u UserService) Register(form forms.UserRegisterForm) (*RegisterResult, error) {
+func (u UserService) Register(ctx context.Context, form forms.UserRegisterForm, metadata RequestMetadata) (*RegisterResult, error) {
 
-	ctx := context.Background()
+	ctx, span := tracer.Start(ctx, "UserService.Register")
+	defer span.End()
 
 	result := &RegisterResult{
 		ValidationErrors:    nil,
@@ -56,70 +68,101 @@ func (u UserService) Register(form forms.UserRegisterForm) (*RegisterResult, err
 		result.HasValidationErrors = true
 		result.ValidationErrors = validationErrorMessages
 
-		return result, nil
-	}
+		metrics.Registrations.WithLabelValues(metrics.RegistrationResultValidationError).Inc()
 
-	getByPhoneNumberInput := repository.GetUserByPhoneNumberInput{
-		PhoneNumber: form.PhoneNumber,
+		return result, nil
 	}
 
-	existedUser, err := u.repository.GetByPhoneNumberIncludePassword(ctx, getByPhoneNumberInput)
+	hashedPassword, err := generateHashedPassword(ctx, u.passwordAuth, form.Password)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if existedUser != nil {
-		result.HasValidationErrors = true
-		result.ValidationErrors = map[string]string{
-			"phone_number": fmt.Sprintf("Phone number %s is unavailable for registering new user", existedUser.PhoneNumber),
+	insertUserInput := repository.InsertUserInput{
+		PhoneNumber: form.PhoneNumber,
+		FullName:    form.FullName,
+		Email:       form.Email,
+		Password:    string(hashedPassword),
+	}
+
+	var registeredUser *repository.GetUserByIdOutput
+
+	// The unique constraints of the database decide whether the phone number and email are available,
+	// a check before the insert could be passed by two concurrent registrations.
+	err = u.repository.WithinTransaction(ctx, repository.TransactionOptions{
+		IsolationLevel: repository.IsolationLevelReadCommitted,
+	}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+		output, err := txRepository.Insert(ctx, insertUserInput)
+
+		if err != nil {
+			return err
 		}
 
-		return result, nil
-	}
+		getUserByIdInput := repository.GetUserByIdInput{
+			Id: output.Id,
+		}
 
-	hashedPassword, err := u.passwordAuth.GenerateHashedPassword(form.Password)
+		registeredUser, err = txRepository.GetById(ctx, getUserByIdInput)
 
-	if err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return err
+		}
 
-	hashedPasswordString := string(hashedPassword)
+		if registeredUser == nil {
+			return errors.New("Unexpected error. After insert return nil user.")
+		}
 
-	insertUserInput := repository.InsertUserInput{
-		PhoneNumber: form.PhoneNumber,
-		FullName:    form.FullName,
-		Password:    hashedPasswordString,
-	}
+		return u.auditService.Record(ctx, txRepository, AuditEntry{
+			ActorUserId:   &registeredUser.Id,
+			SubjectUserId: &registeredUser.Id,
+			Action:        consts.AuditActionRegister,
+			Changes:       buildUserChanges(pojos.User{}, u.buildRegisterUserResponse(*registeredUser)),
+			Metadata:      metadata,
+		})
+	})
 
-	output, err := u.repository.Insert(ctx, insertUserInput)
+	if repository.IsUniqueViolation(err, repository.UniqueFieldPhoneNumber) {
+		result.IsPhoneNumberConflict = true
 
-	if err != nil {
-		return nil, err
-	}
+		metrics.Registrations.WithLabelValues(metrics.RegistrationResultPhoneNumberConflict).Inc()
 
-	getUserByIdInput := repository.GetUserByIdInput{
-		Id: output.Id,
+		return result, nil
 	}
 
-	getUserOutPut, err := u.repository.GetById(ctx, getUserByIdInput)
+	if repository.IsUniqueViolation(err, repository.UniqueFieldEmail) {
+		result.HasValidationErrors = true
+		result.ValidationErrors = map[string]string{
+			"email": fmt.Sprintf("Email %s is unavailable for registering new user", form.Email),
+		}
+
+		metrics.Registrations.WithLabelValues(metrics.RegistrationResultEmailConflict).Inc()
+
+		return result, nil
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	if getUserOutPut == nil {
-		return nil, errors.New("Unexpected error. After insert return nil user.")
+	metrics.Registrations.WithLabelValues(metrics.ResultSuccess).Inc()
+
+	if utils.StringIsEmpty(form.Email) == false {
+
+		u.sendEmailVerificationInBackground(ctx, registeredUser.Id, form.Email)
 	}
 
-	result.User = u.buildRegisterUserResponse(*getUserOutPut)
+	result.User = u.buildRegisterUserResponse(*registeredUser)
 
 	return result, nil
 }
 
-func (u UserService) Update(userId int64, form forms.UserUpdateForm) (*UpdateResult, error) {
+func (u UserService) Update(ctx context.Context, userId int64, form forms.UserUpdateForm, metadata RequestMetadata) (*UpdateResult, error) {
+
+	ctx, span := tracer.Start(ctx, "UserService.Update")
+	defer span.End()
 
-	ctx := context.Background()
 	result := UpdateResult{}
 
 	if form.PhoneNumber == "" && form.FullName == "" {
@@ -149,7 +192,7 @@ func (u UserService) Update(userId int64, form forms.UserUpdateForm) (*UpdateRes
 		return &result, nil
 	}
 
-	user, err := u.GetById(userId)
+	user, err := u.GetById(ctx, userId)
 
 	if err != nil {
 		return nil, err
@@ -159,39 +202,61 @@ func (u UserService) Update(userId int64, form forms.UserUpdateForm) (*UpdateRes
 		return nil, errors.New("User not found")
 	}
 
+	userBeforeUpdate := *user
+
+	updateUserInput := repository.UpdateUserInput{
+		Id:          user.Id,
+		PhoneNumber: form.PhoneNumber,
+		FullName:    form.FullName,
+	}
+
 	if utils.StringIsEmpty(form.PhoneNumber) == false {
-		user.PhoneNumber = form.PhoneNumber
+		updateUserInput.Fields = append(updateUserInput.Fields, repository.UserFieldPhoneNumber)
 	}
 
 	if utils.StringIsEmpty(form.FullName) == false {
-		user.FullName = form.FullName
+		updateUserInput.Fields = append(updateUserInput.Fields, repository.UserFieldFullName)
 	}
 
-	updateUserInput := repository.UpdateUserInput{
-		Id:                user.Id,
-		PhoneNumber:       user.PhoneNumber,
-		FullName:          user.FullName,
-		LoginSuccessCount: user.LoginSuccessCount,
-	}
+	err = u.repository.WithinTransaction(ctx, repository.TransactionOptions{}, func(txRepository repository.TransactionRepositoryInterface) error {
 
-	updateOutput, err := u.repository.Update(ctx, updateUserInput)
+		updateOutput, err := txRepository.Update(ctx, updateUserInput)
 
-	if err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return err
+		}
 
-	if updateOutput.IsSuccessUpdate == false {
-		return nil, errors.New("Failed to update the record")
-	}
+		if updateOutput.IsSuccessUpdate == false {
+			return errors.New("Failed to update the record")
+		}
 
-	user, err = u.GetById(userId)
+		user, err = getUserById(ctx, txRepository, userId)
 
-	if err != nil {
-		return nil, err
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return errors.New("User not found")
+		}
+
+		return u.auditService.Record(ctx, txRepository, AuditEntry{
+			ActorUserId:   &userId,
+			SubjectUserId: &userId,
+			Action:        consts.AuditActionProfileUpdate,
+			Changes:       buildUserChanges(userBeforeUpdate, *user),
+			Metadata:      metadata,
+		})
+	})
+
+	if repository.IsUniqueViolation(err, repository.UniqueFieldPhoneNumber) {
+		result.IsPhoneNumberConflict = true
+
+		return &result, nil
 	}
 
-	if user == nil {
-		return nil, errors.New("User not found")
+	if err != nil {
+		return nil, err
 	}
 
 	result.HasValidationErrors = false
@@ -201,15 +266,22 @@ func (u UserService) Update(userId int64, form forms.UserUpdateForm) (*UpdateRes
 	return &result, nil
 }
 
-func (u UserService) GetById(userId int64) (*pojos.User, error) {
+func (u UserService) GetById(ctx context.Context, userId int64) (*pojos.User, error) {
+
+	ctx, span := tracer.Start(ctx, "UserService.GetById")
+	defer span.End()
+
+	return getUserById(ctx, u.repository, userId)
+}
 
-	ctx := context.Background()
+// getUserById reads the user with userRepository, which is the repository of the transaction when called in one.
+func getUserById(ctx context.Context, userRepository repository.UserRepositoryInterface, userId int64) (*pojos.User, error) {
 
 	getUserByIdInput := repository.GetUserByIdInput{
 		Id: userId,
 	}
 
-	output, err := u.repository.GetById(ctx, getUserByIdInput)
+	output, err := userRepository.GetById(ctx, getUserByIdInput)
 
 	if err != nil {
 		return nil, err
@@ -220,12 +292,19 @@ func (u UserService) GetById(userId int64) (*pojos.User, error) {
 	}
 
 	user := &pojos.User{
-		Id:                output.Id,
-		PhoneNumber:       output.PhoneNumber,
-		FullName:          output.FullName,
-		LoginSuccessCount: output.LoginSuccessCount,
-		CreatedAt:         output.CreatedAt,
-		UpdatedAt:         output.UpdatedAt,
+		Id:                  output.Id,
+		PhoneNumber:         output.PhoneNumber,
+		FullName:            output.FullName,
+		Email:               output.Email,
+		EmailVerifiedAt:     output.EmailVerifiedAt,
+		Role:                output.Role,
+		Status:              output.Status,
+		StatusReason:        output.StatusReason,
+		StatusChangedAt:     output.StatusChangedAt,
+		DeletionScheduledAt: output.DeletionScheduledAt,
+		LoginSuccessCount:   output.LoginSuccessCount,
+		CreatedAt:           output.CreatedAt,
+		UpdatedAt:           output.UpdatedAt,
 	}
 
 	return user, nil
@@ -234,18 +313,26 @@ func (u UserService) GetById(userId int64) (*pojos.User, error) {
 func (u UserService) buildRegisterUserResponse(output repository.GetUserByIdOutput) pojos.User {
 
 	return pojos.User{
-		Id:                output.Id,
-		PhoneNumber:       output.PhoneNumber,
-		FullName:          output.FullName,
-		LoginSuccessCount: output.LoginSuccessCount,
-		CreatedAt:         output.CreatedAt,
-		UpdatedAt:         output.UpdatedAt,
+		Id:                  output.Id,
+		PhoneNumber:         output.PhoneNumber,
+		FullName:            output.FullName,
+		Email:               output.Email,
+		EmailVerifiedAt:     output.EmailVerifiedAt,
+		Role:                output.Role,
+		Status:              output.Status,
+		StatusReason:        output.StatusReason,
+		StatusChangedAt:     output.StatusChangedAt,
+		DeletionScheduledAt: output.DeletionScheduledAt,
+		LoginSuccessCount:   output.LoginSuccessCount,
+		CreatedAt:           output.CreatedAt,
+		UpdatedAt:           output.UpdatedAt,
 	}
 }
 
-func (u UserService) GetByPhoneNumber(phoneNumber string) (*pojos.User, error) {
+func (u UserService) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*pojos.User, error) {
 
-	ctx := context.Background()
+	ctx, span := tracer.Start(ctx, "UserService.GetByPhoneNumber")
+	defer span.End()
 
 	getByPhoneNumberInput := repository.GetUserByPhoneNumberInput{
 		PhoneNumber: phoneNumber,
@@ -262,21 +349,711 @@ func (u UserService) GetByPhoneNumber(phoneNumber string) (*pojos.User, error) {
 	}
 
 	user := &pojos.User{
-		Id:                output.Id,
-		PhoneNumber:       output.PhoneNumber,
-		FullName:          output.FullName,
-		LoginSuccessCount: output.LoginSuccessCount,
-		CreatedAt:         output.CreatedAt,
-		UpdatedAt:         output.UpdatedAt,
+		Id:                  output.Id,
+		PhoneNumber:         output.PhoneNumber,
+		FullName:            output.FullName,
+		Email:               output.Email,
+		EmailVerifiedAt:     output.EmailVerifiedAt,
+		Role:                output.Role,
+		Status:              output.Status,
+		StatusReason:        output.StatusReason,
+		StatusChangedAt:     output.StatusChangedAt,
+		DeletionScheduledAt: output.DeletionScheduledAt,
+		LoginSuccessCount:   output.LoginSuccessCount,
+		CreatedAt:           output.CreatedAt,
+		UpdatedAt:           output.UpdatedAt,
 	}
 
 	return user, nil
 }
 
-func NewUserService(repository repository.UserRepositoryInterface, passwordAuth modules.PasswordAuthInterface) UserServiceInterface {
+// ChangeEmail sets a new, unverified email for the user and sends a verification mail to it.
+// Submitting the current email again resends the verification.
+func (u UserService) ChangeEmail(ctx context.Context, userId int64, form forms.UserChangeEmailForm, metadata RequestMetadata) (*ChangeEmailResult, error) {
+
+	ctx, span := tracer.Start(ctx, "UserService.ChangeEmail")
+	defer span.End()
+
+	result := ChangeEmailResult{}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err := validate.Struct(form)
+
+	if err != nil {
+
+		var validationErrors validator.ValidationErrors
+
+		errors.As(err, &validationErrors)
+
+		validationErrorMessages := utils.CollectValidationErrorMessages(form, validationErrors)
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = validationErrorMessages
+
+		return &result, nil
+	}
+
+	existedEmailUser, err := u.repository.GetByEmailIncludePassword(ctx, repository.GetUserByEmailInput{
+		Email: form.Email,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existedEmailUser != nil && existedEmailUser.Id != userId {
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = map[string]string{
+			"email": fmt.Sprintf("Email %s is unavailable", form.Email),
+		}
+
+		return &result, nil
+	}
+
+	if existedEmailUser != nil && existedEmailUser.EmailVerifiedAt != nil {
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = map[string]string{
+			"email": fmt.Sprintf("Email %s is already verified", form.Email),
+		}
+
+		return &result, nil
+	}
+
+	userBeforeUpdate, err := u.GetById(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if userBeforeUpdate == nil {
+		return nil, errors.New("User not found")
+	}
+
+	var user *pojos.User
+
+	err = u.repository.WithinTransaction(ctx, repository.TransactionOptions{}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+		updateOutput, err := txRepository.UpdateEmail(ctx, repository.UpdateUserEmailInput{
+			Id:    userId,
+			Email: form.Email,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if updateOutput.IsSuccessUpdate == false {
+			return errors.New("Failed to update the record")
+		}
+
+		user, err = getUserById(ctx, txRepository, userId)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return errors.New("User not found")
+		}
+
+		return u.auditService.Record(ctx, txRepository, AuditEntry{
+			ActorUserId:   &userId,
+			SubjectUserId: &userId,
+			Action:        consts.AuditActionEmailChange,
+			Changes:       buildUserChanges(*userBeforeUpdate, *user),
+			Metadata:      metadata,
+		})
+	})
+
+	// Another user took the email after the check above.
+	if repository.IsUniqueViolation(err, repository.UniqueFieldEmail) {
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = map[string]string{
+			"email": fmt.Sprintf("Email %s is unavailable", form.Email),
+		}
+
+		return &result, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	u.sendEmailVerificationInBackground(ctx, userId, form.Email)
+
+	result.HasValidationErrors = false
+	result.ValidationErrors = map[string]string{}
+	result.User = *user
+
+	return &result, nil
+}
+
+func (u UserService) VerifyEmail(ctx context.Context, form forms.VerifyEmailForm, metadata RequestMetadata) (*VerifyEmailResult, error) {
+
+	ctx, span := tracer.Start(ctx, "UserService.VerifyEmail")
+	defer span.End()
+
+	result := VerifyEmailResult{}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err := validate.Struct(form)
+
+	if err != nil {
+
+		var validationErrors validator.ValidationErrors
+
+		errors.As(err, &validationErrors)
+
+		validationErrorMessages := utils.CollectValidationErrorMessages(form, validationErrors)
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = validationErrorMessages
+
+		return &result, nil
+	}
+
+	invalidTokenResult := &VerifyEmailResult{
+		IsVerified:          false,
+		HasValidationErrors: true,
+		ValidationErrors: map[string]string{
+			"token": "Verification token is invalid or expired",
+		},
+	}
+
+	verification, err := u.repository.GetEmailVerificationByTokenHash(ctx, repository.GetEmailVerificationByTokenHashInput{
+		TokenHash: utils.HashToken(form.Token),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if verification == nil || verification.VerifiedAt != nil || time.Now().After(verification.ExpiredAt) {
+		return invalidTokenResult, nil
+	}
+
+	isVerified := false
+
+	err = u.repository.WithinTransaction(ctx, repository.TransactionOptions{}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+		verifyOutput, err := txRepository.VerifyEmail(ctx, repository.VerifyEmailInput{
+			EmailVerificationId: verification.Id,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		isVerified = verifyOutput.IsVerified
+
+		if isVerified == false {
+			return nil
+		}
+
+		return u.auditService.Record(ctx, txRepository, AuditEntry{
+			ActorUserId:   &verification.UserId,
+			SubjectUserId: &verification.UserId,
+			Action:        consts.AuditActionEmailVerify,
+			Changes: map[string]pojos.AuditChange{
+				"email_verified": {Before: false, After: true},
+			},
+			Metadata: metadata,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if isVerified == false {
+		return invalidTokenResult, nil
+	}
+
+	result.IsVerified = true
+	result.HasValidationErrors = false
+	result.ValidationErrors = map[string]string{}
+
+	return &result, nil
+}
+
+// ChangeRole lets an admin (the actor) grant or revoke a role of another user.
+func (u UserService) ChangeRole(ctx context.Context, actorUserId int64, userId int64, form forms.UserChangeRoleForm, metadata RequestMetadata) (*ChangeRoleResult, error) {
+
+	ctx, span := tracer.Start(ctx, "UserService.ChangeRole")
+	defer span.End()
+
+	result := ChangeRoleResult{}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err := validate.Struct(form)
+
+	if err != nil {
+
+		var validationErrors validator.ValidationErrors
+
+		errors.As(err, &validationErrors)
+
+		validationErrorMessages := utils.CollectValidationErrorMessages(form, validationErrors)
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = validationErrorMessages
+
+		return &result, nil
+	}
+
+	userBeforeUpdate, err := u.GetById(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if userBeforeUpdate == nil {
+		result.IsUserNotFound = true
+
+		return &result, nil
+	}
+
+	var user *pojos.User
+
+	err = u.repository.WithinTransaction(ctx, repository.TransactionOptions{}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+		updateOutput, err := txRepository.UpdateRole(ctx, repository.UpdateUserRoleInput{
+			Id:   userId,
+			Role: form.Role,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if updateOutput.IsSuccessUpdate == false {
+			return errors.New("Failed to update the record")
+		}
+
+		user, err = getUserById(ctx, txRepository, userId)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return errors.New("User not found")
+		}
+
+		return u.auditService.Record(ctx, txRepository, AuditEntry{
+			ActorUserId:   &actorUserId,
+			SubjectUserId: &userId,
+			Action:        consts.AuditActionRoleChange,
+			Changes:       buildUserChanges(*userBeforeUpdate, *user),
+			Metadata:      metadata,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.HasValidationErrors = false
+	result.ValidationErrors = map[string]string{}
+	result.User = *user
+
+	return &result, nil
+}
+
+// ChangeStatus lets an admin (the actor) move a user to another account status.
+// Only the transitions listed in consts.AccountStatusTransitions are allowed. A deleted user is anonymized at once.
+func (u UserService) ChangeStatus(ctx context.Context, actorUserId int64, userId int64, form forms.UserChangeStatusForm, metadata RequestMetadata) (*ChangeStatusResult, error) {
+
+	ctx, span := tracer.Start(ctx, "UserService.ChangeStatus")
+	defer span.End()
+
+	result := ChangeStatusResult{}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err := validate.Struct(form)
+
+	if err != nil {
+
+		var validationErrors validator.ValidationErrors
+
+		errors.As(err, &validationErrors)
+
+		validationErrorMessages := utils.CollectValidationErrorMessages(form, validationErrors)
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = validationErrorMessages
+
+		return &result, nil
+	}
+
+	userBeforeUpdate, err := u.GetById(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if userBeforeUpdate == nil {
+		result.IsUserNotFound = true
+
+		return &result, nil
+	}
+
+	if isAccountStatusTransitionAllowed(userBeforeUpdate.Status, form.Status) == false {
+		result.IsTransitionNotAllowed = true
+		result.User = *userBeforeUpdate
+
+		return &result, nil
+	}
+
+	var user *pojos.User
+
+	err = u.repository.WithinTransaction(ctx, repository.TransactionOptions{}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+		isChanged, err := changeAccountStatus(ctx, txRepository, userId, userBeforeUpdate.Status, form)
+
+		if err != nil {
+			return err
+		}
+
+		// The status was changed by someone else in the meantime.
+		if isChanged == false {
+			return nil
+		}
+
+		user, err = getUserById(ctx, txRepository, userId)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return errors.New("User not found")
+		}
+
+		changes := buildUserChanges(*userBeforeUpdate, *user)
+
+		// The anonymized personal fields are left out of the changes, their personal data would be deleted right away.
+		if form.Status == consts.AccountStatusDeleted {
+			changes = map[string]pojos.AuditChange{
+				"status":        {Before: userBeforeUpdate.Status, After: consts.AccountStatusDeleted},
+				"status_reason": {Before: userBeforeUpdate.StatusReason, After: form.Reason},
+			}
+		}
+
+		return u.auditService.Record(ctx, txRepository, AuditEntry{
+			ActorUserId:   &actorUserId,
+			SubjectUserId: &userId,
+			Action:        consts.AuditActionStatusChange,
+			Changes:       changes,
+			Metadata:      metadata,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		result.IsTransitionNotAllowed = true
+		result.User = *userBeforeUpdate
+
+		return &result, nil
+	}
+
+	result.HasValidationErrors = false
+	result.ValidationErrors = map[string]string{}
+	result.User = *user
+
+	return &result, nil
+}
+
+// RequestDeletion schedules the deletion of the user's own account after the password is re-entered.
+// Until ACCOUNT_DELETION_GRACE_PERIOD is over, logging in again cancels the deletion.
+func (u UserService) RequestDeletion(ctx context.Context, userId int64, form forms.UserDeleteAccountForm, metadata RequestMetadata) (*DeleteAccountResult, error) {
+
+	ctx, span := tracer.Start(ctx, "UserService.RequestDeletion")
+	defer span.End()
+
+	result := DeleteAccountResult{}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	err := validate.Struct(form)
+
+	if err != nil {
+
+		var validationErrors validator.ValidationErrors
+
+		errors.As(err, &validationErrors)
+
+		validationErrorMessages := utils.CollectValidationErrorMessages(form, validationErrors)
+
+		result.HasValidationErrors = true
+		result.ValidationErrors = validationErrorMessages
+
+		return &result, nil
+	}
+
+	userBeforeUpdate, err := u.GetById(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if userBeforeUpdate == nil {
+		return nil, errors.New("User not found")
+	}
+
+	userWithPassword, err := u.repository.GetByPhoneNumberIncludePassword(ctx, repository.GetUserByPhoneNumberInput{
+		PhoneNumber: userBeforeUpdate.PhoneNumber,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if userWithPassword == nil {
+		return nil, errors.New("User not found")
+	}
+
+	_, err = compareHashedPassword(ctx, u.passwordAuth, userWithPassword.Password, form.Password)
+
+	if err != nil {
+		result.IsInvalidPassword = true
+
+		return &result, nil
+	}
+
+	var user *pojos.User
+
+	err = u.repository.WithinTransaction(ctx, repository.TransactionOptions{}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+		updateOutput, err := txRepository.ScheduleDeletion(ctx, repository.ScheduleUserDeletionInput{
+			Id:          userId,
+			Reason:      consts.AccountDeletionRequestReason,
+			GracePeriod: u.deletionGracePeriod,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if updateOutput.IsSuccessUpdate == false {
+			return errors.New("Failed to update the record")
+		}
+
+		user, err = getUserById(ctx, txRepository, userId)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return errors.New("User not found")
+		}
+
+		return u.auditService.Record(ctx, txRepository, AuditEntry{
+			ActorUserId:   &userId,
+			SubjectUserId: &userId,
+			Action:        consts.AuditActionDeletionRequest,
+			Changes:       buildUserChanges(*userBeforeUpdate, *user),
+			Metadata:      metadata,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.HasValidationErrors = false
+	result.ValidationErrors = map[string]string{}
+	result.User = *user
+
+	return &result, nil
+}
+
+// AnonymizeDueAccounts anonymizes every account whose deletion grace period is over
+// and returns how many were anonymized. It is run periodically by a background job.
+func (u UserService) AnonymizeDueAccounts(ctx context.Context) (int, error) {
+
+	ctx, span := tracer.Start(ctx, "UserService.AnonymizeDueAccounts")
+	defer span.End()
+
+	anonymizedCount := 0
+
+	for {
+
+		dueOutput, err := u.repository.GetUsersDueForDeletion(ctx, repository.GetUsersDueForDeletionInput{
+			Limit: consts.AccountDeletionBatchSize,
+		})
+
+		if err != nil {
+			return anonymizedCount, err
+		}
+
+		if len(dueOutput.UserIds) == 0 {
+			return anonymizedCount, nil
+		}
+
+		batchAnonymizedCount := 0
+
+		for _, userId := range dueOutput.UserIds {
+
+			isAnonymized := false
+
+			err = u.repository.WithinTransaction(ctx, repository.TransactionOptions{}, func(txRepository repository.TransactionRepositoryInterface) error {
+
+				anonymizeOutput, err := txRepository.AnonymizeUser(ctx, repository.AnonymizeUserInput{
+					Id:         userId,
+					FromStatus: consts.AccountStatusPendingDeletion,
+					FullName:   consts.AnonymizedUserFullName,
+					Reason:     consts.AccountDeletionDoneReason,
+				})
+
+				if err != nil {
+					return err
+				}
+
+				isAnonymized = anonymizeOutput.IsAnonymized
+
+				// Cancelled by a login in the meantime.
+				if isAnonymized == false {
+					return nil
+				}
+
+				subjectUserId := userId
+
+				// The anonymized personal fields are left out of the changes, their personal data would be deleted right away.
+				return u.auditService.Record(ctx, txRepository, AuditEntry{
+					SubjectUserId: &subjectUserId,
+					Action:        consts.AuditActionDelete,
+					Changes: map[string]pojos.AuditChange{
+						"status": {Before: consts.AccountStatusPendingDeletion, After: consts.AccountStatusDeleted},
+					},
+				})
+			})
+
+			if err != nil {
+				return anonymizedCount, err
+			}
+
+			if isAnonymized {
+				anonymizedCount += 1
+				batchAnonymizedCount += 1
+			}
+		}
+
+		if len(dueOutput.UserIds) < consts.AccountDeletionBatchSize || batchAnonymizedCount == 0 {
+			return anonymizedCount, nil
+		}
+	}
+}
+
+// changeAccountStatus moves the user from fromStatus to the status of form and reports whether it did. Deleting a user
+// anonymizes it like the deletion job does, the export cleanup then deletes its archives.
+func changeAccountStatus(ctx context.Context, userRepository repository.UserRepositoryInterface, userId int64, fromStatus string,
+	form forms.UserChangeStatusForm) (bool, error) {
+
+	if form.Status == consts.AccountStatusDeleted {
+
+		anonymizeOutput, err := userRepository.AnonymizeUser(ctx, repository.AnonymizeUserInput{
+			Id:         userId,
+			FromStatus: fromStatus,
+			FullName:   consts.AnonymizedUserFullName,
+			Reason:     form.Reason,
+		})
+
+		if err != nil {
+			return false, err
+		}
+
+		return anonymizeOutput.IsAnonymized, nil
+	}
+
+	updateOutput, err := userRepository.UpdateStatus(ctx, repository.UpdateUserStatusInput{
+		Id:         userId,
+		FromStatus: fromStatus,
+		Status:     form.Status,
+		Reason:     form.Reason,
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return updateOutput.IsSuccessUpdate, nil
+}
+
+func isAccountStatusTransitionAllowed(fromStatus string, toStatus string) bool {
+
+	for _, allowedStatus := range consts.AccountStatusTransitions[fromStatus] {
+		if allowedStatus == toStatus {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sendEmailVerificationInBackground sends the verification mail without holding up the request. A failure is only
+// logged, the user gets another mail by submitting the email again.
+func (u UserService) sendEmailVerificationInBackground(ctx context.Context, userId int64, email string) {
+
+	// The mail is sent after the response, so it must not be cancelled with the request.
+	ctx = context.WithoutCancel(ctx)
+
+	u.runAsync(func() {
+
+		err := u.sendEmailVerification(ctx, userId, email)
+
+		if err != nil {
+			slog.ErrorContext(ctx, "email verification mail failed", slog.Int64("user_id", userId), slog.Any("error", err),
+				logging.Stack(0))
+		}
+	})
+}
+
+func (u UserService) sendEmailVerification(ctx context.Context, userId int64, email string) error {
+
+	token, err := utils.GenerateRandomToken(consts.EmailVerificationTokenByteLength)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = u.repository.InsertEmailVerification(ctx, repository.InsertEmailVerificationInput{
+		UserId:    userId,
+		Email:     email,
+		TokenHash: utils.HashToken(token),
+		ExpiredAt: time.Now().Add(consts.EmailVerificationExpiration),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return u.mailSender.Send(modules.MailMessage{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Open the following link to verify your email address:\n\n%s?token=%s\n\n"+
+			"Or enter this verification code in the app:\n\n%s\n\nThe link and code expire in %s.\n",
+			u.emailVerificationUrl, token, token, consts.EmailVerificationExpiration),
+	})
+}
+
+func NewUserService(repository repository.UserRepositoryInterface, passwordAuth modules.PasswordAuthInterface,
+	mailSender modules.MailSenderInterface, auditService AuditServiceInterface, deletionGracePeriod time.Duration,
+	emailVerificationUrl string) UserServiceInterface {
 
 	return UserService{
-		repository:   repository,
-		passwordAuth: passwordAuth,
+		repository:           repository,
+		passwordAuth:         passwordAuth,
+		mailSender:           mailSender,
+		auditService:         auditService,
+		deletionGracePeriod:  deletionGracePeriod,
+		emailVerificationUrl: emailVerificationUrl,
+		runAsync:             runInBackground,
 	}
 }
@@ -1,23 +1,28 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"github.com/SawitProRecruitment/UserService/consts"
 	"github.com/SawitProRecruitment/UserService/forms"
 	"github.com/SawitProRecruitment/UserService/modules"
 	"github.com/SawitProRecruitment/UserService/pojos"
 	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type UserServiceTestSuite struct {
 	suite.Suite
 
-	repository   *repository.MockUserRepositoryInterface
+	repository   *repository.MockTransactionRepositoryInterface
 	passwordAuth *modules.MockPasswordAuthInterface
+	mailSender   *modules.MockMailSenderInterface
+	auditService *MockAuditServiceInterface
 
 	MockController *gomock.Controller
 }
@@ -34,14 +39,22 @@ func (ts *UserServiceTestSuite) SetupSuite() {
 
 	defer mockCtrl.Finish()
 
-	ts.repository = repository.NewMockUserRepositoryInterface(mockCtrl)
+	ts.repository = repository.NewMockTransactionRepositoryInterface(mockCtrl)
+	ts.repository.EXPECT().WithinTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(repository.RunTransactionDirectly(ts.repository)).AnyTimes()
 	ts.passwordAuth = modules.NewMockPasswordAuthInterface(mockCtrl)
+	ts.mailSender = modules.NewMockMailSenderInterface(mockCtrl)
+	ts.auditService = NewMockAuditServiceInterface(mockCtrl)
 }
 
 func (ts *UserServiceTestSuite) TestNewUserService() {
 	type args struct {
-		repository   repository.UserRepositoryInterface
-		passwordAuth modules.PasswordAuthInterface
+		repository           repository.UserRepositoryInterface
+		passwordAuth         modules.PasswordAuthInterface
+		auditService         AuditServiceInterface
+		mailSender           modules.MailSenderInterface
+		deletionGracePeriod  time.Duration
+		emailVerificationUrl string
 	}
 	tests := []struct {
 		name string
@@ -52,21 +65,36 @@ func (ts *UserServiceTestSuite) TestNewUserService() {
 		{
 			name: "When instantiate correctly it will return implementation of UserService",
 			args: args{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
+				repository:           ts.repository,
+				passwordAuth:         ts.passwordAuth,
+				auditService:         ts.auditService,
+				mailSender:           ts.mailSender,
+				deletionGracePeriod:  48 * time.Hour,
+				emailVerificationUrl: consts.DefaultEmailVerificationUrl,
 			},
 			mock: func() {
 
 			},
 			want: UserService{
-				repository:   ts.repository,
-				passwordAuth: ts.passwordAuth,
+				repository:           ts.repository,
+				passwordAuth:         ts.passwordAuth,
+				auditService:         ts.auditService,
+				mailSender:           ts.mailSender,
+				deletionGracePeriod:  48 * time.Hour,
+				emailVerificationUrl: consts.DefaultEmailVerificationUrl,
 			},
 		},
 	}
 	for _, tt := range tests {
 		ts.T().Run(tt.name, func(t *testing.T) {
-			if got := NewUserService(tt.args.repository, tt.args.passwordAuth); !reflect.DeepEqual(got, tt.want) {
+			got := NewUserService(tt.args.repository, tt.args.passwordAuth, tt.args.mailSender, tt.args.auditService,
+				tt.args.deletionGracePeriod, tt.args.emailVerificationUrl).(UserService)
+			if got.runAsync == nil {
+				t.Errorf("NewUserService() runAsync is nil")
+			}
+			// Functions are only deeply equal when both are nil.
+			got.runAsync = nil
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewUserService() = %v, want %v", got, tt.want)
 			}
 		})
@@ -77,6 +105,7 @@ func (ts *UserServiceTestSuite) TestUserService_GetById() {
 	type fields struct {
 		repository   repository.UserRepositoryInterface
 		passwordAuth modules.PasswordAuthInterface
+		auditService AuditServiceInterface
 	}
 	type args struct {
 		userId int64
@@ -94,6 +123,7 @@ func (ts *UserServiceTestSuite) TestUserService_GetById() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -110,6 +140,7 @@ func (ts *UserServiceTestSuite) TestUserService_GetById() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -126,6 +157,7 @@ func (ts *UserServiceTestSuite) TestUserService_GetById() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -153,8 +185,9 @@ func (ts *UserServiceTestSuite) TestUserService_GetById() {
 			u := UserService{
 				repository:   tt.fields.repository,
 				passwordAuth: tt.fields.passwordAuth,
+				auditService: tt.fields.auditService,
 			}
-			got, err := u.GetById(tt.args.userId)
+			got, err := u.GetById(context.Background(), tt.args.userId)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetById() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -170,6 +203,7 @@ func (ts *UserServiceTestSuite) TestUserService_GetByPhoneNumber() {
 	type fields struct {
 		repository   repository.UserRepositoryInterface
 		passwordAuth modules.PasswordAuthInterface
+		auditService AuditServiceInterface
 	}
 	type args struct {
 		phoneNumber string
@@ -187,6 +221,7 @@ func (ts *UserServiceTestSuite) TestUserService_GetByPhoneNumber() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				phoneNumber: "+6285773801038",
@@ -203,6 +238,7 @@ func (ts *UserServiceTestSuite) TestUserService_GetByPhoneNumber() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				phoneNumber: "+6285773801038",
@@ -219,6 +255,7 @@ func (ts *UserServiceTestSuite) TestUserService_GetByPhoneNumber() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				phoneNumber: "+6285773801038",
@@ -246,8 +283,9 @@ func (ts *UserServiceTestSuite) TestUserService_GetByPhoneNumber() {
 			u := UserService{
 				repository:   tt.fields.repository,
 				passwordAuth: tt.fields.passwordAuth,
+				auditService: tt.fields.auditService,
 			}
-			got, err := u.GetByPhoneNumber(tt.args.phoneNumber)
+			got, err := u.GetByPhoneNumber(context.Background(), tt.args.phoneNumber)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetByPhoneNumber() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -263,6 +301,8 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 	type fields struct {
 		repository   repository.UserRepositoryInterface
 		passwordAuth modules.PasswordAuthInterface
+		auditService AuditServiceInterface
+		mailSender   modules.MailSenderInterface
 	}
 	type args struct {
 		form forms.UserRegisterForm
@@ -280,6 +320,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{},
@@ -301,6 +342,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -325,6 +367,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -349,6 +392,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -374,6 +418,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -399,6 +444,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -424,6 +470,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -449,6 +496,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -474,6 +522,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -499,6 +548,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -524,6 +574,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -549,6 +600,7 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -570,10 +622,56 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 		},
 
 		{
-			name: "When user register but the repo return error when get user by phone number, it will return error",
+			name: "When user register but the phone number is already used, it will return phone number conflict",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+			},
+			args: args{
+				form: forms.UserRegisterForm{
+					FullName:    "Rizqy Faishal Tanjung",
+					Password:    "Asdasd12#",
+					PhoneNumber: "+62242424424",
+				},
+			},
+			want: &RegisterResult{
+				IsPhoneNumberConflict: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.passwordAuth.EXPECT().GenerateHashedPassword(gomock.Any()).Return("asdasdsdsada", nil)
+				ts.repository.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil, &repository.UniqueViolationError{Field: repository.UniqueFieldPhoneNumber})
+			},
+		},
+
+		{
+			name: "When user register hashing password got error, it will return error",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+			},
+			args: args{
+				form: forms.UserRegisterForm{
+					FullName:    "Rizqy Faishal Tanjung",
+					Password:    "Asdasd12#",
+					PhoneNumber: "+62242424424",
+				},
+			},
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.passwordAuth.EXPECT().GenerateHashedPassword(gomock.Any()).Return("", errors.New("unexpected error"))
+			},
+		},
+
+		{
+			name: "When user register repo got error when inserting record, it will return error",
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -585,15 +683,17 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			want:    nil,
 			wantErr: true,
 			mock: func() {
-				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(nil, errors.New("Unepxected error"))
+				ts.passwordAuth.EXPECT().GenerateHashedPassword(gomock.Any()).Return("asdasdsdsada", nil)
+				ts.repository.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
 			},
 		},
 
 		{
-			name: "When user register but the repo return user found when get user by phone number, it will return validatioin error",
+			name: "When user register repo got no error, it will return register result",
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
@@ -603,77 +703,119 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 				},
 			},
 			want: &RegisterResult{
-				ValidationErrors: map[string]string{
-					"phone_number": "Phone number +62242424424 is unavailable for registering new user",
+				User: pojos.User{
+					Id:          123,
+					FullName:    "Rizqy Faishal Tanjung",
+					PhoneNumber: "+62242424424",
 				},
-				HasValidationErrors: true,
+				HasValidationErrors: false,
 			},
 			wantErr: false,
 			mock: func() {
-				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByPhoneNumberOutput{
+				ts.passwordAuth.EXPECT().GenerateHashedPassword(gomock.Any()).Return("asdasdsdsada", nil)
+				ts.repository.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(&repository.InsertUserOutput{
+					Id: 123,
+				}, nil)
+
+				ts.repository.EXPECT().GetById(gomock.Any(), gomock.Any()).Return(&repository.GetUserByIdOutput{
 					Id:                123,
 					PhoneNumber:       "+62242424424",
 					FullName:          "Rizqy Faishal Tanjung",
-					LoginSuccessCount: 1,
-					Password:          "Asdasd123%",
+					LoginSuccessCount: 0,
 				}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
 			},
 		},
 
 		{
-			name: "When user register hashing password got error, it will return error",
+			name: "When user register with an email that is already used, it will return validation error",
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
 					FullName:    "Rizqy Faishal Tanjung",
-					Password:    "Asdasd12#",
+					Password:    "Asdasd123#",
 					PhoneNumber: "+62242424424",
+					Email:       "rizqy@example.com",
 				},
 			},
-			want:    nil,
-			wantErr: true,
+			want: &RegisterResult{
+				ValidationErrors: map[string]string{
+					"email": "Email rizqy@example.com is unavailable for registering new user",
+				},
+				HasValidationErrors: true,
+			},
+			wantErr: false,
 			mock: func() {
-				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(nil, nil)
-				ts.passwordAuth.EXPECT().GenerateHashedPassword(gomock.Any()).Return("", errors.New("unexpected error"))
+				ts.passwordAuth.EXPECT().GenerateHashedPassword(gomock.Any()).Return("asdasdsdsada", nil)
+				ts.repository.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil, &repository.UniqueViolationError{Field: repository.UniqueFieldEmail})
 			},
 		},
 
 		{
-			name: "When user register repo got error when inserting record, it will return error",
+			name: "When user register with an email but the verification mail is failed to send, it will still return the user",
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
 					FullName:    "Rizqy Faishal Tanjung",
-					Password:    "Asdasd12#",
+					Password:    "Asdasd123#",
 					PhoneNumber: "+62242424424",
+					Email:       "rizqy@example.com",
 				},
 			},
-			want:    nil,
-			wantErr: true,
+			want: &RegisterResult{
+				User: pojos.User{
+					Id:          123,
+					FullName:    "Rizqy Faishal Tanjung",
+					PhoneNumber: "+62242424424",
+					Email:       "rizqy@example.com",
+				},
+				HasValidationErrors: false,
+			},
+			wantErr: false,
 			mock: func() {
-				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(nil, nil)
 				ts.passwordAuth.EXPECT().GenerateHashedPassword(gomock.Any()).Return("asdasdsdsada", nil)
-				ts.repository.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+				ts.repository.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(&repository.InsertUserOutput{
+					Id: 123,
+				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), gomock.Any()).Return(&repository.GetUserByIdOutput{
+					Id:          123,
+					PhoneNumber: "+62242424424",
+					FullName:    "Rizqy Faishal Tanjung",
+					Email:       "rizqy@example.com",
+				}, nil)
+				ts.repository.EXPECT().InsertEmailVerification(gomock.Any(), gomock.Any()).Return(&repository.InsertEmailVerificationOutput{
+					Id: 1,
+				}, nil)
+				ts.mailSender.EXPECT().Send(gomock.Any()).Return(errors.New("smtp is down"))
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 
 		{
-			name: "When user register repo got no error, it will return register result",
+			name: "When user register with an email, it will return the user and send the verification mail",
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
 			},
 			args: args{
 				form: forms.UserRegisterForm{
 					FullName:    "Rizqy Faishal Tanjung",
-					Password:    "Asdasd12#",
+					Password:    "Asdasd123#",
 					PhoneNumber: "+62242424424",
+					Email:       "rizqy@example.com",
 				},
 			},
 			want: &RegisterResult{
@@ -681,24 +823,42 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 					Id:          123,
 					FullName:    "Rizqy Faishal Tanjung",
 					PhoneNumber: "+62242424424",
+					Email:       "rizqy@example.com",
 				},
 				HasValidationErrors: false,
 			},
 			wantErr: false,
 			mock: func() {
-				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(nil, nil)
 				ts.passwordAuth.EXPECT().GenerateHashedPassword(gomock.Any()).Return("asdasdsdsada", nil)
-				ts.repository.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(&repository.InsertUserOutput{
+				ts.repository.EXPECT().Insert(gomock.Any(), repository.InsertUserInput{
+					PhoneNumber: "+62242424424",
+					FullName:    "Rizqy Faishal Tanjung",
+					Email:       "rizqy@example.com",
+					Password:    "asdasdsdsada",
+				}).Return(&repository.InsertUserOutput{
 					Id: 123,
 				}, nil)
-
 				ts.repository.EXPECT().GetById(gomock.Any(), gomock.Any()).Return(&repository.GetUserByIdOutput{
-					Id:                123,
-					PhoneNumber:       "+62242424424",
-					FullName:          "Rizqy Faishal Tanjung",
-					LoginSuccessCount: 0,
+					Id:          123,
+					PhoneNumber: "+62242424424",
+					FullName:    "Rizqy Faishal Tanjung",
+					Email:       "rizqy@example.com",
 				}, nil)
-
+				ts.repository.EXPECT().InsertEmailVerification(gomock.Any(), gomock.Any()).Return(&repository.InsertEmailVerificationOutput{
+					Id: 1,
+				}, nil)
+				ts.mailSender.EXPECT().Send(gomock.Any()).Return(nil)
+				userId := int64(123)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					ActorUserId:   &userId,
+					SubjectUserId: &userId,
+					Action:        consts.AuditActionRegister,
+					Changes: map[string]pojos.AuditChange{
+						"phone_number": {Before: "", After: "+62242424424"},
+						"full_name":    {Before: "", After: "Rizqy Faishal Tanjung"},
+						"email":        {Before: "", After: "rizqy@example.com"},
+					},
+				}).Return(nil)
 			},
 		},
 	}
@@ -708,8 +868,13 @@ func (ts *UserServiceTestSuite) TestUserService_Register() {
 			u := UserService{
 				repository:   tt.fields.repository,
 				passwordAuth: tt.fields.passwordAuth,
+				auditService: tt.fields.auditService,
+				mailSender:   tt.fields.mailSender,
+				runAsync: func(task func()) {
+					task()
+				},
 			}
-			got, err := u.Register(tt.args.form)
+			got, err := u.Register(context.Background(), tt.args.form, RequestMetadata{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -725,6 +890,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 	type fields struct {
 		repository   repository.UserRepositoryInterface
 		passwordAuth modules.PasswordAuthInterface
+		auditService AuditServiceInterface
 	}
 	type args struct {
 		userId int64
@@ -743,6 +909,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -766,6 +933,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -791,6 +959,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -816,6 +985,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -841,6 +1011,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -866,6 +1037,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -891,6 +1063,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -910,6 +1083,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -929,6 +1103,7 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -949,11 +1124,40 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			},
 		},
 
+		{
+			name: "When the form is valid, but the phone number is used by another user, then return phone number conflict",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+			},
+			args: args{
+				userId: 123,
+				form: forms.UserUpdateForm{
+					PhoneNumber: "+62857382923",
+				},
+			},
+			want: &UpdateResult{
+				IsPhoneNumberConflict: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), gomock.Any()).Return(&repository.GetUserByIdOutput{
+					Id:                123,
+					PhoneNumber:       "62857382923",
+					FullName:          "Rizqy",
+					LoginSuccessCount: 0,
+				}, nil)
+				ts.repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, &repository.UniqueViolationError{Field: repository.UniqueFieldPhoneNumber})
+			},
+		},
+
 		{
 			name: "When the form is valid, successfully update, then return updated user",
 			fields: fields{
 				repository:   ts.repository,
 				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
 			},
 			args: args{
 				userId: 123,
@@ -979,9 +1183,14 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 					FullName:          "Rizqy",
 					LoginSuccessCount: 0,
 				}, nil)
-				ts.repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&repository.UpdateUserOutput{
+				ts.repository.EXPECT().Update(gomock.Any(), repository.UpdateUserInput{
+					Id:          123,
+					Fields:      []string{repository.UserFieldPhoneNumber},
+					PhoneNumber: "+62857382923",
+				}).Return(&repository.UpdateUserOutput{
 					IsSuccessUpdate: true,
 				}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 	}
@@ -991,8 +1200,9 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 			u := UserService{
 				repository:   tt.fields.repository,
 				passwordAuth: tt.fields.passwordAuth,
+				auditService: tt.fields.auditService,
 			}
-			got, err := u.Update(tt.args.userId, tt.args.form)
+			got, err := u.Update(context.Background(), tt.args.userId, tt.args.form, RequestMetadata{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1004,10 +1214,1137 @@ func (ts *UserServiceTestSuite) TestUserService_Update() {
 	}
 }
 
+func (ts *UserServiceTestSuite) TestUserService_ChangeEmail() {
+	type fields struct {
+		repository   repository.UserRepositoryInterface
+		passwordAuth modules.PasswordAuthInterface
+		auditService AuditServiceInterface
+		mailSender   modules.MailSenderInterface
+	}
+	type args struct {
+		userId int64
+		form   forms.UserChangeEmailForm
+	}
+	verifiedAt := time.Now()
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *ChangeEmailResult
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the email is empty, it will return validation error",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
+			},
+			args: args{
+				userId: 123,
+				form:   forms.UserChangeEmailForm{},
+			},
+			want: &ChangeEmailResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"email": "Email is required",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+			},
+		},
+
+		{
+			name: "When the email is invalid, it will return validation error",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
+			},
+			args: args{
+				userId: 123,
+				form: forms.UserChangeEmailForm{
+					Email: "not an email",
+				},
+			},
+			want: &ChangeEmailResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"email": "Email must be a valid email address",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+			},
+		},
+
+		{
+			name: "When the email is used by another user, it will return validation error",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
+			},
+			args: args{
+				userId: 123,
+				form: forms.UserChangeEmailForm{
+					Email: "rizqy@example.com",
+				},
+			},
+			want: &ChangeEmailResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"email": "Email rizqy@example.com is unavailable",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetByEmailIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByEmailOutput{
+					Id:    321,
+					Email: "rizqy@example.com",
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the email is already verified for the same user, it will return validation error",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
+			},
+			args: args{
+				userId: 123,
+				form: forms.UserChangeEmailForm{
+					Email: "rizqy@example.com",
+				},
+			},
+			want: &ChangeEmailResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"email": "Email rizqy@example.com is already verified",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetByEmailIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByEmailOutput{
+					Id:              123,
+					Email:           "rizqy@example.com",
+					EmailVerifiedAt: &verifiedAt,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the repository return error on update, it will return error",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
+			},
+			args: args{
+				userId: 123,
+				form: forms.UserChangeEmailForm{
+					Email: "rizqy@example.com",
+				},
+			},
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().GetByEmailIncludePassword(gomock.Any(), gomock.Any()).Return(nil, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), gomock.Any()).Return(&repository.GetUserByIdOutput{
+					Id:          123,
+					PhoneNumber: "+62242424424",
+					FullName:    "Rizqy Faishal Tanjung",
+				}, nil)
+				ts.repository.EXPECT().UpdateEmail(gomock.Any(), gomock.Any()).Return(nil, errors.New("Unexpected error"))
+			},
+		},
+
+		{
+			name: "When the email is available, it will update the email, send verification mail and return the user",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+				mailSender:   ts.mailSender,
+			},
+			args: args{
+				userId: 123,
+				form: forms.UserChangeEmailForm{
+					Email: "rizqy@example.com",
+				},
+			},
+			want: &ChangeEmailResult{
+				User: pojos.User{
+					Id:          123,
+					PhoneNumber: "+62242424424",
+					FullName:    "Rizqy Faishal Tanjung",
+					Email:       "rizqy@example.com",
+				},
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetByEmailIncludePassword(gomock.Any(), gomock.Any()).Return(nil, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), gomock.Any()).Return(&repository.GetUserByIdOutput{
+					Id:          123,
+					PhoneNumber: "+62242424424",
+					FullName:    "Rizqy Faishal Tanjung",
+				}, nil)
+				ts.repository.EXPECT().UpdateEmail(gomock.Any(), repository.UpdateUserEmailInput{
+					Id:    123,
+					Email: "rizqy@example.com",
+				}).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: true,
+				}, nil)
+				ts.repository.EXPECT().InsertEmailVerification(gomock.Any(), gomock.Any()).Return(&repository.InsertEmailVerificationOutput{
+					Id: 1,
+				}, nil)
+				ts.mailSender.EXPECT().Send(gomock.Any()).Return(nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), gomock.Any()).Return(&repository.GetUserByIdOutput{
+					Id:          123,
+					PhoneNumber: "+62242424424",
+					FullName:    "Rizqy Faishal Tanjung",
+					Email:       "rizqy@example.com",
+				}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLogRepository repository.AuditLogRepositoryInterface, entry AuditEntry) error {
+					if entry.Action != consts.AuditActionEmailChange {
+						ts.T().Errorf("Record() action = %v, want %v", entry.Action, consts.AuditActionEmailChange)
+					}
+					if !reflect.DeepEqual(entry.Changes, map[string]pojos.AuditChange{
+						"email": {Before: "", After: "rizqy@example.com"},
+					}) {
+						ts.T().Errorf("Record() changes = %v", entry.Changes)
+					}
+					return nil
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			u := UserService{
+				repository:   tt.fields.repository,
+				passwordAuth: tt.fields.passwordAuth,
+				auditService: tt.fields.auditService,
+				mailSender:   tt.fields.mailSender,
+				runAsync: func(task func()) {
+					task()
+				},
+			}
+			got, err := u.ChangeEmail(context.Background(), tt.args.userId, tt.args.form, RequestMetadata{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChangeEmail() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangeEmail() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *UserServiceTestSuite) TestUserService_VerifyEmail() {
+	type fields struct {
+		repository   repository.UserRepositoryInterface
+		passwordAuth modules.PasswordAuthInterface
+		auditService AuditServiceInterface
+		mailSender   modules.MailSenderInterface
+	}
+	type args struct {
+		form forms.VerifyEmailForm
+	}
+	invalidTokenResult := &VerifyEmailResult{
+		IsVerified:          false,
+		HasValidationErrors: true,
+		ValidationErrors: map[string]string{
+			"token": "Verification token is invalid or expired",
+		},
+	}
+	verifiedAt := time.Now()
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *VerifyEmailResult
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the token is empty, it will return validation error",
+			fields: fields{
+				repository: ts.repository,
+			},
+			args: args{
+				form: forms.VerifyEmailForm{},
+			},
+			want: &VerifyEmailResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"token": "Verification token is required",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+			},
+		},
+
+		{
+			name: "When the token is not found, it will return invalid token",
+			fields: fields{
+				repository: ts.repository,
+			},
+			args: args{
+				form: forms.VerifyEmailForm{
+					Token: "token",
+				},
+			},
+			want:    invalidTokenResult,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), repository.GetEmailVerificationByTokenHashInput{
+					TokenHash: utils.HashToken("token"),
+				}).Return(nil, nil)
+			},
+		},
+
+		{
+			name: "When the token is expired, it will return invalid token",
+			fields: fields{
+				repository: ts.repository,
+			},
+			args: args{
+				form: forms.VerifyEmailForm{
+					Token: "token",
+				},
+			},
+			want:    invalidTokenResult,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), gomock.Any()).Return(&repository.GetEmailVerificationOutput{
+					Id:        1,
+					UserId:    123,
+					ExpiredAt: time.Now().Add(-time.Hour),
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the token is already used, it will return invalid token",
+			fields: fields{
+				repository: ts.repository,
+			},
+			args: args{
+				form: forms.VerifyEmailForm{
+					Token: "token",
+				},
+			},
+			want:    invalidTokenResult,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), gomock.Any()).Return(&repository.GetEmailVerificationOutput{
+					Id:         1,
+					UserId:     123,
+					ExpiredAt:  time.Now().Add(time.Hour),
+					VerifiedAt: &verifiedAt,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the user email has changed since the verification was sent, it will return invalid token",
+			fields: fields{
+				repository: ts.repository,
+			},
+			args: args{
+				form: forms.VerifyEmailForm{
+					Token: "token",
+				},
+			},
+			want:    invalidTokenResult,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), gomock.Any()).Return(&repository.GetEmailVerificationOutput{
+					Id:        1,
+					UserId:    123,
+					ExpiredAt: time.Now().Add(time.Hour),
+				}, nil)
+				ts.repository.EXPECT().VerifyEmail(gomock.Any(), gomock.Any()).Return(&repository.VerifyEmailOutput{
+					IsVerified: false,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the repository return error, it will return error",
+			fields: fields{
+				repository: ts.repository,
+			},
+			args: args{
+				form: forms.VerifyEmailForm{
+					Token: "token",
+				},
+			},
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), gomock.Any()).Return(nil, errors.New("Unexpected error"))
+			},
+		},
+
+		{
+			name: "When the token is valid, it will verify the email",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				form: forms.VerifyEmailForm{
+					Token: "token",
+				},
+			},
+			want: &VerifyEmailResult{
+				IsVerified:          true,
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), gomock.Any()).Return(&repository.GetEmailVerificationOutput{
+					Id:        1,
+					UserId:    123,
+					ExpiredAt: time.Now().Add(time.Hour),
+				}, nil)
+				ts.repository.EXPECT().VerifyEmail(gomock.Any(), repository.VerifyEmailInput{
+					EmailVerificationId: 1,
+				}).Return(&repository.VerifyEmailOutput{
+					IsVerified: true,
+				}, nil)
+				userId := int64(123)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					ActorUserId:   &userId,
+					SubjectUserId: &userId,
+					Action:        consts.AuditActionEmailVerify,
+					Changes: map[string]pojos.AuditChange{
+						"email_verified": {Before: false, After: true},
+					},
+				}).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			u := UserService{
+				repository:   tt.fields.repository,
+				passwordAuth: tt.fields.passwordAuth,
+				auditService: tt.fields.auditService,
+				mailSender:   tt.fields.mailSender,
+			}
+			got, err := u.VerifyEmail(context.Background(), tt.args.form, RequestMetadata{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyEmail() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VerifyEmail() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *UserServiceTestSuite) TestUserService_ChangeRole() {
+	type fields struct {
+		repository   repository.UserRepositoryInterface
+		auditService AuditServiceInterface
+	}
+	type args struct {
+		actorUserId int64
+		userId      int64
+		form        forms.UserChangeRoleForm
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *ChangeRoleResult
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the role is not user or admin, it will return validation error",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeRoleForm{
+					Role: "owner",
+				},
+			},
+			want: &ChangeRoleResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"role": "Role must be one of: user admin",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+			},
+		},
+
+		{
+			name: "When the user is not found, it will return user not found",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeRoleForm{
+					Role: consts.RoleAdmin,
+				},
+			},
+			want: &ChangeRoleResult{
+				IsUserNotFound: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(nil, nil)
+			},
+		},
+
+		{
+			name: "When the repository return error on update, it will return error",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeRoleForm{
+					Role: consts.RoleAdmin,
+				},
+			},
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:   456,
+					Role: consts.RoleUser,
+				}, nil)
+				ts.repository.EXPECT().UpdateRole(gomock.Any(), gomock.Any()).Return(nil, errors.New("Unexpected error"))
+			},
+		},
+
+		{
+			name: "When the role is valid, it will update the role, record the change and return the user",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeRoleForm{
+					Role: consts.RoleAdmin,
+				},
+			},
+			want: &ChangeRoleResult{
+				User: pojos.User{
+					Id:   456,
+					Role: consts.RoleAdmin,
+				},
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:   456,
+					Role: consts.RoleUser,
+				}, nil)
+				ts.repository.EXPECT().UpdateRole(gomock.Any(), repository.UpdateUserRoleInput{
+					Id:   456,
+					Role: consts.RoleAdmin,
+				}).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: true,
+				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:   456,
+					Role: consts.RoleAdmin,
+				}, nil)
+				actorUserId := int64(1)
+				subjectUserId := int64(456)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					ActorUserId:   &actorUserId,
+					SubjectUserId: &subjectUserId,
+					Action:        consts.AuditActionRoleChange,
+					Changes: map[string]pojos.AuditChange{
+						"role": {Before: consts.RoleUser, After: consts.RoleAdmin},
+					},
+				}).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			u := UserService{
+				repository:   tt.fields.repository,
+				auditService: tt.fields.auditService,
+			}
+			got, err := u.ChangeRole(context.Background(), tt.args.actorUserId, tt.args.userId, tt.args.form, RequestMetadata{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChangeRole() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangeRole() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *UserServiceTestSuite) TestUserService_ChangeStatus() {
+	type fields struct {
+		repository   repository.UserRepositoryInterface
+		auditService AuditServiceInterface
+	}
+	type args struct {
+		actorUserId int64
+		userId      int64
+		form        forms.UserChangeStatusForm
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *ChangeStatusResult
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the reason is empty, it will return validation error",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeStatusForm{
+					Status: consts.AccountStatusSuspended,
+				},
+			},
+			want: &ChangeStatusResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"reason": "Reason is required",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+			},
+		},
+
+		{
+			name: "When the user is not found, it will return user not found",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeStatusForm{
+					Status: consts.AccountStatusSuspended,
+					Reason: "Left the estate",
+				},
+			},
+			want: &ChangeStatusResult{
+				IsUserNotFound: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(nil, nil)
+			},
+		},
+
+		{
+			name: "When the user is deleted, it will not allow the transition to active",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeStatusForm{
+					Status: consts.AccountStatusActive,
+					Reason: "Came back",
+				},
+			},
+			want: &ChangeStatusResult{
+				User: pojos.User{
+					Id:     456,
+					Status: consts.AccountStatusDeleted,
+				},
+				IsTransitionNotAllowed: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:     456,
+					Status: consts.AccountStatusDeleted,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the user is pending deletion, it will not allow the transition to active",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeStatusForm{
+					Status: consts.AccountStatusActive,
+					Reason: "Asked support to keep the account",
+				},
+			},
+			want: &ChangeStatusResult{
+				User: pojos.User{
+					Id:     456,
+					Status: consts.AccountStatusPendingDeletion,
+				},
+				IsTransitionNotAllowed: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:     456,
+					Status: consts.AccountStatusPendingDeletion,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the status was changed concurrently, it will not allow the transition",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeStatusForm{
+					Status: consts.AccountStatusSuspended,
+					Reason: "Left the estate",
+				},
+			},
+			want: &ChangeStatusResult{
+				User: pojos.User{
+					Id:     456,
+					Status: consts.AccountStatusActive,
+				},
+				IsTransitionNotAllowed: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:     456,
+					Status: consts.AccountStatusActive,
+				}, nil)
+				ts.repository.EXPECT().UpdateStatus(gomock.Any(), gomock.Any()).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: false,
+				}, nil)
+			},
+		},
+
+		{
+			name: "When the transition is allowed, it will update the status, record the change and return the user",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeStatusForm{
+					Status: consts.AccountStatusSuspended,
+					Reason: "Left the estate",
+				},
+			},
+			want: &ChangeStatusResult{
+				User: pojos.User{
+					Id:           456,
+					Status:       consts.AccountStatusSuspended,
+					StatusReason: "Left the estate",
+				},
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:     456,
+					Status: consts.AccountStatusActive,
+				}, nil)
+				ts.repository.EXPECT().UpdateStatus(gomock.Any(), repository.UpdateUserStatusInput{
+					Id:         456,
+					FromStatus: consts.AccountStatusActive,
+					Status:     consts.AccountStatusSuspended,
+					Reason:     "Left the estate",
+				}).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: true,
+				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:           456,
+					Status:       consts.AccountStatusSuspended,
+					StatusReason: "Left the estate",
+				}, nil)
+				actorUserId := int64(1)
+				subjectUserId := int64(456)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					ActorUserId:   &actorUserId,
+					SubjectUserId: &subjectUserId,
+					Action:        consts.AuditActionStatusChange,
+					Changes: map[string]pojos.AuditChange{
+						"status":        {Before: consts.AccountStatusActive, After: consts.AccountStatusSuspended},
+						"status_reason": {Before: "", After: "Left the estate"},
+					},
+				}).Return(nil)
+			},
+		},
+
+		{
+			name: "When the user is deleted, it will anonymize the user and record only the status change",
+			fields: fields{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			},
+			args: args{
+				actorUserId: 1,
+				userId:      456,
+				form: forms.UserChangeStatusForm{
+					Status: consts.AccountStatusDeleted,
+					Reason: "Fraud",
+				},
+			},
+			want: &ChangeStatusResult{
+				User: pojos.User{
+					Id:           456,
+					PhoneNumber:  "#456",
+					FullName:     consts.AnonymizedUserFullName,
+					Status:       consts.AccountStatusDeleted,
+					StatusReason: "Fraud",
+				},
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:          456,
+					PhoneNumber: "+628123456789",
+					FullName:    "Budi Santoso",
+					Status:      consts.AccountStatusSuspended,
+				}, nil)
+				ts.repository.EXPECT().AnonymizeUser(gomock.Any(), repository.AnonymizeUserInput{
+					Id:         456,
+					FromStatus: consts.AccountStatusSuspended,
+					FullName:   consts.AnonymizedUserFullName,
+					Reason:     "Fraud",
+				}).Return(&repository.AnonymizeUserOutput{IsAnonymized: true}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 456}).Return(&repository.GetUserByIdOutput{
+					Id:           456,
+					PhoneNumber:  "#456",
+					FullName:     consts.AnonymizedUserFullName,
+					Status:       consts.AccountStatusDeleted,
+					StatusReason: "Fraud",
+				}, nil)
+				actorUserId := int64(1)
+				subjectUserId := int64(456)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					ActorUserId:   &actorUserId,
+					SubjectUserId: &subjectUserId,
+					Action:        consts.AuditActionStatusChange,
+					Changes: map[string]pojos.AuditChange{
+						"status":        {Before: consts.AccountStatusSuspended, After: consts.AccountStatusDeleted},
+						"status_reason": {Before: "", After: "Fraud"},
+					},
+				}).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			u := UserService{
+				repository:   tt.fields.repository,
+				auditService: tt.fields.auditService,
+			}
+			got, err := u.ChangeStatus(context.Background(), tt.args.actorUserId, tt.args.userId, tt.args.form, RequestMetadata{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChangeStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangeStatus() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *UserServiceTestSuite) TestUserService_RequestDeletion() {
+	deletionScheduledAt := time.Date(2024, 5, 18, 16, 50, 16, 0, time.UTC)
+
+	type fields struct {
+		repository   repository.UserRepositoryInterface
+		passwordAuth modules.PasswordAuthInterface
+		auditService AuditServiceInterface
+	}
+	type args struct {
+		userId int64
+		form   forms.UserDeleteAccountForm
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *DeleteAccountResult
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "When the password is empty, it will return validation error",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+			},
+			args: args{
+				userId: 123,
+				form:   forms.UserDeleteAccountForm{},
+			},
+			want: &DeleteAccountResult{
+				HasValidationErrors: true,
+				ValidationErrors: map[string]string{
+					"password": "Password is required",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+			},
+		},
+
+		{
+			name: "When the password is incorrect, it will return invalid password",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+			},
+			args: args{
+				userId: 123,
+				form: forms.UserDeleteAccountForm{
+					Password: "wrong password",
+				},
+			},
+			want: &DeleteAccountResult{
+				IsInvalidPassword: true,
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 123}).Return(&repository.GetUserByIdOutput{
+					Id:          123,
+					PhoneNumber: "+62242424424",
+					Status:      consts.AccountStatusActive,
+				}, nil)
+				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), repository.GetUserByPhoneNumberInput{
+					PhoneNumber: "+62242424424",
+				}).Return(&repository.GetUserByPhoneNumberOutput{
+					Id:       123,
+					Password: "hashed password",
+				}, nil)
+				ts.passwordAuth.EXPECT().CompareHashedPassword("hashed password", "wrong password").Return(false, errors.New("password not match"))
+			},
+		},
+
+		{
+			name: "When the password is correct, it will schedule the deletion after the grace period and record it",
+			fields: fields{
+				repository:   ts.repository,
+				passwordAuth: ts.passwordAuth,
+				auditService: ts.auditService,
+			},
+			args: args{
+				userId: 123,
+				form: forms.UserDeleteAccountForm{
+					Password: "Asdasd123#",
+				},
+			},
+			want: &DeleteAccountResult{
+				User: pojos.User{
+					Id:                  123,
+					PhoneNumber:         "+62242424424",
+					Status:              consts.AccountStatusPendingDeletion,
+					StatusReason:        consts.AccountDeletionRequestReason,
+					DeletionScheduledAt: &deletionScheduledAt,
+				},
+				HasValidationErrors: false,
+				ValidationErrors:    map[string]string{},
+			},
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 123}).Return(&repository.GetUserByIdOutput{
+					Id:          123,
+					PhoneNumber: "+62242424424",
+					Status:      consts.AccountStatusActive,
+				}, nil)
+				ts.repository.EXPECT().GetByPhoneNumberIncludePassword(gomock.Any(), gomock.Any()).Return(&repository.GetUserByPhoneNumberOutput{
+					Id:       123,
+					Password: "hashed password",
+				}, nil)
+				ts.passwordAuth.EXPECT().CompareHashedPassword("hashed password", "Asdasd123#").Return(true, nil)
+				ts.repository.EXPECT().ScheduleDeletion(gomock.Any(), repository.ScheduleUserDeletionInput{
+					Id:          123,
+					Reason:      consts.AccountDeletionRequestReason,
+					GracePeriod: 48 * time.Hour,
+				}).Return(&repository.UpdateUserOutput{
+					IsSuccessUpdate: true,
+				}, nil)
+				ts.repository.EXPECT().GetById(gomock.Any(), repository.GetUserByIdInput{Id: 123}).Return(&repository.GetUserByIdOutput{
+					Id:                  123,
+					PhoneNumber:         "+62242424424",
+					Status:              consts.AccountStatusPendingDeletion,
+					StatusReason:        consts.AccountDeletionRequestReason,
+					DeletionScheduledAt: &deletionScheduledAt,
+				}, nil)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLogRepository repository.AuditLogRepositoryInterface, entry AuditEntry) error {
+					if entry.Action != consts.AuditActionDeletionRequest {
+						ts.T().Errorf("Record() action = %v, want %v", entry.Action, consts.AuditActionDeletionRequest)
+					}
+					return nil
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			u := UserService{
+				repository:          tt.fields.repository,
+				passwordAuth:        tt.fields.passwordAuth,
+				auditService:        tt.fields.auditService,
+				deletionGracePeriod: 48 * time.Hour,
+			}
+			got, err := u.RequestDeletion(context.Background(), tt.args.userId, tt.args.form, RequestMetadata{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RequestDeletion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RequestDeletion() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (ts *UserServiceTestSuite) TestUserService_AnonymizeDueAccounts() {
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "When no account is due, it will anonymize nothing",
+			want:    0,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetUsersDueForDeletion(gomock.Any(), repository.GetUsersDueForDeletionInput{
+					Limit: consts.AccountDeletionBatchSize,
+				}).Return(&repository.GetUsersDueForDeletionOutput{UserIds: []int64{}}, nil)
+			},
+		},
+
+		{
+			name:    "When accounts are due, it will anonymize them, skip the cancelled ones and record each deletion",
+			want:    1,
+			wantErr: false,
+			mock: func() {
+				ts.repository.EXPECT().GetUsersDueForDeletion(gomock.Any(), gomock.Any()).Return(&repository.GetUsersDueForDeletionOutput{
+					UserIds: []int64{123, 456},
+				}, nil)
+				ts.repository.EXPECT().AnonymizeUser(gomock.Any(), repository.AnonymizeUserInput{
+					Id:         123,
+					FromStatus: consts.AccountStatusPendingDeletion,
+					FullName:   consts.AnonymizedUserFullName,
+					Reason:     consts.AccountDeletionDoneReason,
+				}).Return(&repository.AnonymizeUserOutput{IsAnonymized: true}, nil)
+				subjectUserId := int64(123)
+				ts.auditService.EXPECT().Record(gomock.Any(), gomock.Any(), AuditEntry{
+					SubjectUserId: &subjectUserId,
+					Action:        consts.AuditActionDelete,
+					Changes: map[string]pojos.AuditChange{
+						"status": {Before: consts.AccountStatusPendingDeletion, After: consts.AccountStatusDeleted},
+					},
+				}).Return(nil)
+				ts.repository.EXPECT().AnonymizeUser(gomock.Any(), gomock.Any()).Return(&repository.AnonymizeUserOutput{IsAnonymized: false}, nil)
+			},
+		},
+
+		{
+			name:    "When the repository return error, it will return error",
+			want:    0,
+			wantErr: true,
+			mock: func() {
+				ts.repository.EXPECT().GetUsersDueForDeletion(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		ts.T().Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			u := UserService{
+				repository:   ts.repository,
+				auditService: ts.auditService,
+			}
+			got, err := u.AnonymizeDueAccounts(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AnonymizeDueAccounts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("AnonymizeDueAccounts() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestUserService_buildRegisterUserResponse(t *testing.T) {
 	type fields struct {
 		repository   repository.UserRepositoryInterface
 		passwordAuth modules.PasswordAuthInterface
+		auditService AuditServiceInterface
 	}
 	type args struct {
 		output repository.GetUserByIdOutput
@@ -1025,6 +2362,7 @@ func TestUserService_buildRegisterUserResponse(t *testing.T) {
 			u := UserService{
 				repository:   tt.fields.repository,
 				passwordAuth: tt.fields.passwordAuth,
+				auditService: tt.fields.auditService,
 			}
 			if got := u.buildRegisterUserResponse(tt.args.output); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("buildRegisterUserResponse() = %v, want %v", got, tt.want)
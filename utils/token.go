@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// GenerateRandomToken returns a hex encoded token built from byteLength random bytes.
+func GenerateRandomToken(byteLength int) (string, error) {
+
+	randomBytes := make([]byte, byteLength)
+
+	_, err := rand.Read(randomBytes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(randomBytes), nil
+}
+
+// HashToken returns the hex encoded sha256 of a token, the form tokens are stored in.
+func HashToken(token string) string {
+
+	hashed := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hashed[:])
+}